@@ -5,9 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"tcr/internal/models"
 	"tcr/internal/network"
+	"tcr/internal/shared"
 	"time"
 )
 
@@ -15,31 +19,35 @@ import (
 var (
 	gameMode         string
 	opponentUsername string
+	// Teammate in a 2v2 match, empty in 1v1
+	teammateUsername string
+	teammateState    models.PlayerState
 	myPlayerState    models.PlayerState
 	opponentState    models.PlayerState
 	currentTurn      string
 	lastActionLog    string
+	// Match clock: the current phase and when it runs out (zero if the match has no clock)
+	matchPhase  string
+	phaseEndsAt time.Time
 	// Store detailed game state
-	myTroops         []models.TroopState
-	myKingTower      models.TowerState
-	myGuardTower1    models.TowerState
-	myGuardTower2    models.TowerState
-	enemyKingTower   models.TowerState
-	enemyGuardTower1 models.TowerState
-	enemyGuardTower2 models.TowerState
+	myTroops []models.TroopState
 	// Authentication status flags
 	loginSuccess        bool
 	registrationSuccess bool
 	authErrorMessage    string
+	// Lobby presence, kept up to date by PLAYER_LIST and PRESENCE_UPDATE messages
+	onlinePlayers       = make(map[string]models.PlayerPresence)
+	onlinePlayersMutex  sync.Mutex
+	playerListRequested bool
+	// ID of the game being spectated, set by SPECTATE_START and cleared by SPECTATE_END
+	spectatingGame string
 )
 
 func init() {
 	// Initialize with empty data to avoid nil pointer errors
 	myPlayerState = models.PlayerState{
 		Username:                "You",
-		KingTower:               models.TowerState{Type: "KING"},
-		GuardTower1:             models.TowerState{Type: "GUARD1"},
-		GuardTower2:             models.TowerState{Type: "GUARD2"},
+		Towers:                  make([]models.TowerState, 0),
 		Troops:                  make([]models.TroopState, 0),
 		Level:                   1,
 		CurrentEXP:              0,
@@ -49,9 +57,7 @@ func init() {
 	}
 	opponentState = models.PlayerState{
 		Username:                "Opponent",
-		KingTower:               models.TowerState{Type: "KING"},
-		GuardTower1:             models.TowerState{Type: "GUARD1"},
-		GuardTower2:             models.TowerState{Type: "GUARD2"},
+		Towers:                  make([]models.TowerState, 0),
 		Troops:                  make([]models.TroopState, 0),
 		Level:                   1,
 		CurrentEXP:              0,
@@ -59,54 +65,6 @@ func init() {
 		CurrentMana:             0,
 		MaxMana:                 10,
 	}
-	myKingTower = models.TowerState{
-		Type:      "KING",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
-	myGuardTower1 = models.TowerState{
-		Type:      "GUARD1",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
-	myGuardTower2 = models.TowerState{
-		Type:      "GUARD2",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
-	enemyKingTower = models.TowerState{
-		Type:      "KING",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
-	enemyGuardTower1 = models.TowerState{
-		Type:      "GUARD1",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
-	enemyGuardTower2 = models.TowerState{
-		Type:      "GUARD2",
-		CurrentHP: 0,
-		MaxHP:     0,
-		Attack:    0,
-		Defense:   0,
-		Destroyed: false,
-	}
 	myTroops = make([]models.TroopState, 0)
 }
 
@@ -226,16 +184,30 @@ func main() {
 
 		authenticated = true
 		// fmt.Println("\n✅ Successfully authenticated!") // Removed as handleLoginResponse already prints a success message
-		fmt.Println("Entering lobby - waiting for another player to connect...")
+		fmt.Println("Entering lobby - type 'queue' to look for a match.")
 
 		// Now that user is authenticated, display available commands
 		fmt.Println("\n=== Available Commands ===")
 		fmt.Println("Commands available in lobby:")
+		fmt.Println("  queue [mode...] - Join the matchmaking queue (modes: simple, enhanced, any)")
+		fmt.Println("  create [mode] [turn_timer] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>] - Create a private room")
+		fmt.Println("  join <code> - Join a private room")
+		fmt.Println("  challenge <username> [mode] [turn_timer] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>] - Challenge an online player")
+		fmt.Println("  leave - Leave the matchmaking queue or your room")
+		fmt.Println("  players - List online players with status and level")
+		fmt.Println("  who - Show who is online")
+		fmt.Println("  games - List live games")
+		fmt.Println("  spectate <game_id> [delay] - Watch a live game")
+		fmt.Println("  say <message> - Chat with the lobby (or your room)")
+		fmt.Println("  mute <username> / unmute <username> - Hide or show a player's chat")
+		fmt.Println("  rematch [swap] - Play your last opponent again")
 		fmt.Println("  help - Show this help information")
 		fmt.Println("  quit - Exit the game")
 		fmt.Println("Commands available in game:")
-		fmt.Println("  d <troop_name> - Deploy a troop (auto-targets enemy towers in sequence)")
+		fmt.Println("  d <troop_name> [target] - Deploy a troop at a tower (g1, g2, k), or the next open tower")
 		fmt.Println("  status - Display current game status")
+		fmt.Println("  say <message> / emote <name> - Chat with your opponent")
+		fmt.Println("  surrender / draw - Concede or offer a draw")
 		fmt.Println("  help - Display help information")
 		fmt.Println("  quit - Exit the game")
 		fmt.Println("========================")
@@ -265,18 +237,142 @@ func main() {
 			if input == "quit" || input == "exit" {
 				break
 			}
-			if input == "help" {
-				fmt.Println("\n==============================================")
-				fmt.Println("📋 LOBBY COMMANDS 📋")
-				fmt.Println("==============================================")
-				fmt.Println("  help   - Show this help information")
-				fmt.Println("  quit   - Exit the game")
-				fmt.Println("")
-				fmt.Println("You are in the lobby waiting for a game to start.")
-				fmt.Println("Please wait for another player to connect...")
-				fmt.Println("==============================================\n")
-			} else {
-				fmt.Println("Waiting for a game to start... (type 'help' for lobby commands or 'quit' to exit)")
+
+			lobbyParts := strings.Fields(input)
+			if len(lobbyParts) == 0 {
+				continue
+			}
+
+			switch lobbyParts[0] {
+			case "help":
+				displayLobbyHelp()
+			case "queue", "play":
+				// Remaining words are mode preferences, e.g. "queue enhanced simple", plus "2v2" for team matches
+				modes, teamSize := parseQueueArgs(lobbyParts[1:])
+				if err := client.JoinQueue(modes, teamSize); err != nil {
+					fmt.Printf("Error sending join queue request: %v\n", err)
+				}
+			case "leave":
+				if spectatingGame != "" {
+					if err := client.StopSpectating(); err != nil {
+						fmt.Printf("Error sending stop spectating request: %v\n", err)
+					}
+				} else if client.RoomCode != "" {
+					if err := client.LeaveRoom(); err != nil {
+						fmt.Printf("Error sending leave room request: %v\n", err)
+					}
+				} else if err := client.LeaveQueue(); err != nil {
+					fmt.Printf("Error sending leave queue request: %v\n", err)
+				}
+			case "create":
+				settings, err := parseMatchSettingsArgs(lobbyParts[1:])
+				if err != nil {
+					fmt.Printf("Usage: create [mode] [turn_timer_seconds] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>] (%v)\n", err)
+					continue
+				}
+				if err := client.CreateRoom(settings); err != nil {
+					fmt.Printf("Error sending create room request: %v\n", err)
+				}
+			case "join":
+				if len(lobbyParts) != 2 {
+					fmt.Println("Usage: join <room_code>")
+					continue
+				}
+				if err := client.JoinRoom(lobbyParts[1]); err != nil {
+					fmt.Printf("Error sending join room request: %v\n", err)
+				}
+			case "ready", "unready":
+				if err := client.SetRoomReady(lobbyParts[0] == "ready"); err != nil {
+					fmt.Printf("Error sending ready request: %v\n", err)
+				}
+			case "challenge":
+				if len(lobbyParts) < 2 {
+					fmt.Println("Usage: challenge <username> [mode] [turn_timer_seconds] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>]")
+					continue
+				}
+				settings, err := parseMatchSettingsArgs(lobbyParts[2:])
+				if err != nil {
+					fmt.Printf("Usage: challenge <username> [mode] [turn_timer_seconds] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>] (%v)\n", err)
+					continue
+				}
+				if err := client.Challenge(lobbyParts[1], settings); err != nil {
+					fmt.Printf("Error sending challenge: %v\n", err)
+				}
+			case "players":
+				playerListRequested = true
+				if err := client.ListPlayers(); err != nil {
+					fmt.Printf("Error sending list players request: %v\n", err)
+				}
+			case "who":
+				displayWho()
+			case "cards":
+				if err := client.GetCollection(); err != nil {
+					fmt.Printf("Error sending collection request: %v\n", err)
+				}
+			case "deck":
+				if len(lobbyParts) < 2 {
+					fmt.Println("Usage: deck <card> <card> ... | deck clear")
+					continue
+				}
+				cards := lobbyParts[1:]
+				if len(cards) == 1 && cards[0] == "clear" {
+					cards = []string{}
+				}
+				if err := client.SetDeck(cards); err != nil {
+					fmt.Printf("Error sending deck: %v\n", err)
+				}
+			case "say":
+				// Room members chat among themselves, everyone else chats in the lobby
+				scope := shared.ChatScopeLobby
+				if client.RoomCode != "" {
+					scope = shared.ChatScopeRoom
+				}
+				sendChat(client, scope, input)
+			case "mute", "unmute":
+				muteCommand(client, lobbyParts)
+			case "rematch":
+				swapSides := len(lobbyParts) == 2 && lobbyParts[1] == "swap"
+				if len(lobbyParts) > 2 || (len(lobbyParts) == 2 && !swapSides) {
+					fmt.Println("Usage: rematch [swap]")
+					continue
+				}
+				if err := client.RequestRematch(swapSides); err != nil {
+					fmt.Printf("Error sending rematch request: %v\n", err)
+				}
+			case "games":
+				if err := client.ListGames(); err != nil {
+					fmt.Printf("Error sending list games request: %v\n", err)
+				}
+			case "spectate", "watch":
+				if len(lobbyParts) < 2 || len(lobbyParts) > 3 {
+					fmt.Println("Usage: spectate <game_id> [delay_seconds]")
+					continue
+				}
+				delaySeconds := 0
+				if len(lobbyParts) == 3 {
+					delaySeconds, err = strconv.Atoi(lobbyParts[2])
+					if err != nil {
+						fmt.Println("Usage: spectate <game_id> [delay_seconds]")
+						continue
+					}
+				}
+				if err := client.SpectateGame(lobbyParts[1], delaySeconds); err != nil {
+					fmt.Printf("Error sending spectate request: %v\n", err)
+				}
+			case "accept", "decline":
+				if len(lobbyParts) != 2 {
+					fmt.Printf("Usage: %s <username>\n", lobbyParts[0])
+					continue
+				}
+				if err := client.RespondToChallenge(lobbyParts[1], lobbyParts[0] == "accept"); err != nil {
+					fmt.Printf("Error sending challenge response: %v\n", err)
+				}
+			default:
+				if client.InQueue {
+					fmt.Println("Waiting for a game to start... (type 'help' for lobby commands, 'leave' to leave the queue or 'quit' to exit)")
+				} else {
+					fmt.Println("Unknown command. Type 'queue' to look for a match or 'help' for lobby commands.")
+				}
 			}
 			continue // Loop back for next lobby input
 		}
@@ -285,7 +381,7 @@ func main() {
 		// Refresh hand/target info if it's our turn, right before prompting
 		if client.MyTurn {
 			// displayPlayerHandAndTargetInfo(&myPlayerState) // Moved to handleTurnNotification or specific command handlers
-			fmt.Printf("Your turn - Enter command (d <troop_name>, status, help, quit): ")
+			fmt.Printf("Your turn - Enter command (d <troop_name> [target], status, help, quit): ")
 		} else {
 			fmt.Print("(Waiting for opponent... Type status, help, or quit): ")
 		}
@@ -308,6 +404,54 @@ func main() {
 			continue
 		}
 
+		// Chat, surrender and draw offers work whether or not it's the player's turn
+		chatParts := strings.Fields(input)
+		if len(chatParts) > 0 {
+			switch chatParts[0] {
+			case "say":
+				sendChat(client, shared.ChatScopeMatch, input)
+				continue
+			case "team":
+				sendChat(client, shared.ChatScopeTeam, input)
+				continue
+			case "emote":
+				if len(chatParts) != 2 {
+					displayEmotes()
+					continue
+				}
+				if err := client.SendEmote(chatParts[1]); err != nil {
+					fmt.Printf("Error sending emote: %v\n", err)
+				}
+				continue
+			case "emotes":
+				displayEmotes()
+				continue
+			case "mute", "unmute":
+				muteCommand(client, chatParts)
+				continue
+			case "surrender":
+				if err := client.Surrender(); err != nil {
+					fmt.Printf("Error sending surrender: %v\n", err)
+				}
+				continue
+			case "draw":
+				var err error
+				switch {
+				case len(chatParts) == 1:
+					err = client.OfferDraw()
+				case len(chatParts) == 2 && (chatParts[1] == "accept" || chatParts[1] == "decline"):
+					err = client.RespondToDraw(chatParts[1] == "accept")
+				default:
+					fmt.Println("Usage: draw [accept|decline]")
+					continue
+				}
+				if err != nil {
+					fmt.Printf("Error sending draw command: %v\n", err)
+				}
+				continue
+			}
+		}
+
 		// Commands below are only processed if it's the player's turn
 		if !client.MyTurn {
 			fmt.Println("It's not your turn. Type 'status', 'help', or 'quit'.")
@@ -323,60 +467,63 @@ func main() {
 		switch parts[0] {
 		case "d", "deploy":
 			if len(parts) < 2 {
-				fmt.Println("Usage: d <troop_name>")
+				fmt.Println("Usage: d <troop_name> [target]")
 				continue
 			}
 
 			troopName := parts[1]
 
 			// Automatically determine the target tower
-			var targetTowerID string
-
-			// For Queen, we don't need a target tower for the ability to work
-			if troopName == "Queen" {
-				// Any target ID will work for Queen since server ignores it for special troops
-				// But we need to provide a syntactically correct ID
-				targetTowerID = opponentUsername + "_KING"
+			var targetTowerID, targetDesc string
+
+			// Spells need no target: Fireball picks a tower and Freeze an enemy building, or take one
+			// like a troop.
+			// Buildings are placed on your own side.
+			cardType := handCardType(troopName)
+			if cardType == shared.CardBuilding || (cardType == shared.CardSpell && len(parts) < 3) {
+				targetDesc = "(" + strings.ToLower(cardType) + ")"
 			} else {
-				// Auto-select target based on game rules
-				// Guard Tower 1 must be destroyed before targeting Guard Tower 2 or King
-				if !opponentState.GuardTower1.Destroyed {
-					targetTowerID = opponentState.GuardTower1.ID
-				} else if !opponentState.GuardTower2.Destroyed {
-					targetTowerID = opponentState.GuardTower2.ID
-				} else if !opponentState.KingTower.Destroyed {
-					targetTowerID = opponentState.KingTower.ID
+				var target models.TowerState
+				var found bool
+				if len(parts) >= 3 {
+					// Explicit target by short name (g1, g2, k), type or ID, or an enemy building by name or ID
+					target, found = findTower(opponentState, parts[2])
+					if building, ok := findBuilding(opponentState, parts[2]); ok && !found {
+						target, found = models.TowerState{ID: building.ID, Name: building.Name + " (" + building.ID + ")", Targetable: true}, true
+					}
+					if !found {
+						fmt.Printf("Unknown target %q. Opponent's towers: %s%s\n", parts[2], towerShortNames(opponentState), buildingNames(opponentState))
+						continue
+					}
+					if !target.Targetable {
+						fmt.Printf("%s cannot be attacked right now.\n", target.Name)
+						continue
+					}
+				} else if preferred := preferredTarget(troopName); preferred != "" {
+					// An empty target lets the server pick the tower the troop prefers
+					target, found = models.TowerState{Name: "its preferred target (" + preferred + ")"}, true
 				} else {
-					// All towers destroyed? This shouldn't happen as game should be over
-					fmt.Println("Error: Can't find a valid target tower.")
-					continue
-				}
-
-				// Make sure we have a valid ID
-				if targetTowerID == "" {
-					// Construct a plausible ID if we somehow don't have it from server
-					if !opponentState.GuardTower1.Destroyed {
-						targetTowerID = opponentUsername + "_GUARD1"
-					} else if !opponentState.GuardTower2.Destroyed {
-						targetTowerID = opponentUsername + "_GUARD2"
-					} else {
-						targetTowerID = opponentUsername + "_KING"
+					// Auto-select the next open tower
+					target, found = nextTarget(opponentState)
+					if !found {
+						// All towers destroyed? This shouldn't happen as game should be over
+						fmt.Println("Error: Can't find a valid target tower.")
+						continue
 					}
 				}
+				targetTowerID = target.ID
+				targetDesc = target.Name
 			}
 
 			// Show clear feedback about what we're targeting
-			var targetDesc string
-			if strings.Contains(targetTowerID, "KING") {
-				targetDesc = "King Tower"
-			} else if strings.Contains(targetTowerID, "GUARD1") {
-				targetDesc = "Guard Tower 1"
-			} else if strings.Contains(targetTowerID, "GUARD2") {
-				targetDesc = "Guard Tower 2"
-			} else {
-				targetDesc = targetTowerID // fallback
+			switch cardType {
+			case shared.CardSpell:
+				fmt.Printf("\n>>> CASTING %s %s <<<\n", strings.ToUpper(troopName), targetDesc)
+			case shared.CardBuilding:
+				fmt.Printf("\n>>> PLACING %s on your side <<<\n", strings.ToUpper(troopName))
+			default:
+				fmt.Printf("\n>>> DEPLOYING %s to attack %s <<<\n", strings.ToUpper(troopName), targetDesc)
 			}
-			fmt.Printf("\n>>> DEPLOYING %s to attack %s <<<\n", strings.ToUpper(troopName), targetDesc)
 
 			// Send deploy command
 			err := client.DeployTroop(troopName, targetTowerID)
@@ -385,7 +532,8 @@ func main() {
 			}
 			continue // Wait for server updates before re-prompting
 		case "skip":
-			fmt.Println("\n>>> SKIPPING TURN <<<\n")
+			fmt.Println("\n>>> SKIPPING TURN <<<")
+			fmt.Println()
 			err := client.SendSkipTurnCommand() // We'll need to define this method in network/client.go
 			if err != nil {
 				fmt.Printf("Error sending skip command: %v\n", err)
@@ -430,13 +578,53 @@ func handleServerMessages(client *network.GameClient, stopCh <-chan struct{}) {
 			case models.MsgTypeGameStartNotification:
 				handleGameStartNotification(client, message.Payload)
 			case models.MsgTypeGameStateUpdate:
-				handleGameStateUpdate(client, message.Payload)
+				if spectatingGame != "" {
+					handleSpectatedStateUpdate(message.Payload)
+				} else {
+					handleGameStateUpdate(client, message.Payload)
+				}
 			case models.MsgTypeTurnNotification:
 				handleTurnNotification(client, message.Payload)
 			case models.MsgTypeActionResult:
 				handleActionResult(client, message.Payload)
 			case models.MsgTypeGameOverNotification:
-				handleGameOverNotification(message.Payload)
+				if spectatingGame != "" {
+					handleSpectatedGameOver(message.Payload)
+				} else {
+					handleGameOverNotification(message.Payload)
+				}
+			case models.MsgTypeQueueStatus:
+				handleQueueStatus(message.Payload)
+			case models.MsgTypeRoomUpdate:
+				handleRoomUpdate(message.Payload)
+			case models.MsgTypeChallengeNotify:
+				handleChallengeNotification(message.Payload)
+			case models.MsgTypeChallengeResult:
+				handleChallengeResult(message.Payload)
+			case models.MsgTypePlayerList:
+				handlePlayerList(message.Payload)
+			case models.MsgTypeCollection:
+				handleCollection(message.Payload)
+			case models.MsgTypePresenceUpdate:
+				handlePresenceUpdate(client, message.Payload)
+			case models.MsgTypeGameList:
+				handleGameList(message.Payload)
+			case models.MsgTypeSpectateStart:
+				handleSpectateStart(message.Payload)
+			case models.MsgTypeSpectateEnd:
+				handleSpectateEnd(message.Payload)
+			case models.MsgTypeSpectatorCount:
+				handleSpectatorCount(message.Payload)
+			case models.MsgTypeChatMessage:
+				handleChatMessage(client, message.Payload)
+			case models.MsgTypeSystemNotice:
+				handleSystemNotice(client, message.Payload)
+			case models.MsgTypeDrawOffered:
+				handleDrawOffered(client, message.Payload)
+			case models.MsgTypeRematchOffered:
+				handleRematchOffered(client, message.Payload)
+			case models.MsgTypeServerShutdown:
+				handleServerShutdown(message.Payload)
 			}
 		case err := <-client.DisconnectCh:
 			fmt.Printf("Disconnected from server: %v\n", err)
@@ -529,7 +717,9 @@ func handleGameStartNotification(c *network.GameClient, payload interface{}) {
 
 	// Extract opponent username and game mode
 	opponentUsername, _ = gameStartMap["opponentUsername"].(string)
+	teammateUsername, _ = gameStartMap["teammate"].(string)
 	gameMode, _ = gameStartMap["gameMode"].(string)
+	matchPhase = "" // Set by the first game state update if the match has a clock
 
 	// Parse your player info
 	if pInfo, ok := gameStartMap["yourPlayerInfo"].(map[string]interface{}); ok {
@@ -538,8 +728,34 @@ func handleGameStartNotification(c *network.GameClient, payload interface{}) {
 		fmt.Println("Error parsing yourPlayerInfo from GameStartNotification")
 	}
 
-	fmt.Printf("You are playing against: %s\n", opponentUsername)
+	if teammateUsername != "" {
+		fmt.Printf("You are playing with %s against: %s\n", teammateUsername, strings.Join(parseStringList(gameStartMap["opponents"]), " & "))
+		fmt.Printf("Turn order: %s\n", strings.Join(parseStringList(gameStartMap["turnOrder"]), " -> "))
+	} else {
+		fmt.Printf("You are playing against: %s\n", opponentUsername)
+	}
 	fmt.Printf("Game Mode: %s\n", gameMode)
+	yourLevel, _ := gameStartMap["yourEffectiveLevel"].(float64)
+	opponentLevel, _ := gameStartMap["opponentEffectiveLevel"].(float64)
+	if yourLevel > 0 {
+		fmt.Printf("Levels: you %d, %s %d\n", int(yourLevel), opponentUsername, int(opponentLevel))
+	}
+	if handicapMap, ok := gameStartMap["handicap"].(map[string]interface{}); ok {
+		username, _ := handicapMap["username"].(string)
+		kind, _ := handicapMap["kind"].(string)
+		amount, _ := handicapMap["amount"].(float64)
+		if username == myPlayerState.Username {
+			username = "you"
+		}
+		if kind == shared.HandicapMana {
+			fmt.Printf("Handicap: +%d starting mana for %s\n", int(amount), username)
+		} else {
+			fmt.Printf("Handicap: +%d%% tower HP for %s\n", int(amount), username)
+		}
+	}
+	if targeting, _ := gameStartMap["targeting"].(string); targeting == shared.TargetingLanes {
+		fmt.Println("Lanes: either guard tower can be attacked first; the King opens once one falls.")
+	}
 	// Initial game status display will be handled by the first GameStateUpdate
 	// displayGameStatus(c, &myPlayerState, &opponentState, currentTurn, opponentUsername)
 }
@@ -551,14 +767,21 @@ func parsePlayerState(playerMap map[string]interface{}, defaultUsername string)
 	if username, ok := playerMap["username"].(string); ok {
 		ps.Username = username
 	}
-	if kt, ok := playerMap["kingTower"].(map[string]interface{}); ok {
-		ps.KingTower = parseTowerState(kt)
-	}
-	if gt1, ok := playerMap["guardTower1"].(map[string]interface{}); ok {
-		ps.GuardTower1 = parseTowerState(gt1)
+	if towersList, ok := playerMap["towers"].([]interface{}); ok {
+		ps.Towers = make([]models.TowerState, len(towersList))
+		for i, t := range towersList {
+			if towerMap, ok := t.(map[string]interface{}); ok {
+				ps.Towers[i] = parseTowerState(towerMap)
+			}
+		}
 	}
-	if gt2, ok := playerMap["guardTower2"].(map[string]interface{}); ok {
-		ps.GuardTower2 = parseTowerState(gt2)
+	if buildingsList, ok := playerMap["buildings"].([]interface{}); ok {
+		ps.Buildings = make([]models.BuildingState, 0, len(buildingsList))
+		for _, b := range buildingsList {
+			if buildingMap, ok := b.(map[string]interface{}); ok {
+				ps.Buildings = append(ps.Buildings, parseBuildingState(buildingMap))
+			}
+		}
 	}
 	if troopsList, ok := playerMap["troops"].([]interface{}); ok {
 		ps.Troops = make([]models.TroopState, len(troopsList))
@@ -584,18 +807,51 @@ func parsePlayerState(playerMap map[string]interface{}, defaultUsername string)
 	if maxMana, ok := playerMap["maxMana"].(float64); ok {
 		ps.MaxMana = int(maxMana)
 	}
+	// Opponents' hands (and usually mana) are hidden by the server
+	if handSize, ok := playerMap["handSize"].(float64); ok {
+		ps.HandSize = int(handSize)
+	}
+	ps.HandHidden, _ = playerMap["handHidden"].(bool)
+	ps.ManaHidden, _ = playerMap["manaHidden"].(bool)
+	if rage, ok := playerMap["ragePercent"].(float64); ok {
+		ps.RagePercent = int(rage)
+	}
 
 	return ps
 }
 
+// formatVisibleMana returns a player's mana, or a note that it is hidden
+func formatVisibleMana(player *models.PlayerState) string {
+	if player.ManaHidden {
+		return "hidden"
+	}
+	if player.MaxMana == 0 {
+		return "not used"
+	}
+	return fmt.Sprintf("%d / %d", player.CurrentMana, player.MaxMana)
+}
+
 func parseTowerState(towerMap map[string]interface{}) models.TowerState {
 	ts := models.TowerState{}
 	if id, ok := towerMap["id"].(string); ok {
 		ts.ID = id
 	}
+	if name, ok := towerMap["name"].(string); ok {
+		ts.Name = name
+	}
 	if typ, ok := towerMap["type"].(string); ok {
 		ts.Type = typ
 	}
+	if role, ok := towerMap["role"].(string); ok {
+		ts.Role = role
+	}
+	if requires, ok := towerMap["requires"].([]interface{}); ok {
+		for _, required := range requires {
+			if towerType, ok := required.(string); ok {
+				ts.Requires = append(ts.Requires, towerType)
+			}
+		}
+	}
 	if hp, ok := towerMap["currentHP"].(float64); ok { // JSON numbers are float64
 		ts.CurrentHP = int(hp)
 	}
@@ -611,9 +867,114 @@ func parseTowerState(towerMap map[string]interface{}) models.TowerState {
 	if destroyed, ok := towerMap["destroyed"].(bool); ok {
 		ts.Destroyed = destroyed
 	}
+	if targetable, ok := towerMap["targetable"].(bool); ok {
+		ts.Targetable = targetable
+	}
 	return ts
 }
 
+// towerShortName returns the short name used to target a tower: k for the King,
+// g1, g2, ... for guard towers of type GUARD1, GUARD2, ..., and the lower-case type otherwise
+func towerShortName(tower models.TowerState) string {
+	if tower.Role == shared.KingTowerRole {
+		return "k"
+	}
+	if number, found := strings.CutPrefix(tower.Type, "GUARD"); found && number != "" {
+		return "g" + number
+	}
+	return strings.ToLower(tower.Type)
+}
+
+// towerShortNames lists the short names of a player's towers, e.g. "g1, g2, k"
+func towerShortNames(player models.PlayerState) string {
+	names := make([]string, len(player.Towers))
+	for i, tower := range player.Towers {
+		names[i] = towerShortName(tower)
+	}
+	return strings.Join(names, ", ")
+}
+
+// findTower finds one of a player's towers by short name, type or ID
+func findTower(player models.PlayerState, name string) (models.TowerState, bool) {
+	for _, tower := range player.Towers {
+		if strings.EqualFold(name, towerShortName(tower)) || strings.EqualFold(name, tower.Type) || name == tower.ID {
+			return tower, true
+		}
+	}
+	return models.TowerState{}, false
+}
+
+// preferredTarget returns the preferred target of a troop in the player's hand, e.g. "lowest hp",
+// or "" if it attacks the next open tower like the client's own auto-targeting
+func preferredTarget(troopName string) string {
+	for _, troop := range myPlayerState.Troops {
+		if troop.Name == troopName {
+			return targetLabel(troop.PreferredTarget)
+		}
+	}
+	return ""
+}
+
+// handCardType returns the card type of a card in the player's hand, or "" if it is not there
+func handCardType(cardName string) string {
+	for _, troop := range myPlayerState.Troops {
+		if troop.Name == cardName {
+			return troop.CardType
+		}
+	}
+	return ""
+}
+
+// findBuilding finds one of a player's buildings by ID or card name
+func findBuilding(player models.PlayerState, name string) (models.BuildingState, bool) {
+	for _, building := range player.Buildings {
+		if name == building.ID || strings.EqualFold(name, building.Name) {
+			return building, true
+		}
+	}
+	return models.BuildingState{}, false
+}
+
+// buildingNames lists the IDs of a player's buildings after their towers, e.g. "; buildings: b_Cannon_3"
+func buildingNames(player models.PlayerState) string {
+	if len(player.Buildings) == 0 {
+		return ""
+	}
+	ids := make([]string, len(player.Buildings))
+	for i, building := range player.Buildings {
+		ids[i] = building.ID
+	}
+	return "; buildings: " + strings.Join(ids, ", ")
+}
+
+// targetLabel returns a readable preferred target, or "" for the default (TOWERS)
+func targetLabel(preferredTarget string) string {
+	if preferredTarget == "" || preferredTarget == shared.TargetTowers {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(preferredTarget, "_", " "))
+}
+
+// nextTarget picks the tower a deployed troop attacks: the first targetable guard tower,
+// or the king tower once no guard tower can be attacked
+func nextTarget(opponent models.PlayerState) (models.TowerState, bool) {
+	var king *models.TowerState
+	for i, tower := range opponent.Towers {
+		if !tower.Targetable {
+			continue
+		}
+		if tower.Role == shared.KingTowerRole {
+			king = &opponent.Towers[i]
+			continue
+		}
+		return tower, true
+	}
+	if king != nil {
+		return *king, true
+	}
+	return models.TowerState{}, false
+}
+
 func parseTroopState(troopMap map[string]interface{}) models.TroopState {
 	trs := models.TroopState{}
 	if name, ok := troopMap["name"].(string); ok {
@@ -632,9 +993,90 @@ func parseTroopState(troopMap map[string]interface{}) models.TroopState {
 	if manaCost, ok := troopMap["manaCost"].(float64); ok {
 		trs.ManaCost = int(manaCost)
 	}
+	trs.CardType, _ = troopMap["cardType"].(string)
+	trs.Description, _ = troopMap["description"].(string)
+	trs.CritChancePercent, _ = troopMap["critChancePercent"].(float64)
+	trs.CritMultiplier, _ = troopMap["critMultiplier"].(float64)
+	if penetration, ok := troopMap["armorPenetrationPercent"].(float64); ok {
+		trs.ArmorPenetrationPercent = int(penetration)
+	}
+	trs.PreferredTarget, _ = troopMap["preferredTarget"].(string)
+	if splash, ok := troopMap["splashPercent"].(float64); ok {
+		trs.SplashPercent = int(splash)
+	}
+	if chain, ok := troopMap["chainTargets"].(float64); ok {
+		trs.ChainTargets = int(chain)
+	}
+	if falloff, ok := troopMap["chainFalloffPercent"].(float64); ok {
+		trs.ChainFalloffPercent = int(falloff)
+	}
+	if lifetime, ok := troopMap["lifetime"].(float64); ok {
+		trs.Lifetime = int(lifetime)
+	}
+	trs.Protects, _ = troopMap["protects"].(string)
 	return trs
 }
 
+func parseBuildingState(buildingMap map[string]interface{}) models.BuildingState {
+	bs := models.BuildingState{}
+	bs.ID, _ = buildingMap["id"].(string)
+	bs.Name, _ = buildingMap["name"].(string)
+	bs.Owner, _ = buildingMap["owner"].(string)
+	if hp, ok := buildingMap["currentHP"].(float64); ok {
+		bs.CurrentHP = int(hp)
+	}
+	if maxHP, ok := buildingMap["maxHP"].(float64); ok {
+		bs.MaxHP = int(maxHP)
+	}
+	if atk, ok := buildingMap["attack"].(float64); ok {
+		bs.Attack = int(atk)
+	}
+	if def, ok := buildingMap["defense"].(float64); ok {
+		bs.Defense = int(def)
+	}
+	bs.Protects, _ = buildingMap["protects"].(string)
+	if turns, ok := buildingMap["turnsLeft"].(float64); ok {
+		bs.TurnsLeft = int(turns)
+	}
+	if frozen, ok := buildingMap["frozenTurns"].(float64); ok {
+		bs.FrozenTurns = int(frozen)
+	}
+	return bs
+}
+
+// buildingTraits describes what a building card does once placed, e.g. " [lasts 3 turns, guards KING]"
+func buildingTraits(lifetime int, protects string) string {
+	if protects == "" {
+		protects = "every tower"
+	}
+	return fmt.Sprintf(" [lasts %d turns, guards %s]", lifetime, protects)
+}
+
+// troopTraits describes a troop's crit, armor penetration, splash, chain and preferred target,
+// e.g. " [crit 35% x1.5, pierce 50%]"
+func troopTraits(troop models.TroopState) string {
+	traits := make([]string, 0, 5)
+	if troop.CritChancePercent > 0 {
+		traits = append(traits, fmt.Sprintf("crit %g%% x%g", troop.CritChancePercent, troop.CritMultiplier))
+	}
+	if troop.ArmorPenetrationPercent > 0 {
+		traits = append(traits, fmt.Sprintf("pierce %d%%", troop.ArmorPenetrationPercent))
+	}
+	if troop.SplashPercent > 0 {
+		traits = append(traits, fmt.Sprintf("splash %d%%", troop.SplashPercent))
+	}
+	if troop.ChainTargets > 0 {
+		traits = append(traits, fmt.Sprintf("chain %d (-%d%% per jump)", troop.ChainTargets, troop.ChainFalloffPercent))
+	}
+	if preferred := targetLabel(troop.PreferredTarget); preferred != "" {
+		traits = append(traits, "targets "+preferred)
+	}
+	if len(traits) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(traits, ", ") + "]"
+}
+
 // handleGameStateUpdate handles a game state update from the server
 func handleGameStateUpdate(c *network.GameClient, payload interface{}) {
 	gameStateMap, ok := payload.(map[string]interface{})
@@ -656,23 +1098,22 @@ func handleGameStateUpdate(c *network.GameClient, payload interface{}) {
 		lastActionLog = lal
 		fmt.Printf("\n--- Server Log: %s ---\n", lastActionLog)
 	}
+	matchPhase, phaseEndsAt = parseMatchClock(gameStateMap)
 
-	// Update player states
-	if pA, ok := gameStateMap["playerA"].(map[string]interface{}); ok {
-		// Determine if playerA is me or opponent based on Username
-		usernameA, _ := pA["username"].(string)
-		if usernameA == myPlayerState.Username {
-			myPlayerState = parsePlayerState(pA, myPlayerState.Username)
-		} else {
-			opponentState = parsePlayerState(pA, opponentUsername)
+	// Update player states. In 2v2 the partners share their team's towers, so the opponent tower
+	// owner's state is enough to show the other team.
+	for _, key := range []string{"playerA", "playerB", "partnerA", "partnerB"} {
+		playerMap, ok := gameStateMap[key].(map[string]interface{})
+		if !ok {
+			continue
 		}
-	}
-	if pB, ok := gameStateMap["playerB"].(map[string]interface{}); ok {
-		usernameB, _ := pB["username"].(string)
-		if usernameB == myPlayerState.Username {
-			myPlayerState = parsePlayerState(pB, myPlayerState.Username)
-		} else {
-			opponentState = parsePlayerState(pB, opponentUsername)
+		switch username, _ := playerMap["username"].(string); username {
+		case myPlayerState.Username:
+			myPlayerState = parsePlayerState(playerMap, myPlayerState.Username)
+		case teammateUsername:
+			teammateState = parsePlayerState(playerMap, teammateUsername)
+		case opponentUsername:
+			opponentState = parsePlayerState(playerMap, opponentUsername)
 		}
 	}
 
@@ -703,13 +1144,17 @@ func handleTurnNotification(client *network.GameClient, payload interface{}) {
 
 	if ctUser, ok := turnNotifMap["currentTurnUsername"].(string); ok {
 		currentTurn = ctUser // Update currentTurn
-		fmt.Printf("\n--- It's now %s's turn. ---\n", currentTurn)
+		if timeLimit, ok := turnNotifMap["turnTimeLimitSeconds"].(float64); ok && timeLimit > 0 {
+			fmt.Printf("\n--- It's now %s's turn (%d seconds). ---\n", currentTurn, int(timeLimit))
+		} else {
+			fmt.Printf("\n--- It's now %s's turn. ---\n", currentTurn)
+		}
 		if currentTurn == myPlayerState.Username { // Compare with updated myPlayerState.Username
 			client.MyTurn = true
 			// Display hand and prompt only if game is not over
 			if !client.GameOver {
 				displayPlayerHandAndTargetInfo(&myPlayerState)
-				fmt.Print("Your turn - Enter command (d <troop_name>, status, help, quit): ")
+				fmt.Print("Your turn - Enter command (d <troop_name> [target], status, help, quit): ")
 			}
 		} else {
 			client.MyTurn = false
@@ -721,31 +1166,36 @@ func handleTurnNotification(client *network.GameClient, payload interface{}) {
 }
 
 func displayPlayerHandAndTargetInfo(player *models.PlayerState) {
-	fmt.Printf("\n--- Mana: %d/%d ---\n", player.CurrentMana, player.MaxMana)
+	if player.MaxMana > 0 { // Rule sets without mana report no maximum
+		fmt.Printf("\n--- Mana: %d/%d ---\n", player.CurrentMana, player.MaxMana)
+	}
 	fmt.Println("--- Your Hand ---")
 	if len(player.Troops) == 0 {
 		fmt.Println("  Your hand is empty!")
 	} else {
 		for _, troop := range player.Troops {
 			// Attempt to display ManaCost - requires server to send it in TroopState
-			if troop.ManaCost > 0 {
-				fmt.Printf("  - %s (ATK:%d DEF:%d HP:%d Mana:%d)\n", troop.Name, troop.Attack, troop.Defense, troop.HP, troop.ManaCost)
+			if troop.CardType == shared.CardSpell {
+				fmt.Printf("  - ✨ SPELL %s (Mana:%d): %s\n", troop.Name, troop.ManaCost, troop.Description)
+			} else if troop.CardType == shared.CardBuilding {
+				fmt.Printf("  - 🏰 BUILDING %s (ATK:%d DEF:%d HP:%d Mana:%d)%s\n", troop.Name, troop.Attack, troop.Defense, troop.HP, troop.ManaCost, buildingTraits(troop.Lifetime, troop.Protects))
+			} else if troop.ManaCost > 0 {
+				fmt.Printf("  - %s (ATK:%d DEF:%d HP:%d Mana:%d)%s\n", troop.Name, troop.Attack, troop.Defense, troop.HP, troop.ManaCost, troopTraits(troop))
 			} else {
-				fmt.Printf("  - %s (ATK:%d DEF:%d HP:%d)\n", troop.Name, troop.Attack, troop.Defense, troop.HP)
+				fmt.Printf("  - %s (ATK:%d DEF:%d HP:%d)%s\n", troop.Name, troop.Attack, troop.Defense, troop.HP, troopTraits(troop))
 			}
 		}
 	}
 	fmt.Println("-----------------")
+	if player.RagePercent > 0 {
+		fmt.Printf("  😡 Rage: your next troop attacks with +%d%% ATK\n", player.RagePercent)
+	}
 
 	// Display target info (simplified, relies on auto-targeting logic in main loop)
 	// This could be enhanced to show specific target details based on opponentState
 	fmt.Println("--- Target Info ---")
-	if !opponentState.GuardTower1.Destroyed {
-		fmt.Printf("  Next auto-target: Opponent's Guard Tower 1 (ID: %s)\n", opponentState.GuardTower1.ID)
-	} else if !opponentState.GuardTower2.Destroyed {
-		fmt.Printf("  Next auto-target: Opponent's Guard Tower 2 (ID: %s)\n", opponentState.GuardTower2.ID)
-	} else if !opponentState.KingTower.Destroyed {
-		fmt.Printf("  Next auto-target: Opponent's King Tower (ID: %s)\n", opponentState.KingTower.ID)
+	if target, found := nextTarget(opponentState); found {
+		fmt.Printf("  Next auto-target: Opponent's %s (ID: %s)\n", target.Name, target.ID)
 	} else {
 		fmt.Println("  All opponent towers destroyed or game is over.")
 	}
@@ -791,20 +1241,698 @@ func handleGameOverNotification(payload interface{}) {
 
 	fmt.Println("\n==============================================")
 	fmt.Println("GAME OVER!")
-	if winner != "" && winner != "DRAW" {
+	if winningTeam := parseStringList(gameOverMap["winningTeam"]); len(winningTeam) > 0 {
+		fmt.Printf("Winners: %s\n", strings.Join(winningTeam, " & "))
+	} else if winner != "" && winner != "DRAW" {
 		fmt.Printf("Winner: %s\n", winner)
 	} else if winner == "DRAW" {
 		fmt.Println("Result: It's a DRAW!")
 	}
 	fmt.Printf("Reason: %s\n", reason)
+	displayCardRewards(gameOverMap["cardRewards"])
 	fmt.Println("==============================================")
-	fmt.Println("Thank you for playing! You can type 'quit' to exit.")
+	if teammateUsername != "" {
+		fmt.Println("Thank you for playing! Type 'queue 2v2' to find a new team match, 'queue' for 1v1, or 'quit' to exit.")
+	} else {
+		fmt.Println("Thank you for playing! Type 'rematch' to play the same opponent again, 'queue' to find a new one, or 'quit' to exit.")
+	}
 	// Set a flag to stop prompting for turns or actions.
 	// This should be handled by the main loop checking client.Connected and client.GameOver (if we add such a flag)
 	// For now, client.MyTurn will be false if game over notification is processed after a turn notification.
 	// A more robust solution is a specific client.GameOver flag.
 }
 
+// displayCardRewards prints the card copies each player received at the end of a match
+func displayCardRewards(value interface{}) {
+	rewards, ok := value.([]interface{})
+	if !ok || len(rewards) == 0 {
+		return
+	}
+	fmt.Println("Card rewards:")
+	for _, r := range rewards {
+		rewardMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		username, _ := rewardMap["username"].(string)
+		card, _ := rewardMap["card"].(string)
+		copies, _ := rewardMap["copies"].(float64)
+		level, _ := rewardMap["level"].(float64)
+		upgraded, _ := rewardMap["upgraded"].(bool)
+		line := fmt.Sprintf("  %s: %s x%d", username, card, int(copies))
+		if upgraded {
+			line += fmt.Sprintf(" ⬆️ upgraded to level %d!", int(level))
+		}
+		fmt.Println(line)
+	}
+}
+
+// handleCollection displays the player's card collection and deck
+func handleCollection(payload interface{}) {
+	collectionMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing collection")
+		return
+	}
+	level, _ := collectionMap["level"].(float64)
+	deck := parseStringList(collectionMap["deck"])
+
+	fmt.Println("\n==============================================")
+	fmt.Printf("🃏 CARD COLLECTION (player level %d) 🃏\n", int(level))
+	fmt.Println("==============================================")
+	if cards, ok := collectionMap["cards"].([]interface{}); ok {
+		for _, c := range cards {
+			cardMap, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := cardMap["name"].(string)
+			cardType, _ := cardMap["cardType"].(string)
+			manaCost, _ := cardMap["manaCost"].(float64)
+			unlocked, _ := cardMap["unlocked"].(bool)
+			if !unlocked {
+				unlockLevel, _ := cardMap["unlockLevel"].(float64)
+				fmt.Printf("  🔒 %-10s %-8s Mana: %d - unlocks at level %d\n", name, cardType, int(manaCost), int(unlockLevel))
+				continue
+			}
+			cardLevel, _ := cardMap["level"].(float64)
+			copies, _ := cardMap["copies"].(float64)
+			nextLevel, _ := cardMap["copiesForNextLevel"].(float64)
+			inDeck, _ := cardMap["inDeck"].(bool)
+			progress := "max level"
+			if nextLevel > 0 {
+				progress = fmt.Sprintf("%d/%d copies", int(copies), int(nextLevel))
+			}
+			marker := "  "
+			if inDeck {
+				marker = "⭐"
+			}
+			fmt.Printf("  %s %-10s %-8s Mana: %d - Level %d (%s)\n", marker, name, cardType, int(manaCost), int(cardLevel), progress)
+		}
+	}
+	if len(deck) > 0 {
+		fmt.Printf("Deck: %s\n", strings.Join(deck, ", "))
+	} else {
+		fmt.Println("Deck: every unlocked card (use 'deck <card> ...' to choose)")
+	}
+	fmt.Println("==============================================")
+	fmt.Print("> ")
+}
+
+// handleQueueStatus handles a matchmaking queue status update from the server
+func handleQueueStatus(payload interface{}) {
+	statusMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing queue status")
+		return
+	}
+
+	inQueue, _ := statusMap["inQueue"].(bool)
+	message, _ := statusMap["message"].(string)
+
+	if !inQueue {
+		fmt.Printf("\n--- Queue: %s ---\n> ", message)
+		return
+	}
+
+	position, _ := statusMap["position"].(float64)
+	queueSize, _ := statusMap["queueSize"].(float64)
+	estimatedWait, _ := statusMap["estimatedWaitSeconds"].(float64)
+	modes := make([]string, 0)
+	if modesList, ok := statusMap["modes"].([]interface{}); ok {
+		for _, m := range modesList {
+			if mode, ok := m.(string); ok {
+				modes = append(modes, mode)
+			}
+		}
+	}
+
+	fmt.Printf("\n--- Queue: %s Position %d/%d, modes %s, estimated wait ~%ds ---\n> ",
+		message, int(position), int(queueSize), strings.Join(modes, "/"), int(estimatedWait))
+}
+
+// parseQueueArgs splits "queue" command arguments into mode preferences and the team size ("2v2")
+func parseQueueArgs(args []string) ([]string, int) {
+	modes := make([]string, 0, len(args))
+	teamSize := 0
+	for _, arg := range args {
+		if strings.EqualFold(arg, "2v2") {
+			teamSize = shared.TeamMatchSize
+			continue
+		}
+		modes = append(modes, arg)
+	}
+	return modes, teamSize
+}
+
+// parseStringList converts a JSON array of strings, skipping anything else
+func parseStringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if str, ok := item.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
+// parseMatchSettingsArgs parses optional "[mode] [turn_timer_seconds] [starting_mana] [reveal] [rules=<name>]
+// [cap=<level>] [handicap=<kind>]" command arguments. Missing values are left unset so the server applies its defaults.
+func parseMatchSettingsArgs(args []string) (models.MatchSettingsRequest, error) {
+	settings := models.MatchSettingsRequest{MatchSettings: models.MatchSettings{Mode: "SIMPLE"}}
+
+	// "reveal" anywhere in the arguments lets both players see each other's mana,
+	// "rules=<name>" picks a custom rule set of the server, "cap=<level>" caps both players'
+	// levels and "handicap=<kind>" gives the lower-level player a bonus
+	positional := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.EqualFold(arg, "reveal") {
+			settings.RevealOpponentMana = true
+		} else if ruleSet, found := strings.CutPrefix(arg, "rules="); found {
+			settings.RuleSet = ruleSet
+		} else if levelCap, found := strings.CutPrefix(arg, "cap="); found {
+			capValue, err := strconv.Atoi(levelCap)
+			if err != nil {
+				return settings, fmt.Errorf("invalid level cap %q", levelCap)
+			}
+			settings.LevelCap = capValue
+		} else if handicap, found := strings.CutPrefix(arg, "handicap="); found {
+			settings.Handicap = strings.ToUpper(handicap)
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	args = positional
+
+	if len(args) > 3 {
+		return settings, fmt.Errorf("too many arguments")
+	}
+	if len(args) >= 1 {
+		settings.Mode = strings.ToUpper(args[0])
+	}
+	if len(args) >= 2 {
+		turnTimer, err := strconv.Atoi(args[1])
+		if err != nil {
+			return settings, fmt.Errorf("invalid turn timer %q", args[1])
+		}
+		settings.TurnTimerSeconds = turnTimer
+	}
+	if len(args) == 3 {
+		startingMana, err := strconv.Atoi(args[2])
+		if err != nil {
+			return settings, fmt.Errorf("invalid starting mana %q", args[2])
+		}
+		settings.StartingMana = &startingMana
+	}
+	return settings, nil
+}
+
+// formatMatchSettings returns a short description of match settings from a payload map
+func formatMatchSettings(settingsMap map[string]interface{}) string {
+	mode, _ := settingsMap["mode"].(string)
+	turnTimer, _ := settingsMap["turnTimerSeconds"].(float64)
+	startingMana, _ := settingsMap["startingMana"].(float64)
+
+	timerDesc := "no turn timer"
+	if turnTimer > 0 {
+		timerDesc = fmt.Sprintf("%ds turn timer", int(turnTimer))
+	}
+	description := fmt.Sprintf("%s, %s, %d starting mana", mode, timerDesc, int(startingMana))
+	if revealMana, _ := settingsMap["revealOpponentMana"].(bool); revealMana {
+		description += ", mana revealed"
+	}
+	if ruleSet, _ := settingsMap["ruleSet"].(string); ruleSet != "" {
+		description += fmt.Sprintf(", %s rules", ruleSet)
+	}
+	if levelCap, _ := settingsMap["levelCap"].(float64); levelCap > 0 {
+		description += fmt.Sprintf(", levels capped at %d", int(levelCap))
+	}
+	if handicap, _ := settingsMap["handicap"].(string); handicap != "" {
+		description += fmt.Sprintf(", %s handicap", strings.ToLower(handicap))
+	}
+	return description
+}
+
+// handleRoomUpdate handles a private room update from the server
+func handleRoomUpdate(payload interface{}) {
+	updateMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing room update")
+		return
+	}
+
+	roomCode, _ := updateMap["roomCode"].(string)
+	message, _ := updateMap["message"].(string)
+
+	fmt.Printf("\n--- Room: %s ---\n", message)
+	if roomCode != "" {
+		host, _ := updateMap["host"].(string)
+		guest, _ := updateMap["guest"].(string)
+		hostReady, _ := updateMap["hostReady"].(bool)
+		guestReady, _ := updateMap["guestReady"].(bool)
+		settingsMap, _ := updateMap["settings"].(map[string]interface{})
+
+		if guest == "" {
+			guest = "(waiting)"
+		}
+		fmt.Printf("  Code: %s | Settings: %s\n", roomCode, formatMatchSettings(settingsMap))
+		fmt.Printf("  Host: %s %s | Guest: %s %s\n", host, formatReadyStatus(hostReady), guest, formatReadyStatus(guestReady))
+		fmt.Println("  Type 'ready' to start once both players have joined, or 'leave' to leave.")
+	}
+	fmt.Print("> ")
+}
+
+// formatReadyStatus returns a string indicating if a room member is ready
+func formatReadyStatus(ready bool) string {
+	if ready {
+		return "(ready)"
+	}
+	return "(not ready)"
+}
+
+// handleChallengeNotification handles an incoming challenge from another player
+func handleChallengeNotification(payload interface{}) {
+	challengeMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing challenge notification")
+		return
+	}
+
+	challenger, _ := challengeMap["challengerUsername"].(string)
+	expiresIn, _ := challengeMap["expiresInSeconds"].(float64)
+	settingsMap, _ := challengeMap["settings"].(map[string]interface{})
+
+	fmt.Printf("\n⚔️  %s challenges you! (%s)\n", challenger, formatMatchSettings(settingsMap))
+	fmt.Printf("Type 'accept %s' or 'decline %s' within %d seconds.\n> ", challenger, challenger, int(expiresIn))
+}
+
+// handleChallengeResult handles the answer to a challenge this client sent
+func handleChallengeResult(payload interface{}) {
+	resultMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing challenge result")
+		return
+	}
+
+	message, _ := resultMap["message"].(string)
+	fmt.Printf("\n--- Challenge: %s ---\n> ", message)
+}
+
+// parsePlayerPresence converts a map to models.PlayerPresence
+func parsePlayerPresence(presenceMap map[string]interface{}) models.PlayerPresence {
+	presence := models.PlayerPresence{}
+	presence.Username, _ = presenceMap["username"].(string)
+	presence.Status, _ = presenceMap["status"].(string)
+	if level, ok := presenceMap["level"].(float64); ok {
+		presence.Level = int(level)
+	}
+	if rating, ok := presenceMap["rating"].(float64); ok {
+		presence.Rating = int(rating)
+	}
+	return presence
+}
+
+// handlePlayerList handles the list of online players sent by the server
+func handlePlayerList(payload interface{}) {
+	listMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing player list")
+		return
+	}
+
+	onlinePlayersMutex.Lock()
+	onlinePlayers = make(map[string]models.PlayerPresence)
+	if playersList, ok := listMap["players"].([]interface{}); ok {
+		for _, p := range playersList {
+			if presenceMap, ok := p.(map[string]interface{}); ok {
+				presence := parsePlayerPresence(presenceMap)
+				onlinePlayers[presence.Username] = presence
+			}
+		}
+	}
+	count := len(onlinePlayers)
+	onlinePlayersMutex.Unlock()
+
+	// The list sent right after login is only summarized
+	if !playerListRequested {
+		fmt.Printf("\n--- %d player(s) online. Type 'players' to see who. ---\n", count)
+		return
+	}
+	playerListRequested = false
+	displayPlayers()
+	fmt.Print("> ")
+}
+
+// handlePresenceUpdate handles a change in another player's lobby presence
+func handlePresenceUpdate(c *network.GameClient, payload interface{}) {
+	updateMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	presenceMap, ok := updateMap["player"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	presence := parsePlayerPresence(presenceMap)
+
+	onlinePlayersMutex.Lock()
+	_, wasOnline := onlinePlayers[presence.Username]
+	if presence.Status == models.PresenceOffline {
+		delete(onlinePlayers, presence.Username)
+	} else {
+		onlinePlayers[presence.Username] = presence
+	}
+	onlinePlayersMutex.Unlock()
+
+	// Only announce arrivals and departures, and only while in the lobby
+	if c.InGame || presence.Username == c.Username {
+		return
+	}
+	if !wasOnline && presence.Status != models.PresenceOffline {
+		fmt.Printf("\n--- %s (Level %d) is now online ---\n> ", presence.Username, presence.Level)
+	} else if wasOnline && presence.Status == models.PresenceOffline {
+		fmt.Printf("\n--- %s went offline ---\n> ", presence.Username)
+	}
+}
+
+// sortedOnlinePlayers returns the cached online players sorted by username
+func sortedOnlinePlayers() []models.PlayerPresence {
+	onlinePlayersMutex.Lock()
+	defer onlinePlayersMutex.Unlock()
+
+	players := make([]models.PlayerPresence, 0, len(onlinePlayers))
+	for _, presence := range onlinePlayers {
+		players = append(players, presence)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Username < players[j].Username
+	})
+	return players
+}
+
+// displayPlayers displays all online players with their status, level and rating
+func displayPlayers() {
+	players := sortedOnlinePlayers()
+
+	fmt.Println("\n==============================================")
+	fmt.Printf("👥 ONLINE PLAYERS (%d)\n", len(players))
+	fmt.Println("==============================================")
+	fmt.Printf("  %-16s %-10s %-6s %s\n", "Username", "Status", "Level", "Rating")
+	for _, presence := range players {
+		fmt.Printf("  %-16s %-10s %-6d %d\n", presence.Username, presence.Status, presence.Level, presence.Rating)
+	}
+	fmt.Println("==============================================")
+}
+
+// displayWho displays a compact list of who is online
+func displayWho() {
+	players := sortedOnlinePlayers()
+
+	names := make([]string, 0, len(players))
+	for _, presence := range players {
+		names = append(names, fmt.Sprintf("%s (%s)", presence.Username, strings.ToLower(presence.Status)))
+	}
+	fmt.Printf("Online (%d): %s\n", len(players), strings.Join(names, ", "))
+}
+
+// handleGameList handles the list of live games sent by the server
+func handleGameList(payload interface{}) {
+	listMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing game list")
+		return
+	}
+
+	gamesList, _ := listMap["games"].([]interface{})
+	fmt.Println("\n==============================================")
+	fmt.Printf("🎮 LIVE GAMES (%d)\n", len(gamesList))
+	fmt.Println("==============================================")
+	if len(gamesList) == 0 {
+		fmt.Println("  No games are being played right now.")
+	}
+	for _, g := range gamesList {
+		gameMap, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sessionID, _ := gameMap["sessionId"].(string)
+		turnNumber, _ := gameMap["turnNumber"].(float64)
+		spectatorCount, _ := gameMap["spectatorCount"].(float64)
+		settingsMap, _ := gameMap["settings"].(map[string]interface{})
+		fmt.Printf("  %s - %s, turn %d, %d watching\n", sessionID, formatMatchSettings(settingsMap), int(turnNumber), int(spectatorCount))
+	}
+	fmt.Println("==============================================")
+	fmt.Println("Type 'spectate <game_id> [delay_seconds]' to watch a game.")
+	fmt.Print("> ")
+}
+
+// handleSpectateStart handles the server confirming that we are spectating a game
+func handleSpectateStart(payload interface{}) {
+	startMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing spectate start")
+		return
+	}
+
+	spectatingGame, _ = startMap["sessionId"].(string)
+	playerA, _ := startMap["playerA"].(string)
+	playerB, _ := startMap["playerB"].(string)
+	delaySeconds, _ := startMap["delaySeconds"].(float64)
+
+	fmt.Println("\n==============================================")
+	fmt.Printf("👀 SPECTATING: %s vs %s\n", playerA, playerB)
+	if delaySeconds > 0 {
+		fmt.Printf("Updates are delayed by %d seconds.\n", int(delaySeconds))
+	}
+	fmt.Println("Type 'leave' to stop spectating.")
+	fmt.Println("==============================================")
+}
+
+// handleSpectateEnd handles the end of spectating a game
+func handleSpectateEnd(payload interface{}) {
+	spectatingGame = ""
+
+	endMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	message, _ := endMap["message"].(string)
+	fmt.Printf("\n--- Stopped spectating: %s ---\n> ", message)
+}
+
+// handleSpectatedStateUpdate displays a state update of the game being spectated
+func handleSpectatedStateUpdate(payload interface{}) {
+	gameStateMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing game state update")
+		return
+	}
+
+	if lal, ok := gameStateMap["lastActionLog"].(string); ok && lal != "" {
+		fmt.Printf("\n--- Server Log: %s ---\n", lal)
+	}
+
+	turnUser, _ := gameStateMap["currentTurn"].(string)
+	fmt.Println("\n==============================================")
+	fmt.Printf("           SPECTATING - %s's turn\n", turnUser)
+	fmt.Println("==============================================")
+	if clock := formatMatchClock(parseMatchClock(gameStateMap)); clock != "" {
+		fmt.Printf("Clock: %s\n", clock)
+	}
+	for _, key := range []string{"playerA", "playerB"} {
+		playerMap, ok := gameStateMap[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		player := parsePlayerState(playerMap, key)
+		fmt.Printf("%s (Level %d, %d cards in hand, mana %s):\n", player.Username, player.Level, player.HandSize, formatVisibleMana(&player))
+		for _, tower := range player.Towers {
+			fmt.Printf("    - %-14s: HP=%d/%d %s\n", tower.Name, tower.CurrentHP, tower.MaxHP, formatDestroyedStatus(tower.Destroyed))
+		}
+	}
+	fmt.Println("==============================================")
+	fmt.Print("> ")
+}
+
+// handleSpectatedGameOver displays the result of the game being spectated
+func handleSpectatedGameOver(payload interface{}) {
+	gameOverMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing game over notification")
+		return
+	}
+	winner, _ := gameOverMap["winnerUsername"].(string)
+	reason, _ := gameOverMap["reason"].(string)
+
+	fmt.Println("\n==============================================")
+	fmt.Println("GAME OVER!")
+	if winningTeam := parseStringList(gameOverMap["winningTeam"]); len(winningTeam) > 0 {
+		fmt.Printf("Winners: %s\n", strings.Join(winningTeam, " & "))
+	} else if winner != "" && winner != "DRAW" {
+		fmt.Printf("Winner: %s\n", winner)
+	} else if winner == "DRAW" {
+		fmt.Println("Result: It's a DRAW!")
+	}
+	fmt.Printf("Reason: %s\n", reason)
+	fmt.Println("==============================================")
+}
+
+// handleSpectatorCount handles a change in the number of spectators watching our game
+func handleSpectatorCount(payload interface{}) {
+	countMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if count, ok := countMap["count"].(float64); ok {
+		fmt.Printf("\n--- %d spectator(s) watching your game ---\n", int(count))
+	}
+}
+
+// sendChat sends the text following the "say" or "team" command to a chat scope
+func sendChat(c *network.GameClient, scope, input string) {
+	command, text, _ := strings.Cut(strings.TrimSpace(input), " ")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		fmt.Printf("Usage: %s <message>\n", command)
+		return
+	}
+	if err := c.SendChat(scope, text); err != nil {
+		fmt.Printf("Error sending chat message: %v\n", err)
+	}
+}
+
+// muteCommand handles the "mute <username>" and "unmute <username>" commands
+func muteCommand(c *network.GameClient, parts []string) {
+	if len(parts) != 2 {
+		fmt.Printf("Usage: %s <username>\n", parts[0])
+		return
+	}
+	if err := c.MutePlayer(parts[1], parts[0] == "mute"); err != nil {
+		fmt.Printf("Error sending mute request: %v\n", err)
+	}
+}
+
+// displayEmotes lists the emotes usable during a match
+func displayEmotes() {
+	names := make([]string, 0, len(shared.Emotes))
+	for name := range shared.Emotes {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	fmt.Printf("Usage: emote <name>. Available emotes: %s\n", strings.Join(names, ", "))
+}
+
+// redisplayPrompt prints the prompt matching the client's current state again
+// after a message was printed in the middle of it
+func redisplayPrompt(c *network.GameClient) {
+	if !c.InGame {
+		fmt.Print("> ")
+	} else if c.MyTurn {
+		fmt.Print("Your turn - Enter command (d <troop_name> [target], status, help, quit): ")
+	} else {
+		fmt.Print("(Waiting for opponent... Type status, help, or quit): ")
+	}
+}
+
+// handleChatMessage displays a chat message or emote inline
+func handleChatMessage(c *network.GameClient, payload interface{}) {
+	chatMap, ok := payload.(map[string]interface{})
+	if !ok {
+		fmt.Println("Error parsing chat message")
+		return
+	}
+
+	scope, _ := chatMap["scope"].(string)
+	sender, _ := chatMap["sender"].(string)
+	text, _ := chatMap["text"].(string)
+	if emote, _ := chatMap["emote"].(string); emote != "" {
+		if emoteText, ok := shared.Emotes[emote]; ok {
+			text = emoteText
+		} else {
+			text = emote
+		}
+	}
+
+	fmt.Printf("\n💬 [%s] %s: %s\n", strings.ToLower(scope), sender, text)
+	redisplayPrompt(c)
+}
+
+// handleSystemNotice displays an informational notice from the server
+func handleSystemNotice(c *network.GameClient, payload interface{}) {
+	noticeMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	message, _ := noticeMap["message"].(string)
+	fmt.Printf("\nℹ️  %s\n", message)
+	redisplayPrompt(c)
+}
+
+// handleServerShutdown tells the player the server is going away
+func handleServerShutdown(payload interface{}) {
+	shutdownMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	reason, _ := shutdownMap["reason"].(string)
+	fmt.Printf("\n⚠️ Server shutting down: %s\n", reason)
+}
+
+// handleDrawOffered handles the opponent offering a draw
+func handleDrawOffered(c *network.GameClient, payload interface{}) {
+	offerMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	fromUsername, _ := offerMap["fromUsername"].(string)
+	fmt.Printf("\n🤝 %s offers a draw. Type 'draw accept' or 'draw decline'.\n", fromUsername)
+	redisplayPrompt(c)
+}
+
+// handleRematchOffered handles the last opponent asking for a rematch
+func handleRematchOffered(c *network.GameClient, payload interface{}) {
+	offerMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+	fromUsername, _ := offerMap["fromUsername"].(string)
+	swapSides, _ := offerMap["swapSides"].(bool)
+	expiresIn, _ := offerMap["expiresInSeconds"].(float64)
+
+	sides := ""
+	if swapSides {
+		sides = " with sides swapped"
+	}
+	fmt.Printf("\n🔁 %s wants a rematch%s. Type 'rematch' within %d seconds to play again.\n", fromUsername, sides, int(expiresIn))
+	redisplayPrompt(c)
+}
+
+// parseMatchClock reads the match clock from a game state update: the current phase and when it runs out.
+// The phase is empty if the match has no clock.
+func parseMatchClock(gameStateMap map[string]interface{}) (string, time.Time) {
+	phase, _ := gameStateMap["phase"].(string)
+	if phase == "" {
+		return "", time.Time{}
+	}
+	secondsLeft, _ := gameStateMap["phaseSecondsLeft"].(float64)
+	return phase, time.Now().Add(time.Duration(secondsLeft) * time.Second)
+}
+
+// formatMatchClock describes the match clock, e.g. "2:30 left" or "OVERTIME - first tower destroyed wins, 0:45 left".
+// It returns an empty string if the match has no clock.
+func formatMatchClock(phase string, endsAt time.Time) string {
+	if phase == "" {
+		return ""
+	}
+	left := max(time.Until(endsAt).Round(time.Second), 0)
+	clock := fmt.Sprintf("%d:%02d left", int(left.Minutes()), int(left.Seconds())%60)
+	if phase == shared.PhaseOvertime {
+		return "OVERTIME - first tower destroyed wins, " + clock
+	}
+	return clock
+}
+
 // displayGameStatus displays the current game status in a more readable format
 func displayGameStatus(c *network.GameClient, me *models.PlayerState, opp *models.PlayerState, turnUser string, oppUser string) {
 	fmt.Println("\n==============================================")
@@ -814,6 +1942,12 @@ func displayGameStatus(c *network.GameClient, me *models.PlayerState, opp *model
 	if gameMode != "" { // Display game mode if known
 		fmt.Printf("Game Mode: %s\n", gameMode)
 	}
+	if clock := formatMatchClock(matchPhase, phaseEndsAt); clock != "" {
+		fmt.Printf("Clock: %s\n", clock)
+	}
+	if c.Spectators > 0 {
+		fmt.Printf("Spectators: %d\n", c.Spectators)
+	}
 	if lastActionLog != "" {
 		fmt.Printf("Last Action: %s\n", lastActionLog)
 	}
@@ -823,11 +1957,21 @@ func displayGameStatus(c *network.GameClient, me *models.PlayerState, opp *model
 	fmt.Printf("YOUR INFO (%s):\n", me.Username)
 	fmt.Printf("  Level: %d\n", me.Level)
 	fmt.Printf("  EXP: %d / %d\n", me.CurrentEXP, me.RequiredEXPForNextLevel)
-	fmt.Printf("  Mana: %d / %d\n", me.CurrentMana, me.MaxMana)
+	fmt.Printf("  Mana: %s\n", formatVisibleMana(me))
+	fmt.Print(formatSpellEffects(me))
 	fmt.Println("  Towers:")
-	fmt.Printf("    - King Tower   (ID: %s): HP=%d/%d %s\n", me.KingTower.ID, me.KingTower.CurrentHP, me.KingTower.MaxHP, formatDestroyedStatus(me.KingTower.Destroyed))
-	fmt.Printf("    - Guard Tower 1 (ID: %s): HP=%d/%d %s\n", me.GuardTower1.ID, me.GuardTower1.CurrentHP, me.GuardTower1.MaxHP, formatDestroyedStatus(me.GuardTower1.Destroyed))
-	fmt.Printf("    - Guard Tower 2 (ID: %s): HP=%d/%d %s\n", me.GuardTower2.ID, me.GuardTower2.CurrentHP, me.GuardTower2.MaxHP, formatDestroyedStatus(me.GuardTower2.Destroyed))
+	printTowers(me.Towers)
+	printBuildings(me.Buildings)
+	if teammateUsername != "" {
+		fmt.Println("----------------------------------------------")
+		fmt.Printf("TEAMMATE INFO (%s):\n", teammateUsername)
+		fmt.Printf("  Level: %d\n", teammateState.Level)
+		fmt.Printf("  Mana: %s\n", formatVisibleMana(&teammateState))
+		fmt.Println("  Hand:")
+		for _, troop := range teammateState.Troops {
+			fmt.Printf("    - %s (ATK:%d DEF:%d HP:%d Mana:%d)\n", troop.Name, troop.Attack, troop.Defense, troop.HP, troop.ManaCost)
+		}
+	}
 	// fmt.Println("  Hand:") // Hand info will be shown by displayPlayerHandAndTargetInfo when it's player's turn
 	// if len(me.Troops) == 0 {
 	// 	fmt.Println("    Your hand is empty!")
@@ -845,15 +1989,53 @@ func displayGameStatus(c *network.GameClient, me *models.PlayerState, opp *model
 	// Display Opponent Info (opp)
 	fmt.Printf("OPPONENT INFO (%s):\n", oppUser) // Use oppUser which is opponentUsername
 	fmt.Printf("  Level: %d\n", opp.Level)       // Display opponent's level
-	// Opponent's EXP and Mana are not typically shown, but towers are.
+	// Opponent's EXP is not shown; the server only sends their hand size, and mana if the match reveals it.
+	fmt.Printf("  Cards in hand: %d\n", opp.HandSize)
+	fmt.Printf("  Mana: %s\n", formatVisibleMana(opp))
+	fmt.Print(formatSpellEffects(opp))
 	fmt.Println("  Towers:")
-	fmt.Printf("    - King Tower   (ID: %s): HP=%d/%d %s\n", opp.KingTower.ID, opp.KingTower.CurrentHP, opp.KingTower.MaxHP, formatDestroyedStatus(opp.KingTower.Destroyed))
-	fmt.Printf("    - Guard Tower 1 (ID: %s): HP=%d/%d %s\n", opp.GuardTower1.ID, opp.GuardTower1.CurrentHP, opp.GuardTower1.MaxHP, formatDestroyedStatus(opp.GuardTower1.Destroyed))
-	fmt.Printf("    - Guard Tower 2 (ID: %s): HP=%d/%d %s\n", opp.GuardTower2.ID, opp.GuardTower2.CurrentHP, opp.GuardTower2.MaxHP, formatDestroyedStatus(opp.GuardTower2.Destroyed))
+	printTowers(opp.Towers)
+	printBuildings(opp.Buildings)
 	// We don't usually show opponent's hand
 	fmt.Println("==============================================")
 }
 
+// formatSpellEffects returns the status line for the rage on a player, if any
+func formatSpellEffects(player *models.PlayerState) string {
+	var effects string
+	if player.RagePercent > 0 {
+		effects += fmt.Sprintf("  😡 Rage: next troop +%d%% ATK\n", player.RagePercent)
+	}
+	return effects
+}
+
+// printTowers lists a player's towers with their HP
+func printTowers(towers []models.TowerState) {
+	for _, tower := range towers {
+		fmt.Printf("    - [%s] %-14s (ID: %s): HP=%d/%d %s\n", towerShortName(tower), tower.Name, tower.ID, tower.CurrentHP, tower.MaxHP, formatDestroyedStatus(tower.Destroyed))
+	}
+}
+
+// printBuildings lists the buildings standing on a player's side, if any
+func printBuildings(buildings []models.BuildingState) {
+	if len(buildings) == 0 {
+		return
+	}
+	fmt.Println("  Buildings:")
+	for _, building := range buildings {
+		protects := building.Protects
+		if protects == "" {
+			protects = "every tower"
+		}
+		frozen := ""
+		if building.FrozenTurns > 0 {
+			frozen = fmt.Sprintf(", ❄️ frozen for %d attack(s)", building.FrozenTurns)
+		}
+		fmt.Printf("    - %-14s (ID: %s): HP=%d/%d ATK:%d DEF:%d [%d turns left, guards %s%s]\n",
+			building.Name, building.ID, building.CurrentHP, building.MaxHP, building.Attack, building.Defense, building.TurnsLeft, protects, frozen)
+	}
+}
+
 // formatDestroyedStatus returns a string indicating if a tower is destroyed
 func formatDestroyedStatus(destroyed bool) string {
 	if destroyed {
@@ -868,10 +2050,15 @@ func displayHelp() {
 	fmt.Println("📋 GAME COMMANDS 📋")
 	fmt.Println("==============================================")
 	fmt.Println("Game Actions:")
-	fmt.Println("  d <troop_name> - Deploy a troop to attack")
-	fmt.Println("    - Auto-targets enemy towers in sequence")
-	fmt.Println("    - Example: d Knight (deploys Knight to attack)")
-	fmt.Println("    - Example: d Queen (deploys Queen to heal your lowest HP tower)")
+	fmt.Println("  d <card_name> [target] - Deploy a troop to attack, or cast a spell")
+	fmt.Println("    - target is a tower short name such as g1, g2 or k (shown in brackets in the status)")
+	fmt.Println("    - Without a target, attacks the next open guard tower, then the King (or the troop's preferred target)")
+	fmt.Println("    - Example: d Knight g2 (deploys Knight to attack Guard Tower 2)")
+	fmt.Println("    - Example: d Queen (casts Queen to heal your lowest HP tower)")
+	fmt.Println("    - Example: d Fireball g1 (casts Fireball at Guard Tower 1; without a target it picks one)")
+	fmt.Println("    - Example: d Freeze b_Cannon_3 (an enemy building skips its next attack; towers cannot be frozen)")
+	fmt.Println("    - Example: d Cannon (places a building on your side; it intercepts attacks on the towers it guards)")
+	fmt.Println("    - Example: d Pawn b_Cannon_3 (attacks an enemy building by its ID)")
 	fmt.Println("  skip           - Skip your turn and gain bonus mana")
 	fmt.Println("")
 	fmt.Println("Information Commands:")
@@ -883,16 +2070,75 @@ func displayHelp() {
 	fmt.Println("==============================================")
 	fmt.Println("Commands available IN GAME:")
 	fmt.Println("----------------------------------------------")
-	fmt.Println("  d <troop_name> - Deploy a troop (auto-targets enemy towers in sequence)")
-	fmt.Println("                   Example: d Pawn")
-	fmt.Println("                   (Queen will automatically heal your lowest HP tower)")
+	fmt.Println("  d <card_name> [target] - Deploy a troop at a tower (g1, g2, k) or the next open one")
+	fmt.Println("                   Example: d Pawn g1")
+	fmt.Println("                   (Queen and Rage need no target; Freeze takes an enemy building, never a tower)")
 	fmt.Println("  skip           - Skip your turn and gain bonus mana")
 	fmt.Println("  status         - Display current game status")
+	fmt.Println("  surrender      - Concede the game (your opponent wins)")
+	fmt.Println("  draw           - Offer a draw; 'draw accept' / 'draw decline' to answer an offer")
+	fmt.Println("  say <message>  - Chat with your opponent (works on either turn)")
+	fmt.Println("  team <message> - Chat with your teammate only (2v2 matches)")
+	fmt.Println("  emote <name>   - Send a quick emote (type 'emotes' to list them)")
+	fmt.Println("  mute <username> / unmute <username> - Hide or show a player's chat")
 	fmt.Println("  help           - Display this help information")
 	fmt.Println("  quit           - Forfeit the current game and exit")
 	fmt.Println("==============================================")
 }
 
+// displayLobbyHelp displays the lobby help information
+func displayLobbyHelp() {
+	fmt.Println("\n==============================================")
+	fmt.Println("📋 LOBBY COMMANDS 📋")
+	fmt.Println("==============================================")
+	fmt.Println("  queue [mode...] - Join the matchmaking queue")
+	fmt.Println("                    Modes: simple, enhanced, any (default: any)")
+	fmt.Println("                    Example: queue enhanced simple")
+	fmt.Println("                    Add '2v2' to queue for team matches (turns rotate across all four players)")
+	fmt.Println("  leave           - Leave the matchmaking queue or your room")
+	fmt.Println("")
+	fmt.Println("Private Matches:")
+	fmt.Println("  create [mode] [turn_timer] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>] - Create a private room")
+	fmt.Println("                    Example: create enhanced 30 10")
+	fmt.Println("                    Add 'reveal' to let both players see each other's mana")
+	fmt.Println("                    Add 'rules=<name>' to play a custom rule set of the server")
+	fmt.Println("                    Add 'cap=<level>' to cap both players' levels (tournament standard)")
+	fmt.Println("                    Add 'handicap=mana' or 'handicap=tower_hp' to give the lower-level player a bonus")
+	fmt.Println("  join <code>     - Join a private room using its code")
+	fmt.Println("  ready / unready - Mark yourself ready; the match starts when both are ready")
+	fmt.Println("  challenge <username> [mode] [turn_timer] [starting_mana] [reveal] [rules=<name>] [cap=<level>] [handicap=<kind>]")
+	fmt.Println("                  - Challenge an online player directly")
+	fmt.Println("  accept <username> / decline <username>")
+	fmt.Println("                  - Answer a challenge")
+	fmt.Println("  rematch [swap]  - After a game, play the same opponent again")
+	fmt.Println("                    ('swap' lets the player who moved second go first)")
+	fmt.Println("")
+	fmt.Println("Players:")
+	fmt.Println("  players         - List online players with status, level and rating")
+	fmt.Println("  who             - Show who is online")
+	fmt.Println("")
+	fmt.Println("Cards:")
+	fmt.Println("  cards           - Show your card collection, card levels and deck")
+	fmt.Printf("  deck <card> ... - Choose the %d to %d unlocked cards you play with\n", shared.MinDeckSize, shared.MaxDeckSize)
+	fmt.Println("                    ('deck clear' plays with every unlocked card)")
+	fmt.Println("")
+	fmt.Println("Spectating:")
+	fmt.Println("  games           - List live games with their IDs")
+	fmt.Println("  spectate <game_id> [delay_seconds]")
+	fmt.Println("                  - Watch a live game, optionally delayed")
+	fmt.Println("  leave           - Stop spectating")
+	fmt.Println("")
+	fmt.Println("Chat:")
+	fmt.Println("  say <message>   - Chat with the lobby, or with your room while in one")
+	fmt.Println("  mute <username> / unmute <username>")
+	fmt.Println("                  - Hide or show a player's chat")
+	fmt.Println("")
+	fmt.Println("  help            - Show this help information")
+	fmt.Println("  quit            - Exit the game")
+	fmt.Println("==============================================")
+	fmt.Println()
+}
+
 // displayAuthPrompt displays the authentication options to the user
 func displayAuthPrompt() {
 	fmt.Println("\n==== Authentication Required ====")
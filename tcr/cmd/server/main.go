@@ -6,85 +6,193 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"tcr/internal/config"
 	"tcr/internal/game"
 	"tcr/internal/network"
+	"tcr/internal/shared"
 	"tcr/internal/storage"
+	"time"
 )
 
+// defaultConfigFile is read if it exists and no other config file is given
+const defaultConfigFile = "server.json"
+
 func main() {
-	// Define command line flags
+	// Define command line flags. Flags that are set override the config file and environment.
+	configFile := flag.String("config", "", "Path to the server config file (default "+defaultConfigFile+" if present, or $TCR_CONFIG)")
 	addr := flag.String("addr", ":8080", "Server address to listen on (host:port)")
 	mode := flag.String("mode", "online", "Server mode (online or offline)")
 	configsDir := flag.String("configs", "configs", "Path to config files directory")
 	dataDir := flag.String("data", "data", "Path to data files directory")
+	drainTimeout := flag.Duration("drain-timeout", shared.ShutdownDrainTimeoutSeconds*time.Second, "How long a graceful shutdown waits for matches and messages to be flushed")
 	flag.Parse()
 
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Server.ListenAddr = *addr
+		case "configs":
+			cfg.Storage.ConfigsDir = *configsDir
+		case "data":
+			cfg.Storage.DataDir = *dataDir
+		case "drain-timeout":
+			cfg.Timeouts.ShutdownDrainSeconds = int(drainTimeout.Seconds())
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
+	}
+	if err := setUpLogging(cfg.Logging); err != nil {
+		log.Fatalf("Error setting up logging: %v", err)
+	}
+
 	fmt.Println("TCR Server - Starting...")
-	fmt.Printf("Address: %s\n", *addr)
+	fmt.Printf("Address: %s\n", cfg.Server.ListenAddr)
 	fmt.Printf("Mode: %s\n", *mode)
-	fmt.Printf("Configs directory: %s\n", *configsDir)
-	fmt.Printf("Data directory: %s\n", *dataDir)
+	fmt.Printf("Configs directory: %s\n", cfg.Storage.ConfigsDir)
+	fmt.Printf("Data directory: %s\n", cfg.Storage.DataDir)
 
 	// Create data directories if they don't exist
-	if err := os.MkdirAll(*dataDir, 0755); err != nil {
+	if err := os.MkdirAll(cfg.Storage.DataDir, 0755); err != nil {
 		log.Fatalf("Failed to create data directory: %v", err)
 	}
 
-	usersDir := filepath.Join(*dataDir, "users")
+	usersDir := filepath.Join(cfg.Storage.DataDir, "users")
 	if err := os.MkdirAll(usersDir, 0755); err != nil {
 		log.Fatalf("Failed to create users directory: %v", err)
 	}
 
-	playersDir := filepath.Join(*dataDir, "players")
+	playersDir := filepath.Join(cfg.Storage.DataDir, "players")
 	if err := os.MkdirAll(playersDir, 0755); err != nil {
 		log.Fatalf("Failed to create players directory: %v", err)
 	}
 
 	// Create JSON handler
-	jsonHandler := storage.NewJSONHandler(*configsDir, *dataDir)
+	jsonHandler := storage.NewJSONHandler(cfg.Storage.ConfigsDir, cfg.Storage.DataDir)
 
 	// Run in offline mode if specified
 	if *mode == "offline" {
 		fmt.Println("Running in offline mode...")
-		testSimpleTCR()
+		testSimpleTCR(cfg, jsonHandler)
 		return
 	}
 
 	// Create and start server
-	server := network.NewServer(*addr, jsonHandler)
-	err := server.Start()
-	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
-	}
+	server := network.NewServer(cfg, jsonHandler)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+
+	// SIGHUP reloads the troop and tower specs for new matches
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	go func() {
+		for range reloads {
+			log.Printf("Received SIGHUP, reloading specs")
+			specs, err := server.ReloadSpecs()
+			if err != nil {
+				log.Printf("Error reloading specs, keeping the current specs: %v", err)
+				continue
+			}
+			log.Printf("New matches use specs v%d", specs.Version)
+		}
+	}()
 
 	// Handle Ctrl+C to gracefully shutdown server
-	// In a real implementation, you'd add signal handling here
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 	fmt.Println("Server running. Press Ctrl+C to stop.")
-	select {}
+
+	select {
+	case err := <-serverErr:
+		log.Fatalf("Error starting server: %v", err)
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down (press Ctrl+C again to force)...", sig)
+	}
+
+	// A second signal skips the drain
+	go func() {
+		<-signals
+		log.Printf("Forced shutdown")
+		os.Exit(1)
+	}()
+
+	if err := server.Shutdown("The server is shutting down.", config.Seconds(cfg.Timeouts.ShutdownDrainSeconds)); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+	}
+	fmt.Println("Server stopped.")
 }
 
-// testSimpleTCR tests the Simple TCR game logic in a console environment
-func testSimpleTCR() {
-	// Initialize storage handler for loading configs
-	jsonHandler := storage.NewJSONHandler("configs", "data/players")
+// loadConfig resolves the server config from the built-in defaults, the config file and the environment.
+// Without an explicit file, server.json is used if it exists.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		path = os.Getenv("TCR_CONFIG")
+	}
+
+	var cfg *config.Config
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			path = defaultConfigFile
+		}
+	}
+	if path != "" {
+		var err error
+		cfg, err = config.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Config file: %s\n", path)
+	} else {
+		cfg = config.Default()
+		fmt.Println("Config file: none (using defaults)")
+	}
 
-	// Load troop and tower specs
-	troopSpecs, err := jsonHandler.LoadTroopSpecs()
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// setUpLogging points the standard logger at the configured file
+func setUpLogging(logging config.LoggingConfig) error {
+	if logging.Microseconds {
+		log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+	}
+	if logging.File == "" {
+		return nil
+	}
+
+	logFile, err := os.OpenFile(logging.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		fmt.Printf("Error loading troop specs: %v\n", err)
-		return
+		return err
 	}
+	log.SetOutput(logFile)
+	return nil
+}
 
-	towerSpecs, err := jsonHandler.LoadTowerSpecs()
+// testSimpleTCR tests the Simple TCR game logic in a console environment, with the rules and
+// storage of the server config
+func testSimpleTCR(cfg *config.Config, jsonHandler *storage.JSONHandler) {
+	// Load and validate troop and tower specs
+	specs, err := jsonHandler.LoadSpecSet(1)
 	if err != nil {
-		fmt.Printf("Error loading tower specs: %v\n", err)
+		fmt.Printf("Error loading specs:\n%v\n", err)
 		return
 	}
 
 	// Create a new game session
-	gameSession := game.NewGameSession("PlayerA", "PlayerB", troopSpecs, towerSpecs, jsonHandler)
+	settings := game.DefaultMatchSettings(cfg, shared.GameModeSimple)
+	gameSession := game.NewGameSessionWithSettings("PlayerA", "PlayerB", specs.Troops, specs.Towers, jsonHandler, settings, game.NewRuleSet(cfg, settings))
 
 	// Print initial game state
 	fmt.Println("\n=== Initial Game State ===")
@@ -0,0 +1,99 @@
+// Command tcr-config checks TCR configuration files without starting the server.
+//
+//	tcr-config lint [-config server.json] [-configs dir]
+//
+// lint validates the server config file (if any) and the troop and tower specs, printing
+// every problem with its file, entry and field. It exits with status 1 if problems were found.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"tcr/internal/config"
+	"tcr/internal/storage"
+)
+
+// defaultConfigFile is linted if it exists and no other config file is given
+const defaultConfigFile = "server.json"
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "lint" {
+		fmt.Fprintln(os.Stderr, "Usage: tcr-config lint [-config server.json] [-configs dir]")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	configFile := flags.String("config", "", "Path to the server config file (default "+defaultConfigFile+" if present)")
+	configsDir := flags.String("configs", "", "Directory with troops.json and towers.json (default storage.configsDir of the server config)")
+	flags.Parse(os.Args[2:])
+
+	os.Exit(lint(*configFile, *configsDir))
+}
+
+// lint checks the server config and the spec files and returns the exit status
+func lint(configFile, configsDir string) int {
+	if configFile == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			configFile = defaultConfigFile
+		}
+	}
+
+	problems := 0
+	cfg := config.Default()
+	if configFile != "" {
+		loaded, err := config.Load(configFile)
+		if err == nil {
+			cfg = loaded
+			err = cfg.Validate()
+		}
+		problems += report(configFile, err)
+	}
+
+	if configsDir == "" {
+		configsDir = cfg.Storage.ConfigsDir
+	}
+	jsonHandler := storage.NewJSONHandler(configsDir, "")
+	_, err := jsonHandler.LoadSpecSet(0)
+	problems += report(configsDir, err)
+
+	if problems > 0 {
+		fmt.Printf("%d problem(s) found\n", problems)
+		return 1
+	}
+	return 0
+}
+
+// report prints each problem in err and returns how many there were
+func report(checked string, err error) int {
+	problems := flatten(err)
+	if len(problems) == 0 {
+		fmt.Printf("%s: OK\n", checked)
+		return 0
+	}
+
+	fmt.Printf("%s:\n", checked)
+	for _, problem := range problems {
+		fmt.Printf("  %v\n", problem)
+	}
+	return len(problems)
+}
+
+// flatten lists the individual errors of an error built with errors.Join
+func flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		return []error{err}
+	}
+
+	var problems []error
+	for _, inner := range joined.Unwrap() {
+		problems = append(problems, flatten(inner)...)
+	}
+	return problems
+}
@@ -0,0 +1,372 @@
+// Package config holds the server configuration: listen address, game rules per mode,
+// timeouts, matchmaking parameters, storage and logging.
+//
+// Settings are resolved in this order, later sources overriding earlier ones:
+// built-in defaults (the constants in internal/shared), the JSON config file,
+// environment variables and finally command line flags.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"tcr/internal/shared"
+	"time"
+)
+
+// Config is the complete server configuration
+type Config struct {
+	Server      ServerConfig      `json:"server"`
+	Rules       RulesConfig       `json:"rules"`
+	Timeouts    TimeoutConfig     `json:"timeouts"`
+	Matchmaking MatchmakingConfig `json:"matchmaking"`
+	Storage     StorageConfig     `json:"storage"`
+	Logging     LoggingConfig     `json:"logging"`
+}
+
+// ServerConfig holds network settings
+type ServerConfig struct {
+	ListenAddr string `json:"listenAddr"` // Address to listen on (host:port)
+}
+
+// RulesConfig holds the game rules of each game mode, and custom rule sets matches can choose instead
+type RulesConfig struct {
+	Simple   GameRules            `json:"simple"`
+	Enhanced GameRules            `json:"enhanced"`
+	Custom   map[string]GameRules `json:"custom"` // Custom rule sets by name; settings left out are taken from simple
+}
+
+// UnmarshalJSON decodes the rules of each mode on top of the rules already set, and every custom
+// rule set on top of the Simple TCR rules, so settings left out keep their defaults.
+// Unknown settings are rejected.
+func (r *RulesConfig) UnmarshalJSON(data []byte) error {
+	var sections struct {
+		Simple   json.RawMessage            `json:"simple"`
+		Enhanced json.RawMessage            `json:"enhanced"`
+		Custom   map[string]json.RawMessage `json:"custom"`
+	}
+	if err := decodeStrict(data, &sections); err != nil {
+		return err
+	}
+
+	if sections.Simple != nil {
+		if err := decodeStrict(sections.Simple, &r.Simple); err != nil {
+			return fmt.Errorf("rules.simple: %v", err)
+		}
+	}
+	if sections.Enhanced != nil {
+		if err := decodeStrict(sections.Enhanced, &r.Enhanced); err != nil {
+			return fmt.Errorf("rules.enhanced: %v", err)
+		}
+	}
+	for name, raw := range sections.Custom {
+		rules := r.Simple
+		if err := decodeStrict(raw, &rules); err != nil {
+			return fmt.Errorf("rules.custom.%s: %v", name, err)
+		}
+		if r.Custom == nil {
+			r.Custom = make(map[string]GameRules)
+		}
+		r.Custom[name] = rules
+	}
+	return nil
+}
+
+// GameRules are the tunable rules a match is played with
+type GameRules struct {
+	InitialMana          int     `json:"initialMana"`          // Default starting mana
+	ManaRegenRate        int     `json:"manaRegenRate"`        // Mana gained at the start of each turn
+	MaxMana              int     `json:"maxMana"`              // Maximum mana a player can hold
+	WinEXPReward         int     `json:"winExpReward"`         // EXP for winning a match
+	DrawEXPReward        int     `json:"drawExpReward"`        // EXP for each player on a draw
+	QueenHealAmount      int     `json:"queenHealAmount"`      // HP restored by the Queen's heal
+	CritDamageMultiplier float64 `json:"critDamageMultiplier"` // Damage multiplier of a critical hit
+	TroopCritChance      float64 `json:"troopCritChance"`      // Chance in percent that a troop attack is critical
+	Targeting            string  `json:"targeting"`            // Which towers can be attacked: CLASSIC or LANES
+	Damage               string  `json:"damage"`               // Damage formula: CRITICAL or FLAT
+	Mana                 string  `json:"mana"`                 // Mana economy: POOL or FREE
+	Turns                string  `json:"turns"`                // Turn structure: BONUS_ATTACK or ALTERNATE
+	WinCondition         string  `json:"winCondition"`         // When destroying a tower wins: KING or ALL_TOWERS
+	EXP                  string  `json:"exp"`                  // What awards EXP: MATCH_AND_TOWERS or MATCH_ONLY
+
+	// Match clock. When time runs out, whoever destroyed more towers wins; a tie goes to
+	// sudden-death overtime if it is enabled, otherwise it is a draw.
+	MatchSeconds                int     `json:"matchSeconds"`                // Length of regular time; 0 plays without a clock
+	OvertimeSeconds             int     `json:"overtimeSeconds"`             // Length of overtime; 0 turns overtime off
+	OvertimeManaRegenMultiplier float64 `json:"overtimeManaRegenMultiplier"` // manaRegenRate is multiplied by this during overtime
+
+	// Size of the handicap bonus of matches that use one, per level the lower-level player is behind
+	HandicapManaPerLevel           int `json:"handicapManaPerLevel"`           // Bonus starting mana (MANA handicap)
+	HandicapTowerHPPercentPerLevel int `json:"handicapTowerHpPercentPerLevel"` // Bonus tower HP in percent (TOWER_HP handicap)
+}
+
+// TimeoutConfig holds timeouts and time limits, in seconds
+type TimeoutConfig struct {
+	ChallengeSeconds     int `json:"challengeSeconds"`     // How long a direct challenge stays open
+	RematchSeconds       int `json:"rematchSeconds"`       // How long players can ask for a rematch after a game
+	MinTurnTimerSeconds  int `json:"minTurnTimerSeconds"`  // Shortest allowed turn timer (0 disables the timer)
+	MaxTurnTimerSeconds  int `json:"maxTurnTimerSeconds"`  // Longest allowed turn timer
+	ClientWriteSeconds   int `json:"clientWriteSeconds"`   // Longest a single write to a client may take
+	ShutdownDrainSeconds int `json:"shutdownDrainSeconds"` // Longest a graceful shutdown waits for matches and messages
+}
+
+// MatchmakingConfig holds the rated matchmaking queue parameters
+type MatchmakingConfig struct {
+	BaseRatingGap      int `json:"baseRatingGap"`      // Rating gap accepted as soon as a player joins the queue
+	GapWidenPerSecond  int `json:"gapWidenPerSecond"`  // Extra rating gap accepted for every second spent waiting
+	MaxRatingGap       int `json:"maxRatingGap"`       // Upper bound for the accepted rating gap
+	TickSeconds        int `json:"tickSeconds"`        // How often the queue is re-evaluated
+	StatusEverySeconds int `json:"statusEverySeconds"` // How often waiting players receive a queue status update
+	DefaultWaitSeconds int `json:"defaultWaitSeconds"` // Wait estimate used before any match has been made
+	WaitSampleSize     int `json:"waitSampleSize"`     // Number of recent waits used for the wait estimate
+}
+
+// StorageConfig holds where game specs and player data are kept
+type StorageConfig struct {
+	Backend    string `json:"backend"`    // Storage backend; only "json" is supported
+	ConfigsDir string `json:"configsDir"` // Directory with troops.json and towers.json
+	DataDir    string `json:"dataDir"`    // Directory for user and player data
+
+	// How often configsDir is checked for changed troop and tower specs; 0 turns watching off
+	// (specs can still be reloaded with SIGHUP)
+	WatchSeconds int `json:"watchSeconds"`
+}
+
+// LoggingConfig holds logging settings
+type LoggingConfig struct {
+	File         string `json:"file"`         // Log file to append to; empty logs to stderr
+	Microseconds bool   `json:"microseconds"` // Include microseconds in log timestamps
+}
+
+// StorageBackendJSON stores everything as JSON files on disk
+const StorageBackendJSON = "json"
+
+// Default returns the built-in configuration
+func Default() *Config {
+	rules := GameRules{
+		InitialMana:          shared.InitialMana,
+		ManaRegenRate:        shared.ManaRegenRate,
+		MaxMana:              shared.MaxMana,
+		WinEXPReward:         shared.WinEXPReward,
+		DrawEXPReward:        shared.DrawEXPReward,
+		QueenHealAmount:      shared.QueenHealAmount,
+		CritDamageMultiplier: shared.CritDamageMultiplier,
+		TroopCritChance:      shared.DefaultTroopCritChance,
+		Targeting:            shared.TargetingClassic,
+		Damage:               shared.DamageCritical,
+		Mana:                 shared.ManaPool,
+		Turns:                shared.TurnsBonusAttack,
+		WinCondition:         shared.WinKingTower,
+		EXP:                  shared.EXPMatchAndTowers,
+
+		OvertimeManaRegenMultiplier: shared.OvertimeManaRegenMultiplier,
+
+		HandicapManaPerLevel:           shared.HandicapManaPerLevel,
+		HandicapTowerHPPercentPerLevel: shared.HandicapTowerHPPercentPerLevel,
+	}
+
+	// Enhanced TCR matches are played against the clock; Simple TCR has no mana and no critical hits
+	enhanced := rules
+	enhanced.MatchSeconds = shared.GameDurationSeconds
+	simple := rules
+	simple.Damage = shared.DamageFlat
+	simple.Mana = shared.ManaFree
+
+	return &Config{
+		Server: ServerConfig{
+			ListenAddr: ":8080",
+		},
+		Rules: RulesConfig{
+			Simple:   simple,
+			Enhanced: enhanced,
+		},
+		Timeouts: TimeoutConfig{
+			ChallengeSeconds:     shared.ChallengeTimeoutSeconds,
+			RematchSeconds:       shared.RematchTimeoutSeconds,
+			MinTurnTimerSeconds:  shared.MinTurnTimerSeconds,
+			MaxTurnTimerSeconds:  shared.MaxTurnTimerSeconds,
+			ClientWriteSeconds:   shared.ClientWriteTimeoutSeconds,
+			ShutdownDrainSeconds: shared.ShutdownDrainTimeoutSeconds,
+		},
+		Matchmaking: MatchmakingConfig{
+			BaseRatingGap:      shared.MatchmakingBaseRatingGap,
+			GapWidenPerSecond:  shared.MatchmakingGapWidenPerSecond,
+			MaxRatingGap:       shared.MatchmakingMaxRatingGap,
+			TickSeconds:        shared.MatchmakingTickSeconds,
+			StatusEverySeconds: shared.MatchmakingStatusEverySeconds,
+			DefaultWaitSeconds: shared.MatchmakingDefaultWaitSeconds,
+			WaitSampleSize:     shared.MatchmakingWaitSampleSize,
+		},
+		Storage: StorageConfig{
+			Backend:      StorageBackendJSON,
+			ConfigsDir:   "configs",
+			DataDir:      "data",
+			WatchSeconds: shared.SpecWatchIntervalSeconds,
+		},
+	}
+}
+
+// Load reads a config file on top of the defaults. Settings missing from the file keep their
+// default value; unknown settings are rejected so typos do not go unnoticed.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// decodeStrict decodes JSON on top of v, rejecting unknown fields
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// Environment variables that override the config file
+const (
+	EnvListenAddr = "TCR_ADDR"
+	EnvConfigsDir = "TCR_CONFIGS_DIR"
+	EnvDataDir    = "TCR_DATA_DIR"
+	EnvLogFile    = "TCR_LOG_FILE"
+	EnvTurnTimer  = "TCR_MAX_TURN_TIMER_SECONDS"
+)
+
+// ApplyEnv overrides settings with the environment variables that are set.
+// lookup is normally os.LookupEnv.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	if value, ok := lookup(EnvListenAddr); ok {
+		c.Server.ListenAddr = value
+	}
+	if value, ok := lookup(EnvConfigsDir); ok {
+		c.Storage.ConfigsDir = value
+	}
+	if value, ok := lookup(EnvDataDir); ok {
+		c.Storage.DataDir = value
+	}
+	if value, ok := lookup(EnvLogFile); ok {
+		c.Logging.File = value
+	}
+	if value, ok := lookup(EnvTurnTimer); ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number of seconds: %v", EnvTurnTimer, err)
+		}
+		c.Timeouts.MaxTurnTimerSeconds = seconds
+	}
+	return nil
+}
+
+// Validate checks that every setting is usable and reports all problems at once
+func (c *Config) Validate() error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.ListenAddr != "", "server.listenAddr must not be empty")
+
+	type namedRules struct {
+		name  string
+		rules GameRules
+	}
+	// The damage formula and mana economy are what sets the modes apart; custom rule sets may mix them
+	check(c.Rules.Simple.Damage == shared.DamageFlat && c.Rules.Simple.Mana == shared.ManaFree,
+		"rules.simple must use damage %q and mana %q (Simple TCR has no critical hits and no mana; use a custom rule set instead)", shared.DamageFlat, shared.ManaFree)
+	check(c.Rules.Enhanced.Damage == shared.DamageCritical && c.Rules.Enhanced.Mana == shared.ManaPool,
+		"rules.enhanced must use damage %q and mana %q (use a custom rule set instead)", shared.DamageCritical, shared.ManaPool)
+	ruleSets := []namedRules{{"simple", c.Rules.Simple}, {"enhanced", c.Rules.Enhanced}}
+	for _, name := range slices.Sorted(maps.Keys(c.Rules.Custom)) {
+		check(name != "", "rules.custom names must not be empty")
+		ruleSets = append(ruleSets, namedRules{"custom." + name, c.Rules.Custom[name]})
+	}
+	for _, set := range ruleSets {
+		r := set.rules
+		check(r.MaxMana > 0, "rules.%s.maxMana must be positive", set.name)
+		check(r.InitialMana >= 0 && r.InitialMana <= r.MaxMana, "rules.%s.initialMana must be between 0 and maxMana", set.name)
+		check(r.ManaRegenRate >= 0, "rules.%s.manaRegenRate must not be negative", set.name)
+		check(r.WinEXPReward >= 0, "rules.%s.winExpReward must not be negative", set.name)
+		check(r.DrawEXPReward >= 0, "rules.%s.drawExpReward must not be negative", set.name)
+		check(r.QueenHealAmount >= 0, "rules.%s.queenHealAmount must not be negative", set.name)
+		check(r.CritDamageMultiplier >= 1, "rules.%s.critDamageMultiplier must be at least 1", set.name)
+		check(r.TroopCritChance >= 0 && r.TroopCritChance <= 100, "rules.%s.troopCritChance must be between 0 and 100", set.name)
+		check(slices.Contains(shared.TargetingRules, r.Targeting), "rules.%s.targeting %q is not supported (use one of %v)", set.name, r.Targeting, shared.TargetingRules)
+		check(slices.Contains(shared.DamageFormulas, r.Damage), "rules.%s.damage %q is not supported (use one of %v)", set.name, r.Damage, shared.DamageFormulas)
+		check(slices.Contains(shared.ManaEconomies, r.Mana), "rules.%s.mana %q is not supported (use one of %v)", set.name, r.Mana, shared.ManaEconomies)
+		check(slices.Contains(shared.TurnStructures, r.Turns), "rules.%s.turns %q is not supported (use one of %v)", set.name, r.Turns, shared.TurnStructures)
+		check(slices.Contains(shared.WinConditions, r.WinCondition), "rules.%s.winCondition %q is not supported (use one of %v)", set.name, r.WinCondition, shared.WinConditions)
+		check(slices.Contains(shared.EXPAwards, r.EXP), "rules.%s.exp %q is not supported (use one of %v)", set.name, r.EXP, shared.EXPAwards)
+		check(r.MatchSeconds >= 0, "rules.%s.matchSeconds must not be negative", set.name)
+		check(r.OvertimeSeconds >= 0, "rules.%s.overtimeSeconds must not be negative", set.name)
+		check(r.OvertimeSeconds == 0 || r.MatchSeconds > 0, "rules.%s.overtimeSeconds needs a match clock (matchSeconds)", set.name)
+		check(r.OvertimeManaRegenMultiplier >= 1, "rules.%s.overtimeManaRegenMultiplier must be at least 1", set.name)
+		check(r.HandicapManaPerLevel >= 0, "rules.%s.handicapManaPerLevel must not be negative", set.name)
+		check(r.HandicapTowerHPPercentPerLevel >= 0, "rules.%s.handicapTowerHpPercentPerLevel must not be negative", set.name)
+	}
+
+	t := c.Timeouts
+	check(t.ChallengeSeconds > 0, "timeouts.challengeSeconds must be positive")
+	check(t.RematchSeconds > 0, "timeouts.rematchSeconds must be positive")
+	check(t.MinTurnTimerSeconds > 0, "timeouts.minTurnTimerSeconds must be positive")
+	check(t.MaxTurnTimerSeconds >= t.MinTurnTimerSeconds, "timeouts.maxTurnTimerSeconds must not be below minTurnTimerSeconds")
+	check(t.ClientWriteSeconds > 0, "timeouts.clientWriteSeconds must be positive")
+	check(t.ShutdownDrainSeconds >= 0, "timeouts.shutdownDrainSeconds must not be negative")
+
+	m := c.Matchmaking
+	check(m.BaseRatingGap >= 0, "matchmaking.baseRatingGap must not be negative")
+	check(m.GapWidenPerSecond >= 0, "matchmaking.gapWidenPerSecond must not be negative")
+	check(m.MaxRatingGap >= m.BaseRatingGap, "matchmaking.maxRatingGap must not be below baseRatingGap")
+	check(m.TickSeconds > 0, "matchmaking.tickSeconds must be positive")
+	check(m.StatusEverySeconds > 0, "matchmaking.statusEverySeconds must be positive")
+	check(m.DefaultWaitSeconds >= 0, "matchmaking.defaultWaitSeconds must not be negative")
+	check(m.WaitSampleSize > 0, "matchmaking.waitSampleSize must be positive")
+
+	check(c.Storage.Backend == StorageBackendJSON, "storage.backend %q is not supported (use %q)", c.Storage.Backend, StorageBackendJSON)
+	check(c.Storage.ConfigsDir != "", "storage.configsDir must not be empty")
+	check(c.Storage.DataDir != "", "storage.dataDir must not be empty")
+	check(c.Storage.WatchSeconds >= 0, "storage.watchSeconds must not be negative")
+
+	return errors.Join(problems...)
+}
+
+// RulesFor returns the game rules of a game mode, falling back to the Simple TCR rules
+func (c *Config) RulesFor(mode string) GameRules {
+	if mode == shared.GameModeEnhanced {
+		return c.Rules.Enhanced
+	}
+	return c.Rules.Simple
+}
+
+// CustomRules returns the custom rule set with the given name
+func (c *Config) CustomRules(name string) (GameRules, bool) {
+	rules, exists := c.Rules.Custom[name]
+	return rules, exists
+}
+
+// Seconds converts a number of seconds from the config to a duration
+func Seconds(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// AllowedRatingGap returns the rating gap a player accepts after waiting for the given duration.
+// The gap widens the longer the player waits, up to MaxRatingGap.
+func (m MatchmakingConfig) AllowedRatingGap(waited time.Duration) int {
+	gap := m.BaseRatingGap + int(waited.Seconds())*m.GapWidenPerSecond
+	if gap > m.MaxRatingGap {
+		return m.MaxRatingGap
+	}
+	return gap
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"tcr/internal/shared"
+	"testing"
+	"time"
+)
+
+func TestAllowedRatingGap(t *testing.T) {
+	settings := MatchmakingConfig{BaseRatingGap: 100, GapWidenPerSecond: 10, MaxRatingGap: 300}
+	tests := []struct {
+		name   string
+		waited time.Duration
+		want   int
+	}{
+		{"just joined", 0, 100},
+		{"partial seconds are ignored", 900 * time.Millisecond, 100},
+		{"widens per second", 5 * time.Second, 150},
+		{"reaches the maximum", 20 * time.Second, 300},
+		{"capped at the maximum", time.Minute, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settings.AllowedRatingGap(tt.waited); got != tt.want {
+				t.Errorf("AllowedRatingGap(%v) = %d, want %d", tt.waited, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"no listen address", func(c *Config) { c.Server.ListenAddr = "" }, true},
+		{"initial mana above the maximum", func(c *Config) { c.Rules.Enhanced.InitialMana = c.Rules.Enhanced.MaxMana + 1 }, true},
+		{"crit chance above 100", func(c *Config) { c.Rules.Enhanced.TroopCritChance = 101 }, true},
+		{"crit multiplier below 1", func(c *Config) { c.Rules.Simple.CritDamageMultiplier = 0.5 }, true},
+		{"simple with mana", func(c *Config) { c.Rules.Simple.Mana = shared.ManaPool }, true},
+		{"simple with critical hits", func(c *Config) { c.Rules.Simple.Damage = shared.DamageCritical }, true},
+		{"enhanced without mana", func(c *Config) { c.Rules.Enhanced.Mana = shared.ManaFree }, true},
+		{"enhanced without critical hits", func(c *Config) { c.Rules.Enhanced.Damage = shared.DamageFlat }, true},
+		{"unknown targeting rule", func(c *Config) { c.Rules.Enhanced.Targeting = "DIAGONAL" }, true},
+		{"unknown damage formula", func(c *Config) { c.Rules.Simple.Damage = "RANDOM" }, true},
+		{"custom rule set mixes the rules", func(c *Config) {
+			custom := c.Rules.Simple
+			custom.Targeting = shared.TargetingLanes
+			custom.Mana = shared.ManaPool
+			c.Rules.Custom = map[string]GameRules{"siege": custom}
+		}, false},
+		{"invalid custom rule set", func(c *Config) {
+			custom := c.Rules.Simple
+			custom.WinCondition = "FIRST_BLOOD"
+			c.Rules.Custom = map[string]GameRules{"siege": custom}
+		}, true},
+		{"overtime without a clock", func(c *Config) { c.Rules.Simple.OvertimeSeconds = 30 }, true},
+		{"overtime with a clock", func(c *Config) { c.Rules.Enhanced.OvertimeSeconds = 30 }, false},
+		{"turn timer range inverted", func(c *Config) { c.Timeouts.MaxTurnTimerSeconds = c.Timeouts.MinTurnTimerSeconds - 1 }, true},
+		{"max rating gap below the base gap", func(c *Config) { c.Matchmaking.MaxRatingGap = c.Matchmaking.BaseRatingGap - 1 }, true},
+		{"unsupported storage backend", func(c *Config) { c.Storage.Backend = "sql" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  bool
+		wantAddr string
+	}{
+		{"missing settings keep their defaults", `{"timeouts": {"challengeSeconds": 30}}`, false, ":8080"},
+		{"overrides a default", `{"server": {"listenAddr": ":9000"}}`, false, ":9000"},
+		{"unknown setting", `{"server": {"port": 9000}}`, true, ""},
+		{"unknown rule in a custom rule set", `{"rules": {"custom": {"siege": {"speed": 2}}}}`, true, ""},
+		{"syntax error", `{"server": `, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "server.json")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Server.ListenAddr != tt.wantAddr {
+				t.Errorf("ListenAddr = %q, want %q", cfg.Server.ListenAddr, tt.wantAddr)
+			}
+			if cfg.Rules.Simple != Default().Rules.Simple {
+				t.Errorf("Rules = %+v, want the defaults", cfg.Rules)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		EnvListenAddr: ":9000",
+		EnvDataDir:    "/tmp/tcr",
+		EnvTurnTimer:  "120",
+	}
+	cfg := Default()
+	if err := cfg.ApplyEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if cfg.Server.ListenAddr != ":9000" || cfg.Storage.DataDir != "/tmp/tcr" || cfg.Timeouts.MaxTurnTimerSeconds != 120 {
+		t.Errorf("ApplyEnv() left %q, %q, %d", cfg.Server.ListenAddr, cfg.Storage.DataDir, cfg.Timeouts.MaxTurnTimerSeconds)
+	}
+	if cfg.Storage.ConfigsDir != Default().Storage.ConfigsDir {
+		t.Errorf("ConfigsDir = %q, want it unchanged", cfg.Storage.ConfigsDir)
+	}
+
+	env[EnvTurnTimer] = "two minutes"
+	if err := Default().ApplyEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}); err == nil {
+		t.Error("ApplyEnv() accepted a turn timer that is not a number")
+	}
+}
+
+func TestLoadCustomRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.json")
+	contents := `{"rules": {"simple": {"maxMana": 30}, "custom": {"siege": {"mana": "FREE"}}}}`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	siege, exists := cfg.CustomRules("siege")
+	if !exists {
+		t.Fatal("custom rule set siege was not loaded")
+	}
+	if siege.Mana != shared.ManaFree {
+		t.Errorf("siege mana = %q, want %q", siege.Mana, shared.ManaFree)
+	}
+	if siege.MaxMana != 30 {
+		t.Errorf("siege maxMana = %d, want 30 taken from the simple rules", siege.MaxMana)
+	}
+	if cfg.Rules.Enhanced.MaxMana != Default().Rules.Enhanced.MaxMana {
+		t.Errorf("enhanced maxMana = %d, want the default", cfg.Rules.Enhanced.MaxMana)
+	}
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"tcr/internal/shared"
+)
+
+// Hit is the result of an attack on one tower or building. A troop with splash or chain attacks
+// hits several targets with one deploy; the first hit is always its target.
+type Hit struct {
+	TowerID     string // The tower hit, or the building if Building is set
+	Kind        string // shared.HitPrimary, shared.HitSplash, shared.HitChain or shared.HitBuilding
+	Building    bool   // Whether the hit was on a building
+	Damage      int
+	Critical    bool
+	Destroyed   bool // Whether this hit destroyed the tower or building
+	HPRemaining int
+}
+
+// resolveAttack applies a troop's attack by the player on a target tower of the owner, with the
+// damage of the match's damage formula
+func (gs *GameSession) resolveAttack(player *Player, troop *TroopInstance, owner *Player, target *TowerInstance) []Hit {
+	damage, critical := gs.GameState.Rules.Damage(troop, target.CurrentDEF)
+	return gs.spreadAttack(player, troop, owner, target, damage, critical)
+}
+
+// spreadAttack deals damage to a target tower of the owner, then the card's splash to adjacent
+// towers and buildings and its chain to further ones. Only towers the match's targeting rule
+// allowed to attack before the attack can be hit, and each tower or building is hit at most once.
+// Buildings destroyed this way award their EXP to the attacking player.
+func (gs *GameSession) spreadAttack(player *Player, troop *TroopInstance, owner *Player, target *TowerInstance, damage int, critical bool) []Hit {
+	rules := gs.GameState.Rules
+	towers := make([]*TowerInstance, 0, len(owner.Towers))
+	for _, tower := range owner.Towers {
+		if tower != target && rules.CanTarget(owner, tower) {
+			towers = append(towers, tower)
+		}
+	}
+	buildings := slices.Clone(owner.Buildings)
+
+	hits := []Hit{applyHit(target, shared.HitPrimary, damage, critical)}
+
+	// Splash deals a share of the damage to every open tower next to the target, and to every
+	// building guarding it
+	if splash := troop.Spec.SplashPercent; splash > 0 {
+		for _, tower := range towers {
+			if adjacentTowers(target, tower) {
+				hits = append(hits, applyHit(tower, shared.HitSplash, damage*splash/100, critical))
+			}
+		}
+		for _, building := range buildings {
+			if building.Protects(target) {
+				hits = append(hits, gs.splashBuilding(player, owner, building, shared.HitSplash, damage*splash/100, critical))
+			}
+		}
+	}
+
+	// The chain jumps to the next open towers, then to the buildings, losing a share of its damage
+	// on every jump
+	chainDamage, chained := damage, 0
+	for _, tower := range towers {
+		if chained == troop.Spec.ChainTargets {
+			break
+		}
+		if hitTower(hits, tower.ID) {
+			continue
+		}
+		chainDamage = chainDamage * (100 - troop.Spec.ChainFalloffPercent) / 100
+		hits = append(hits, applyHit(tower, shared.HitChain, chainDamage, critical))
+		chained++
+	}
+	for _, building := range buildings {
+		if chained == troop.Spec.ChainTargets {
+			break
+		}
+		if hitTower(hits, building.ID) {
+			continue
+		}
+		chainDamage = chainDamage * (100 - troop.Spec.ChainFalloffPercent) / 100
+		hits = append(hits, gs.splashBuilding(player, owner, building, shared.HitChain, chainDamage, critical))
+		chained++
+	}
+	return hits
+}
+
+// splashBuilding deals a splash or chain hit's damage to a building of the owner
+func (gs *GameSession) splashBuilding(player *Player, owner *Player, building *BuildingInstance, kind string, damage int, critical bool) Hit {
+	hit := gs.attackBuilding(player, owner, building, damage, critical)
+	hit.Kind = kind
+	return hit
+}
+
+// applyHit deals damage to a tower and reports the hit
+func applyHit(tower *TowerInstance, kind string, damage int, critical bool) Hit {
+	tower.CurrentHP -= damage
+	destroyed := false
+	if tower.CurrentHP <= 0 {
+		tower.CurrentHP = 0
+		tower.Destroyed = true
+		destroyed = true
+	}
+	return Hit{TowerID: tower.ID, Kind: kind, Damage: damage, Critical: critical, Destroyed: destroyed, HPRemaining: tower.CurrentHP}
+}
+
+// adjacentTowers checks if one of two towers requires the other, e.g. a guard tower and the King it protects
+func adjacentTowers(a, b *TowerInstance) bool {
+	return slices.Contains(a.Spec.Requires, b.Spec.Type) || slices.Contains(b.Spec.Requires, a.Spec.Type)
+}
+
+// hitTower checks if an attack already hit a tower
+func hitTower(hits []Hit, towerID string) bool {
+	for _, hit := range hits {
+		if hit.TowerID == towerID {
+			return true
+		}
+	}
+	return false
+}
+
+// describeHits describes the splash and chain hits of an attack for the action log,
+// e.g. " Splash hit b_KING for 60 damage (HP remaining: 1740)."
+func describeHits(hits []Hit) string {
+	description := ""
+	for _, hit := range hits {
+		if hit.Kind == shared.HitPrimary {
+			continue
+		}
+		kind := "Splash"
+		if hit.Kind == shared.HitChain {
+			kind = "Chain"
+		}
+		if hit.Destroyed {
+			description += fmt.Sprintf(" %s hit %s for %d damage and destroyed it!", kind, hit.TowerID, hit.Damage)
+		} else {
+			description += fmt.Sprintf(" %s hit %s for %d damage (HP remaining: %d).", kind, hit.TowerID, hit.Damage, hit.HPRemaining)
+		}
+	}
+	return description
+}
@@ -0,0 +1,251 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+	"testing"
+)
+
+// newAttackSession creates a match whose towers have 1000 HP and no DEF, so every hit deals the
+// troop's ATK. The King requires LEFT and RIGHT; MID is a guard tower no tower requires.
+func newAttackSession(t *testing.T, targeting string) *GameSession {
+	t.Helper()
+
+	towers := []models.TowerSpec{
+		{Name: "King Tower", Type: "KING", Role: shared.KingTowerRole, Requires: []string{"LEFT", "RIGHT"}, BaseHP: 1000},
+		{Name: "Left Guard", Type: "LEFT", Role: shared.GuardTowerRole, BaseHP: 1000},
+		{Name: "Right Guard", Type: "RIGHT", Role: shared.GuardTowerRole, BaseHP: 1000},
+		{Name: "Middle Guard", Type: "MID", Role: shared.GuardTowerRole, BaseHP: 1000},
+	}
+	troops := []models.TroopSpec{{Name: "Pawn", BaseHP: 10, BaseATK: 10}}
+	gs := NewGameSession("a", "b", troops, towers, storage.NewJSONHandler("", t.TempDir()))
+
+	rules := config.Default().Rules.Simple
+	rules.Damage = shared.DamageFlat
+	rules.Targeting = targeting
+	gs.GameState.Rules = ComposeRuleSet("test", rules)
+	return gs
+}
+
+// wantHit is an expected hit, with the tower given by type and a building by name
+type wantHit struct {
+	tower       string
+	kind        string
+	damage      int
+	destroyed   bool
+	hpRemaining int
+}
+
+func TestSpreadAttack(t *testing.T) {
+	classic, lanes := shared.TargetingClassic, shared.TargetingLanes
+	leftWall := models.TroopSpec{Name: "LeftWall", CardType: shared.CardBuilding, BaseHP: 150, DestroyEXP: 20, Protects: "LEFT"}
+	rightWall := models.TroopSpec{Name: "RightWall", CardType: shared.CardBuilding, BaseHP: 150, DestroyEXP: 20, Protects: "RIGHT"}
+	wall := models.TroopSpec{Name: "Wall", CardType: shared.CardBuilding, BaseHP: 150, DestroyEXP: 20}
+	tests := []struct {
+		name      string
+		targeting string
+		troop     models.TroopSpec
+		towerHP   map[string]int     // HP of towers before the attack; 0 destroys the tower
+		buildings []models.TroopSpec // Buildings on the owner's side, with 150 HP
+		target    string
+		want      []wantHit
+		wantEXP   int // EXP of the attacking player for destroyed buildings
+	}{
+		{
+			name:      "single target",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Knight"},
+			target:    "LEFT",
+			want:      []wantHit{{"LEFT", shared.HitPrimary, 200, false, 800}},
+		},
+		{
+			name:      "splash on an adjacent tower",
+			targeting: lanes,
+			troop:     models.TroopSpec{Name: "Wizard", SplashPercent: 50},
+			towerHP:   map[string]int{"LEFT": 0},
+			target:    "KING",
+			want: []wantHit{
+				{"KING", shared.HitPrimary, 200, false, 800},
+				{"RIGHT", shared.HitSplash, 100, false, 900},
+			},
+		},
+		{
+			name:      "splash skips towers that are not adjacent",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Wizard", SplashPercent: 50},
+			target:    "MID",
+			want:      []wantHit{{"MID", shared.HitPrimary, 200, false, 800}},
+		},
+		{
+			name:      "splash skips closed towers",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Wizard", SplashPercent: 50},
+			target:    "RIGHT",
+			want:      []wantHit{{"RIGHT", shared.HitPrimary, 200, false, 800}},
+		},
+		{
+			name:      "chain falls off on every jump",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 2, ChainFalloffPercent: 50},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"RIGHT", shared.HitChain, 100, false, 900},
+				{"MID", shared.HitChain, 50, false, 950},
+			},
+		},
+		{
+			name:      "chain stops at its target count",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 1, ChainFalloffPercent: 25},
+			target:    "MID",
+			want: []wantHit{
+				{"MID", shared.HitPrimary, 200, false, 800},
+				{"LEFT", shared.HitChain, 150, false, 850},
+			},
+		},
+		{
+			name:      "chain skips closed and destroyed towers",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 3},
+			towerHP:   map[string]int{"RIGHT": 0},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"MID", shared.HitChain, 200, false, 800},
+			},
+		},
+		{
+			name:      "each tower is hit once",
+			targeting: lanes,
+			troop:     models.TroopSpec{Name: "Storm", SplashPercent: 50, ChainTargets: 3, ChainFalloffPercent: 50},
+			towerHP:   map[string]int{"MID": 0},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"KING", shared.HitSplash, 100, false, 900},
+				{"RIGHT", shared.HitChain, 100, false, 900},
+			},
+		},
+		{
+			name:      "splash on buildings guarding the target",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Wizard", SplashPercent: 50},
+			buildings: []models.TroopSpec{leftWall, rightWall, wall},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"LeftWall", shared.HitSplash, 100, false, 50},
+				{"Wall", shared.HitSplash, 100, false, 50},
+			},
+		},
+		{
+			name:      "chain jumps to buildings after the towers",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 3, ChainFalloffPercent: 50},
+			buildings: []models.TroopSpec{rightWall},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"RIGHT", shared.HitChain, 100, false, 900},
+				{"MID", shared.HitChain, 50, false, 950},
+				{"RightWall", shared.HitChain, 25, false, 125},
+			},
+		},
+		{
+			name:      "each building is hit once",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Storm", SplashPercent: 50, ChainTargets: 3, ChainFalloffPercent: 50},
+			buildings: []models.TroopSpec{wall},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"Wall", shared.HitSplash, 100, false, 50},
+				{"RIGHT", shared.HitChain, 100, false, 900},
+				{"MID", shared.HitChain, 50, false, 950},
+			},
+		},
+		{
+			name:      "chain destroys a building",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 1},
+			towerHP:   map[string]int{"RIGHT": 0, "MID": 0},
+			buildings: []models.TroopSpec{rightWall},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"RightWall", shared.HitChain, 200, true, 0},
+			},
+			wantEXP: 20,
+		},
+		{
+			name:      "chain destroys a tower",
+			targeting: classic,
+			troop:     models.TroopSpec{Name: "Zapper", ChainTargets: 1},
+			towerHP:   map[string]int{"RIGHT": 150},
+			target:    "LEFT",
+			want: []wantHit{
+				{"LEFT", shared.HitPrimary, 200, false, 800},
+				{"RIGHT", shared.HitChain, 200, true, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newAttackSession(t, tt.targeting)
+			attacker, owner := gs.GameState.PlayerA, gs.GameState.PlayerB
+			for towerType, hp := range tt.towerHP {
+				tower := owner.TowerOfType(towerType)
+				tower.CurrentHP = hp
+				tower.Destroyed = hp == 0
+			}
+			buildings := make(map[string]*BuildingInstance)
+			for _, spec := range tt.buildings {
+				building := placeTestBuilding(owner, spec, spec.BaseHP, 3)
+				buildings[building.ID] = building
+			}
+			tt.troop.BaseATK = 200
+			troop := &TroopInstance{Spec: &tt.troop, CurrentATK: 200}
+
+			hits := gs.spreadAttack(attacker, troop, owner, owner.TowerOfType(tt.target), 200, false)
+			if len(hits) != len(tt.want) {
+				t.Fatalf("spreadAttack() = %d hits %+v, want %d", len(hits), hits, len(tt.want))
+			}
+			for i, hit := range hits {
+				want := tt.want[i]
+				if hit.Kind != want.kind || hit.Damage != want.damage || hit.Destroyed != want.destroyed || hit.HPRemaining != want.hpRemaining {
+					t.Errorf("hit %d = %+v, want %+v", i, hit, want)
+					continue
+				}
+				if hit.Building {
+					building := buildings[hit.TowerID]
+					if building == nil || building.Spec.Name != want.tower {
+						t.Errorf("hit %d on building %s, want %s", i, hit.TowerID, want.tower)
+					} else if building.CurrentHP != want.hpRemaining || (owner.Building(hit.TowerID) == nil) != want.destroyed {
+						t.Errorf("%s has %d HP (standing %v) after the attack, want %d (destroyed %v)",
+							want.tower, building.CurrentHP, owner.Building(hit.TowerID) != nil, want.hpRemaining, want.destroyed)
+					}
+					continue
+				}
+				tower := owner.Tower(hit.TowerID)
+				if tower == nil || tower.Spec.Type != want.tower {
+					t.Errorf("hit %d on tower %s, want %s", i, hit.TowerID, want.tower)
+				} else if tower.CurrentHP != want.hpRemaining || tower.Destroyed != want.destroyed {
+					t.Errorf("%s has %d HP (destroyed %v) after the attack, want %d (destroyed %v)",
+						want.tower, tower.CurrentHP, tower.Destroyed, want.hpRemaining, want.destroyed)
+				}
+			}
+			for id, building := range buildings {
+				if !hitTower(hits, id) && building.CurrentHP != building.MaxHP {
+					t.Errorf("%s was not hit but has %d of %d HP", id, building.CurrentHP, building.MaxHP)
+				}
+			}
+			if attacker.CurrentEXP != tt.wantEXP {
+				t.Errorf("attacker EXP = %d, want %d", attacker.CurrentEXP, tt.wantEXP)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"tcr/internal/shared"
+)
+
+// isBuilding checks if a card in a hand is a building
+func isBuilding(card *TroopInstance) bool {
+	return card.Spec.Card() == shared.CardBuilding
+}
+
+// placeBuilding puts a building card on the side of the player and returns what happened
+func (gs *GameSession) placeBuilding(player *Player, card *TroopInstance) string {
+	side := gs.GameState.Side(player.Username)
+	building := NewBuildingInstance(card, fmt.Sprintf("%s_%s_%d", side.Username, card.Spec.Name, gs.GameState.TurnNumber), player.Username)
+	side.Buildings = append(side.Buildings, building)
+
+	protects := "every tower"
+	if building.Spec.Protects != "" {
+		protects = building.Spec.Protects
+	}
+	return fmt.Sprintf("%s placed %s (ID: %s, HP: %d) guarding %s for %s.",
+		player.Username, card.Spec.Name, building.ID, building.CurrentHP, protects, turnsText(building.TurnsLeft))
+}
+
+// attackBuilding deals an attack's damage to a building of the owner. A destroyed building is
+// removed and awards its EXP to the attacking player.
+func (gs *GameSession) attackBuilding(attacker *Player, owner *Player, building *BuildingInstance, damage int, critical bool) Hit {
+	building.CurrentHP -= damage
+	hit := Hit{TowerID: building.ID, Kind: shared.HitBuilding, Building: true, Damage: damage, Critical: critical, HPRemaining: building.CurrentHP}
+	if building.CurrentHP <= 0 {
+		building.CurrentHP = 0
+		hit.HPRemaining = 0
+		hit.Destroyed = true
+		owner.removeBuilding(building)
+		attacker.CurrentEXP += gs.GameState.Rules.BuildingEXP(building)
+	}
+	return hit
+}
+
+// describeBuildingHit describes a hit on a building for the action log
+func describeBuildingHit(hit Hit) string {
+	if hit.Destroyed {
+		return fmt.Sprintf("dealt %d damage to %s and destroyed it!", hit.Damage, hit.TowerID)
+	}
+	return fmt.Sprintf("dealt %d damage to %s (HP remaining: %d).", hit.Damage, hit.TowerID, hit.HPRemaining)
+}
+
+// switchTurn passes the turn on, then lets the buildings of the side whose turn begins attack
+// and age. It returns what the buildings did, or "" if nothing happened.
+func (gs *GameSession) switchTurn() string {
+	gs.GameState.SwitchTurn()
+
+	side := gs.GameState.Side(gs.GameState.CurrentTurn)
+	enemy := gs.GameState.OpposingSide(gs.GameState.CurrentTurn)
+	report := ""
+
+	// Buildings with ATK attack the first enemy building on the field. Troops never stay on the
+	// field after attacking, so enemy buildings are the only units there.
+	for _, building := range side.Buildings {
+		if building.CurrentATK <= 0 || len(enemy.Buildings) == 0 {
+			continue
+		}
+		if building.FrozenTurns > 0 {
+			building.FrozenTurns--
+			report += fmt.Sprintf(" %s is frozen and skips its attack.", building.ID)
+			continue
+		}
+		target := enemy.Buildings[0]
+		hit := gs.attackBuilding(gs.GameState.Player(building.Owner), enemy, target, CalculateDamage(building.CurrentATK, target.CurrentDEF), false)
+		report += fmt.Sprintf(" %s %s", building.ID, describeBuildingHit(hit))
+		if hit.Destroyed {
+			if levelUpMessage := gs.HandleExperienceAndLevelUp(gs.GameState.Player(building.Owner)); levelUpMessage != "" {
+				report += " " + levelUpMessage
+			}
+		}
+	}
+
+	// Buildings expire once their lifetime is over
+	for _, building := range slices.Clone(side.Buildings) {
+		building.TurnsLeft--
+		if building.TurnsLeft <= 0 {
+			side.removeBuilding(building)
+			report += fmt.Sprintf(" %s expired.", building.ID)
+		}
+	}
+	return report
+}
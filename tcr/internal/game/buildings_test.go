@@ -0,0 +1,254 @@
+package game
+
+import (
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+var (
+	cannonSpec = models.TroopSpec{Name: "Cannon", BaseHP: 300, BaseATK: 100, ManaCost: 3, CardType: shared.CardBuilding, Lifetime: 3, DestroyEXP: 20}
+	wallSpec   = models.TroopSpec{Name: "Wall", BaseHP: 300, BaseDEF: 20, ManaCost: 2, CardType: shared.CardBuilding, Lifetime: 3, DestroyEXP: 20}
+)
+
+// placeTestBuilding puts a building on a player's side with the given HP and turns left
+func placeTestBuilding(side *Player, spec models.TroopSpec, hp, turnsLeft int) *BuildingInstance {
+	building := NewBuildingInstance(NewTroopInstance(&spec, spec.Name, 1), side.Username+"_"+spec.Name, side.Username)
+	building.CurrentHP = hp
+	building.TurnsLeft = turnsLeft
+	side.Buildings = append(side.Buildings, building)
+	return building
+}
+
+// testBuilding is a building standing before a test, with its HP and turns left
+type testBuilding struct {
+	spec      models.TroopSpec
+	hp        int
+	turnsLeft int
+}
+
+func TestSwitchTurnBuildings(t *testing.T) {
+	tests := []struct {
+		name         string
+		alice        []testBuilding // Alice's turn begins
+		bob          []testBuilding // Bob's buildings have 2 turns left, which do not age on alice's turn
+		wantAlice    []int          // Turns left of alice's buildings still standing
+		wantBobHP    []int          // HP of bob's buildings still standing
+		wantAliceEXP int
+		aliceFrozen  int // Attacks alice's first building skips
+		wantFrozen   int // Attacks it still skips afterwards
+	}{
+		{
+			name:      "attacks the first enemy building",
+			alice:     []testBuilding{{cannonSpec, 300, 3}},
+			bob:       []testBuilding{{wallSpec, 300, 2}, {cannonSpec, 300, 2}},
+			wantAlice: []int{2},
+			wantBobHP: []int{220, 300},
+		},
+		{
+			name:         "destroys the first enemy building",
+			alice:        []testBuilding{{cannonSpec, 300, 3}},
+			bob:          []testBuilding{{wallSpec, 50, 2}, {cannonSpec, 300, 2}},
+			wantAlice:    []int{2},
+			wantBobHP:    []int{300},
+			wantAliceEXP: 20,
+		},
+		{
+			name:      "buildings without ATK do not attack",
+			alice:     []testBuilding{{wallSpec, 300, 3}},
+			bob:       []testBuilding{{wallSpec, 300, 2}},
+			wantAlice: []int{2},
+			wantBobHP: []int{300},
+		},
+		{
+			name:        "frozen buildings skip their attack",
+			alice:       []testBuilding{{cannonSpec, 300, 3}},
+			bob:         []testBuilding{{wallSpec, 300, 2}},
+			wantAlice:   []int{2},
+			wantBobHP:   []int{300},
+			aliceFrozen: 2,
+			wantFrozen:  1,
+		},
+		{
+			name:      "no enemy building to attack",
+			alice:     []testBuilding{{cannonSpec, 300, 3}},
+			wantAlice: []int{2},
+		},
+		{
+			name:      "expires when its lifetime is over",
+			alice:     []testBuilding{{cannonSpec, 300, 1}, {wallSpec, 300, 2}},
+			bob:       []testBuilding{{wallSpec, 300, 2}},
+			wantAlice: []int{1},
+			wantBobHP: []int{220},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, 0)
+			alice, bob := gs.GameState.PlayerA, gs.GameState.PlayerB
+			for _, b := range tt.alice {
+				placeTestBuilding(alice, b.spec, b.hp, b.turnsLeft)
+			}
+			for _, b := range tt.bob {
+				placeTestBuilding(bob, b.spec, b.hp, b.turnsLeft)
+			}
+			if len(alice.Buildings) > 0 {
+				alice.Buildings[0].FrozenTurns = tt.aliceFrozen
+			}
+			gs.GameState.CurrentTurn = "bob"
+
+			gs.switchTurn()
+			if gs.GameState.CurrentTurn != "alice" {
+				t.Fatalf("turn went to %s, want alice", gs.GameState.CurrentTurn)
+			}
+
+			if len(alice.Buildings) != len(tt.wantAlice) {
+				t.Fatalf("alice has %d buildings, want %d", len(alice.Buildings), len(tt.wantAlice))
+			}
+			for i, want := range tt.wantAlice {
+				if got := alice.Buildings[i].TurnsLeft; got != want {
+					t.Errorf("alice's %s has %d turns left, want %d", alice.Buildings[i].ID, got, want)
+				}
+			}
+			if got := alice.Buildings[0].FrozenTurns; got != tt.wantFrozen {
+				t.Errorf("alice's %s skips %d more attacks, want %d", alice.Buildings[0].ID, got, tt.wantFrozen)
+			}
+			if len(bob.Buildings) != len(tt.wantBobHP) {
+				t.Fatalf("bob has %d buildings, want %d", len(bob.Buildings), len(tt.wantBobHP))
+			}
+			for i, want := range tt.wantBobHP {
+				if got := bob.Buildings[i].CurrentHP; got != want {
+					t.Errorf("bob's %s has %d HP, want %d", bob.Buildings[i].ID, got, want)
+				}
+				if got := bob.Buildings[i].TurnsLeft; got != 2 {
+					t.Errorf("bob's %s has %d turns left on alice's turn, want 2", bob.Buildings[i].ID, got)
+				}
+			}
+			if alice.CurrentEXP != tt.wantAliceEXP {
+				t.Errorf("alice EXP = %d, want %d", alice.CurrentEXP, tt.wantAliceEXP)
+			}
+		})
+	}
+}
+
+func TestBuildingLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		standing int
+		wantOK   bool
+	}{
+		{"no building yet", 0, true},
+		{"room for one more", shared.MaxBuildings - 1, true},
+		{"limit reached", shared.MaxBuildings, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, 10, cannonSpec)
+			alice := gs.GameState.PlayerA
+			for i := 0; i < tt.standing; i++ {
+				placeTestBuilding(alice, wallSpec, 300, 3)
+			}
+
+			msg, ok := gs.DeployTroop("alice", "Cannon", "")
+			if ok != tt.wantOK {
+				t.Fatalf("DeployTroop(Cannon) ok = %v, want %v (%s)", ok, tt.wantOK, msg)
+			}
+			wantBuildings, wantMana := tt.standing, 10
+			if tt.wantOK {
+				wantBuildings, wantMana = tt.standing+1, 7
+			}
+			if len(alice.Buildings) != wantBuildings {
+				t.Errorf("alice has %d buildings, want %d", len(alice.Buildings), wantBuildings)
+			}
+			if alice.CurrentMana != wantMana {
+				t.Errorf("mana = %d, want %d", alice.CurrentMana, wantMana)
+			}
+		})
+	}
+}
+
+func TestBuildingProtectsTower(t *testing.T) {
+	fireball := fireballSpec
+	fireball.SplashPercent = 0 // Splash would hit the wall, see TestSpreadAttack
+	tests := []struct {
+		name          string
+		card          models.TroopSpec
+		protects      string
+		wallHP        int
+		wantWallHP    int // 0 if the wall is destroyed
+		wantLeftHP    int
+		wantHitKind   string
+		wantDestroyed bool
+	}{
+		{"protects every tower", rookSpec, "", 300, 120, 1000, shared.HitBuilding, false},
+		{"protects the target", rookSpec, "LEFT", 300, 120, 1000, shared.HitBuilding, false},
+		{"protects another tower", rookSpec, "RIGHT", 300, 300, 900, shared.HitPrimary, false},
+		{"destroyed while protecting", rookSpec, "", 150, 0, 1000, shared.HitBuilding, true},
+		{"fireballs fly over it", fireball, "", 300, 300, 700, shared.HitPrimary, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, 10, tt.card)
+			bob := gs.GameState.PlayerB
+			spec := wallSpec
+			spec.Protects = tt.protects
+			wall := placeTestBuilding(bob, spec, tt.wallHP, 3)
+
+			if msg, ok := gs.DeployTroop("alice", tt.card.Name, "bob_LEFT"); !ok {
+				t.Fatalf("DeployTroop(%s) failed: %s", tt.card.Name, msg)
+			}
+
+			hits := gs.GameState.LastHits
+			if len(hits) != 1 || hits[0].Kind != tt.wantHitKind || hits[0].Destroyed != tt.wantDestroyed {
+				t.Errorf("hits = %+v, want one %s hit (destroyed %v)", hits, tt.wantHitKind, tt.wantDestroyed)
+			}
+			if wall.CurrentHP != tt.wantWallHP {
+				t.Errorf("wall HP = %d, want %d", wall.CurrentHP, tt.wantWallHP)
+			}
+			if standing := bob.Building(wall.ID) != nil; standing == tt.wantDestroyed {
+				t.Errorf("wall standing = %v, want %v", standing, !tt.wantDestroyed)
+			}
+			if left := bob.TowerOfType("LEFT"); left.CurrentHP != tt.wantLeftHP {
+				t.Errorf("LEFT HP = %d, want %d", left.CurrentHP, tt.wantLeftHP)
+			}
+		})
+	}
+}
+
+func TestTroopsPreferBuildings(t *testing.T) {
+	hunter := rookSpec
+	hunter.PreferredTarget = shared.TargetTroops
+	tests := []struct {
+		name       string
+		target     string
+		wall       bool
+		wantWallHP int
+		wantLeftHP int
+	}{
+		{"attacks the first enemy building", "", true, 120, 1000},
+		{"falls back to a tower without buildings", "", false, 0, 900},
+		{"a named tower beats the preference", "bob_LEFT", true, 300, 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, 10, hunter)
+			bob := gs.GameState.PlayerB
+			var wall *BuildingInstance
+			if tt.wall {
+				spec := wallSpec
+				spec.Protects = "RIGHT"
+				wall = placeTestBuilding(bob, spec, 300, 3)
+			}
+
+			if msg, ok := gs.DeployTroop("alice", "Rook", tt.target); !ok {
+				t.Fatalf("DeployTroop(Rook, %q) failed: %s", tt.target, msg)
+			}
+			if wall != nil && wall.CurrentHP != tt.wantWallHP {
+				t.Errorf("wall HP = %d, want %d", wall.CurrentHP, tt.wantWallHP)
+			}
+			if left := bob.TowerOfType("LEFT"); left.CurrentHP != tt.wantLeftHP {
+				t.Errorf("LEFT HP = %d, want %d", left.CurrentHP, tt.wantLeftHP)
+			}
+		})
+	}
+}
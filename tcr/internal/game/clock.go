@@ -0,0 +1,67 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"tcr/internal/shared"
+)
+
+// EndRegularTime decides a match whose regular time ran out: whoever destroyed more towers wins.
+// With equal tower counts the match goes to sudden-death overtime if its rule set has one,
+// and is a draw otherwise. It returns a message describing what happened, and true if overtime started.
+func (gs *GameSession) EndRegularTime() (string, bool) {
+	state := gs.GameState
+	if state.IsGameOver || state.InOvertime() {
+		return "", false
+	}
+
+	destroyedByA := state.PlayerB.DestroyedTowers()
+	destroyedByB := state.PlayerA.DestroyedTowers()
+
+	switch {
+	case destroyedByA > destroyedByB:
+		state.EndReason = fmt.Sprintf("Time up: %s destroyed more towers (%d to %d)", state.TeamName(state.PlayerA), destroyedByA, destroyedByB)
+		return "Time is up! " + gs.HandleGameOver(state.PlayerA.Username, false), false
+	case destroyedByB > destroyedByA:
+		state.EndReason = fmt.Sprintf("Time up: %s destroyed more towers (%d to %d)", state.TeamName(state.PlayerB), destroyedByB, destroyedByA)
+		return "Time is up! " + gs.HandleGameOver(state.PlayerB.Username, false), false
+	}
+
+	if state.Rules.OvertimeDuration() <= 0 {
+		state.EndReason = fmt.Sprintf("Time up with %d towers destroyed each", destroyedByA)
+		return "Time is up! " + gs.HandleGameOver("", true), false
+	}
+
+	state.Phase = shared.PhaseOvertime
+	state.LastHits = nil
+	message := fmt.Sprintf("Time is up with %d towers destroyed each. OVERTIME: the first tower destroyed wins!", destroyedByA)
+	if state.Rules.UsesMana() {
+		message += fmt.Sprintf(" Mana now regenerates %d per turn.", state.Rules.TurnRegen(true))
+	}
+	log.Printf("Overtime between %s and %s", state.TeamName(state.PlayerA), state.TeamName(state.PlayerB))
+	return message, true
+}
+
+// EndOvertime decides a match whose overtime ran out without a tower being destroyed:
+// the player with the higher share of tower HP left wins, and equal shares are a draw.
+func (gs *GameSession) EndOvertime() string {
+	state := gs.GameState
+	if state.IsGameOver || !state.InOvertime() {
+		return ""
+	}
+
+	percentA := state.PlayerA.TowerHPPercent()
+	percentB := state.PlayerB.TowerHPPercent()
+
+	switch {
+	case percentA > percentB:
+		state.EndReason = fmt.Sprintf("Overtime over: %s kept more tower HP (%.1f%% to %.1f%%)", state.TeamName(state.PlayerA), percentA, percentB)
+		return "Overtime is over! " + gs.HandleGameOver(state.PlayerA.Username, false)
+	case percentB > percentA:
+		state.EndReason = fmt.Sprintf("Overtime over: %s kept more tower HP (%.1f%% to %.1f%%)", state.TeamName(state.PlayerB), percentB, percentA)
+		return "Overtime is over! " + gs.HandleGameOver(state.PlayerB.Username, false)
+	}
+
+	state.EndReason = fmt.Sprintf("Overtime over with equal tower HP (%.1f%%)", percentA)
+	return "Overtime is over! " + gs.HandleGameOver("", true)
+}
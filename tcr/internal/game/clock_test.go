@@ -0,0 +1,106 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+	"testing"
+)
+
+// newClockSession starts a match between a and b with three towers each and the given overtime
+func newClockSession(t *testing.T, overtimeSeconds int) *GameSession {
+	towers := []models.TowerSpec{
+		{Name: "King Tower", Type: "KING", Role: shared.KingTowerRole, Requires: []string{"LEFT", "RIGHT"}, BaseHP: 100},
+		{Name: "Left Guard", Type: "LEFT", Role: shared.GuardTowerRole, BaseHP: 100},
+		{Name: "Right Guard", Type: "RIGHT", Role: shared.GuardTowerRole, BaseHP: 100},
+	}
+	troops := []models.TroopSpec{{Name: "Pawn", BaseHP: 10, BaseATK: 10}}
+	session := NewGameSession("a", "b", troops, towers, storage.NewJSONHandler("", t.TempDir()))
+
+	rules := config.Default().Rules.Simple
+	rules.MatchSeconds = 60
+	rules.OvertimeSeconds = overtimeSeconds
+	session.GameState.Rules = NewSimpleRuleSet(rules)
+	return session
+}
+
+// setTowers sets the HP of a player's towers in order; a tower at 0 HP is destroyed
+func setTowers(player *Player, hp ...int) {
+	for i, tower := range player.Towers {
+		tower.MaxHP = 100
+		tower.CurrentHP = hp[i]
+		tower.Destroyed = hp[i] == 0
+	}
+}
+
+func TestEndRegularTime(t *testing.T) {
+	tests := []struct {
+		name         string
+		overtime     int
+		towersA      []int
+		towersB      []int
+		wantWinner   string
+		wantOvertime bool
+	}{
+		{"a destroyed more towers", 30, []int{100, 100, 100}, []int{100, 0, 100}, "a", false},
+		{"b destroyed more towers", 30, []int{100, 0, 0}, []int{100, 0, 100}, "b", false},
+		{"tie goes to overtime", 30, []int{100, 0, 100}, []int{100, 100, 0}, "", true},
+		{"tie without overtime is a draw", 0, []int{100, 100, 100}, []int{100, 100, 100}, "DRAW", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newClockSession(t, tt.overtime)
+			state := session.GameState
+			setTowers(state.PlayerA, tt.towersA...)
+			setTowers(state.PlayerB, tt.towersB...)
+
+			_, overtime := session.EndRegularTime()
+			if overtime != tt.wantOvertime || state.InOvertime() != tt.wantOvertime {
+				t.Errorf("EndRegularTime() overtime = %v (phase %s), want %v", overtime, state.Phase, tt.wantOvertime)
+			}
+			if state.Winner != tt.wantWinner {
+				t.Errorf("winner = %q, want %q", state.Winner, tt.wantWinner)
+			}
+			if state.IsGameOver == tt.wantOvertime {
+				t.Errorf("IsGameOver = %v with overtime %v", state.IsGameOver, tt.wantOvertime)
+			}
+		})
+	}
+}
+
+func TestEndOvertime(t *testing.T) {
+	tests := []struct {
+		name       string
+		towersA    []int
+		towersB    []int
+		wantWinner string
+	}{
+		{"a kept more tower HP", []int{100, 50, 100}, []int{100, 40, 100}, "a"},
+		{"b kept more tower HP", []int{90, 0, 100}, []int{100, 0, 100}, "b"},
+		{"equal tower HP is a draw", []int{100, 50, 0}, []int{50, 100, 0}, "DRAW"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newClockSession(t, 30)
+			state := session.GameState
+			state.Phase = shared.PhaseOvertime
+			setTowers(state.PlayerA, tt.towersA...)
+			setTowers(state.PlayerB, tt.towersB...)
+
+			if message := session.EndOvertime(); message == "" {
+				t.Fatal("EndOvertime() did not end the match")
+			}
+			if !state.IsGameOver || state.Winner != tt.wantWinner {
+				t.Errorf("winner = %q (game over %v), want %q", state.Winner, state.IsGameOver, tt.wantWinner)
+			}
+		})
+	}
+}
+
+func TestEndOvertimeOutsideOvertime(t *testing.T) {
+	session := newClockSession(t, 30)
+	if message := session.EndOvertime(); message != "" || session.GameState.IsGameOver {
+		t.Errorf("EndOvertime() in regular time = %q, want no effect", message)
+	}
+}
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+)
+
+// HasUnlocked checks if a card is in the player's collection: cards unlock at their UnlockLevel
+func (p *Player) HasUnlocked(spec *models.TroopSpec) bool {
+	return spec.UnlockLevel <= p.Level
+}
+
+// CardProgress returns the player's progress with a card
+func (p *Player) CardProgress(name string) storage.CardProgress {
+	return cardProgress(p.Cards, name)
+}
+
+// cardProgress returns the progress with a card in a collection; cards without an entry are at level 1
+func cardProgress(cards map[string]storage.CardProgress, name string) storage.CardProgress {
+	progress := cards[name]
+	if progress.Level < 1 {
+		progress.Level = 1
+	}
+	return progress
+}
+
+// CardLevel returns the level a card of the player is scaled to: its card level, lowered to the
+// level cap of the match if there is one
+func (p *Player) CardLevel(name string) int {
+	level := p.CardProgress(name).Level
+	if p.LevelCap > 0 && level > p.LevelCap {
+		return p.LevelCap
+	}
+	return level
+}
+
+// AddCardCopies adds copies of a card to the player's collection. The card is upgraded whenever
+// enough copies are collected for its next level, using up those copies.
+// It returns the card's progress afterwards.
+func (p *Player) AddCardCopies(name string, copies int) storage.CardProgress {
+	progress := p.CardProgress(name)
+	progress.Copies += copies
+	for progress.Level < shared.MaxCardLevel && progress.Copies >= shared.CardUpgradeCopies(progress.Level) {
+		progress.Copies -= shared.CardUpgradeCopies(progress.Level)
+		progress.Level++
+	}
+	if p.Cards == nil {
+		p.Cards = make(map[string]storage.CardProgress)
+	}
+	p.Cards[name] = progress
+	return progress
+}
+
+// deckOf returns the specs of the cards the player plays with: the cards of their deck that
+// exist in this match's specs and are unlocked, or every unlocked card if that leaves none
+func (gs *GameSession) deckOf(player *Player) []*models.TroopSpec {
+	unlocked := make([]*models.TroopSpec, 0, len(gs.TroopSpecs))
+	deck := make([]*models.TroopSpec, 0, len(player.Deck))
+	for i := range gs.TroopSpecs {
+		spec := &gs.TroopSpecs[i]
+		if !player.HasUnlocked(spec) {
+			continue
+		}
+		unlocked = append(unlocked, spec)
+		if slices.Contains(player.Deck, spec.Name) {
+			deck = append(deck, spec)
+		}
+	}
+	if len(deck) == 0 {
+		return unlocked
+	}
+	return deck
+}
+
+// awardCards gives a player copies of random cards from their collection and returns what they
+// received, e.g. "alice received Knight x2, Pawn x1. Knight upgraded to level 2!"
+func (gs *GameSession) awardCards(player *Player, copies int) string {
+	unlocked := make([]string, 0, len(gs.TroopSpecs))
+	for i := range gs.TroopSpecs {
+		if player.HasUnlocked(&gs.TroopSpecs[i]) {
+			unlocked = append(unlocked, gs.TroopSpecs[i].Name)
+		}
+	}
+	if len(unlocked) == 0 || copies <= 0 {
+		return ""
+	}
+
+	// Draw the copies, keeping the order cards were first drawn in
+	drawn := make(map[string]int)
+	order := make([]string, 0, copies)
+	for i := 0; i < copies; i++ {
+		name := unlocked[rand.Intn(len(unlocked))]
+		if drawn[name] == 0 {
+			order = append(order, name)
+		}
+		drawn[name]++
+	}
+
+	received := make([]string, 0, len(order))
+	upgrades := ""
+	for _, name := range order {
+		oldLevel := player.CardProgress(name).Level
+		progress := player.AddCardCopies(name, drawn[name])
+		received = append(received, fmt.Sprintf("%s x%d", name, drawn[name]))
+		gs.GameState.CardRewards = append(gs.GameState.CardRewards, models.CardReward{
+			Username: player.Username,
+			Card:     name,
+			Copies:   drawn[name],
+			Level:    progress.Level,
+			Upgraded: progress.Level > oldLevel,
+		})
+		if progress.Level > oldLevel {
+			upgrades += fmt.Sprintf(" %s upgraded to level %d!", name, progress.Level)
+		}
+	}
+	return fmt.Sprintf("%s received %s.%s", player.Username, strings.Join(received, ", "), upgrades)
+}
+
+// unlockedBetween returns the names of the cards unlocked by levelling up from oldLevel to newLevel
+func (gs *GameSession) unlockedBetween(oldLevel, newLevel int) []string {
+	var names []string
+	for _, spec := range gs.TroopSpecs {
+		if spec.UnlockLevel > oldLevel && spec.UnlockLevel <= newLevel {
+			names = append(names, spec.Name)
+		}
+	}
+	return names
+}
+
+// ValidateDeck checks a deck a player of the given level wants to play with: between MinDeckSize
+// and MaxDeckSize unlocked cards of the specs, without duplicates, and with at least one troop
+func ValidateDeck(specs []models.TroopSpec, level int, deck []string) error {
+	if len(deck) < shared.MinDeckSize || len(deck) > shared.MaxDeckSize {
+		return fmt.Errorf("a deck needs %d to %d cards, got %d", shared.MinDeckSize, shared.MaxDeckSize, len(deck))
+	}
+	troops := 0
+	for i, name := range deck {
+		index := slices.IndexFunc(specs, func(spec models.TroopSpec) bool { return spec.Name == name })
+		if index < 0 {
+			return fmt.Errorf("unknown card %q", name)
+		}
+		if slices.Contains(deck[:i], name) {
+			return fmt.Errorf("%s is in the deck twice", name)
+		}
+		if specs[index].UnlockLevel > level {
+			return fmt.Errorf("%s unlocks at level %d", name, specs[index].UnlockLevel)
+		}
+		if specs[index].Card() == shared.CardTroop {
+			troops++
+		}
+	}
+	if troops == 0 {
+		return fmt.Errorf("a deck needs at least one troop")
+	}
+	return nil
+}
+
+// CollectionCards lists every card of the specs as it stands in a player's collection
+func CollectionCards(specs []models.TroopSpec, profile storage.PlayerProfile) []models.CollectionCard {
+	cards := make([]models.CollectionCard, len(specs))
+	for i := range specs {
+		spec := &specs[i]
+		progress := cardProgress(profile.Cards, spec.Name)
+		cards[i] = models.CollectionCard{
+			Name:        spec.Name,
+			CardType:    spec.Card(),
+			ManaCost:    spec.ManaCost,
+			UnlockLevel: max(spec.UnlockLevel, 1),
+			Unlocked:    spec.UnlockLevel <= profile.Level,
+			InDeck:      slices.Contains(profile.Deck, spec.Name),
+		}
+		if cards[i].Unlocked {
+			cards[i].Level = progress.Level
+			cards[i].Copies = progress.Copies
+			if progress.Level < shared.MaxCardLevel {
+				cards[i].CopiesForNextLevel = shared.CardUpgradeCopies(progress.Level)
+			}
+		}
+	}
+	return cards
+}
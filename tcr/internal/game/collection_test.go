@@ -0,0 +1,73 @@
+package game
+
+import (
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+	"testing"
+)
+
+func TestAddCardCopies(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  storage.CardProgress
+		copies int
+		want   storage.CardProgress
+	}{
+		{"new card collects copies", storage.CardProgress{}, 1, storage.CardProgress{Level: 1, Copies: 1}},
+		{"upgrades with enough copies", storage.CardProgress{Level: 1, Copies: 1}, shared.CardUpgradeCopies(1), storage.CardProgress{Level: 2, Copies: 1}},
+		{"upgrades several levels at once", storage.CardProgress{Level: 1}, shared.CardUpgradeCopies(1) + shared.CardUpgradeCopies(2), storage.CardProgress{Level: 3}},
+		{"stops at the max level", storage.CardProgress{Level: shared.MaxCardLevel}, 100, storage.CardProgress{Level: shared.MaxCardLevel, Copies: 100}},
+		{"reaches the max level", storage.CardProgress{Level: shared.MaxCardLevel - 1}, shared.CardUpgradeCopies(shared.MaxCardLevel-1) + 1, storage.CardProgress{Level: shared.MaxCardLevel, Copies: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := &Player{}
+			if tt.start != (storage.CardProgress{}) {
+				player.Cards = map[string]storage.CardProgress{"Knight": tt.start}
+			}
+			got := player.AddCardCopies("Knight", tt.copies)
+			if got != tt.want {
+				t.Errorf("AddCardCopies(%d) = %+v, want %+v", tt.copies, got, tt.want)
+			}
+			if player.Cards["Knight"] != got {
+				t.Errorf("collection holds %+v, want %+v", player.Cards["Knight"], got)
+			}
+		})
+	}
+}
+
+func TestValidateDeck(t *testing.T) {
+	specs := []models.TroopSpec{
+		{Name: "Pawn", UnlockLevel: 1},
+		{Name: "Knight", UnlockLevel: 1},
+		{Name: "Bishop", UnlockLevel: 2},
+		{Name: "Prince", UnlockLevel: 5},
+		{Name: "Cannon", CardType: shared.CardBuilding, UnlockLevel: 1},
+		{Name: "Fireball", CardType: shared.CardSpell, UnlockLevel: 1},
+		{Name: "Freeze", CardType: shared.CardSpell, UnlockLevel: 1},
+		{Name: "Rage", CardType: shared.CardSpell, UnlockLevel: 1},
+	}
+	tests := []struct {
+		name    string
+		level   int
+		deck    []string
+		wantErr bool
+	}{
+		{"valid deck", 2, []string{"Pawn", "Knight", "Bishop", "Fireball"}, false},
+		{"full deck", 5, []string{"Pawn", "Knight", "Bishop", "Prince", "Cannon", "Fireball", "Freeze", "Rage"}, false},
+		{"too few cards", 5, []string{"Pawn", "Knight", "Bishop"}, true},
+		{"too many cards", 5, []string{"Pawn", "Knight", "Bishop", "Prince", "Cannon", "Fireball", "Freeze", "Rage", "Pawn"}, true},
+		{"unknown card", 5, []string{"Pawn", "Knight", "Bishop", "Dragon"}, true},
+		{"duplicate card", 5, []string{"Pawn", "Knight", "Bishop", "Knight"}, true},
+		{"locked card", 4, []string{"Pawn", "Knight", "Bishop", "Prince"}, true},
+		{"no troop", 1, []string{"Cannon", "Fireball", "Freeze", "Rage"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDeck(specs, tt.level, tt.deck); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDeck(%v) = %v, want error %v", tt.deck, err, tt.wantErr)
+			}
+		})
+	}
+}
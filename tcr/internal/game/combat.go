@@ -2,7 +2,6 @@ package game
 
 import (
 	"math/rand"
-	"tcr/internal/shared"
 	"time"
 )
 
@@ -16,11 +15,16 @@ func CalculateDamage(attackerEffectiveATK int, defenderEffectiveDEF int) int {
 	return damage
 }
 
+// PenetrateArmor returns the defense left after an attacker ignores armorPenetrationPercent of it
+func PenetrateArmor(defenderEffectiveDEF int, armorPenetrationPercent int) int {
+	return defenderEffectiveDEF * (100 - armorPenetrationPercent) / 100
+}
+
 // CalculateDamageEnhanced calculates damage dealt by an attacker to a defender,
-// incorporating critical hit logic based on the attacker's crit chance.
-// Formula: DMG = (ATK_A or ATK_A * CritDamageMultiplier if CRIT) - DEF_B (if ≥ 0)
+// incorporating critical hit logic based on the attacker's crit chance and its armor penetration.
+// Formula: DMG = (ATK_A or ATK_A * critDamageMultiplier if CRIT) - DEF_B * (100 - penetration%) (if ≥ 0)
 // It returns the calculated damage and a boolean indicating if a critical hit occurred.
-func CalculateDamageEnhanced(attackerEffectiveATK int, defenderEffectiveDEF int, attackerCritChancePercent float64) (damage int, didCrit bool) {
+func CalculateDamageEnhanced(attackerEffectiveATK int, defenderEffectiveDEF int, attackerCritChancePercent float64, critDamageMultiplier float64, armorPenetrationPercent int) (damage int, didCrit bool) {
 	// Seed random number generator only once if not already done elsewhere globally
 	// For simplicity in this function, we can seed it. In a larger app, seed once at startup.
 	// Consider moving seed to main or init if not already there.
@@ -31,10 +35,10 @@ func CalculateDamageEnhanced(attackerEffectiveATK int, defenderEffectiveDEF int,
 
 	if attackerCritChancePercent > 0 && rand.Float64()*100 < attackerCritChancePercent {
 		didCrit = true
-		rawAttack *= shared.CritDamageMultiplier
+		rawAttack *= critDamageMultiplier
 	}
 
-	calculatedDamage := int(rawAttack) - defenderEffectiveDEF
+	calculatedDamage := int(rawAttack) - PenetrateArmor(defenderEffectiveDEF, armorPenetrationPercent)
 	if calculatedDamage < 0 {
 		return 0, didCrit
 	}
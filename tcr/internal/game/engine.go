@@ -4,64 +4,46 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"strings"
+	"tcr/internal/config"
 	"tcr/internal/models"
 	"tcr/internal/shared"
 	"tcr/internal/storage"
 	"time"
 )
 
-// GameSession represents a game session between two players
+// GameSession represents a game session between two players, or two teams of two in 2v2
 type GameSession struct {
 	GameState   *GameState
 	TroopSpecs  []models.TroopSpec   // Available troops for both players
 	TowerSpecs  []models.TowerSpec   // Available towers for both players
 	JSONHandler *storage.JSONHandler // Added to save player data
+	Settings    models.MatchSettings // Match settings (mode, turn timer, starting mana)
 }
 
-// NewGameSession creates a new game session with two players
+// NewGameSession creates a new game session with two players using the default Simple TCR settings and rules
 func NewGameSession(playerAName, playerBName string, troopSpecs []models.TroopSpec, towerSpecs []models.TowerSpec, jsonHandler *storage.JSONHandler) *GameSession {
+	cfg := config.Default()
+	settings := DefaultMatchSettings(cfg, shared.GameModeSimple)
+	return NewGameSessionWithSettings(playerAName, playerBName, troopSpecs, towerSpecs, jsonHandler, settings, NewRuleSet(cfg, settings))
+}
+
+// NewGameSessionWithSettings creates a new game session with two players, the given match settings
+// and the rule set of the match
+func NewGameSessionWithSettings(playerAName, playerBName string, troopSpecs []models.TroopSpec, towerSpecs []models.TowerSpec, jsonHandler *storage.JSONHandler, settings models.MatchSettings, rules RuleSet) *GameSession {
 	// Initialize random seed
 	rand.NewSource(time.Now().UnixNano())
 
-	// Create player A
-	playerA := NewPlayer(playerAName) // Initializes with defaults (Lvl 1, 0 EXP, etc)
-	profileA, errA := jsonHandler.LoadPlayerData(playerAName)
-	if errA != nil {
-		log.Printf("Error loading player data for %s: %v. Using default/initial stats.", playerAName, errA)
-		// Keep default NewPlayer stats, but ensure RequiredEXP is set based on level 1
-		playerA.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(playerA.Level) // Should be 100 for level 1
-	} else {
-		playerA.Level = profileA.Level
-		playerA.CurrentEXP = profileA.CurrentEXP
-		playerA.RequiredEXPForNextLevel = profileA.RequiredEXPForNextLevel
-		// If loaded profile had 0 for RequiredEXP (e.g. old format or error), recalculate
-		if playerA.RequiredEXPForNextLevel == 0 {
-			playerA.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(playerA.Level)
-		}
-	}
-	playerA.CurrentMana = shared.InitialMana // Initialize Mana for Enhanced TCR
-
-	// Create player B
-	playerB := NewPlayer(playerBName)
-	profileB, errB := jsonHandler.LoadPlayerData(playerBName)
-	if errB != nil {
-		log.Printf("Error loading player data for %s: %v. Using default/initial stats.", playerBName, errB)
-		playerB.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(playerB.Level)
-	} else {
-		playerB.Level = profileB.Level
-		playerB.CurrentEXP = profileB.CurrentEXP
-		playerB.RequiredEXPForNextLevel = profileB.RequiredEXPForNextLevel
-		if playerB.RequiredEXPForNextLevel == 0 {
-			playerB.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(playerB.Level)
-		}
-	}
-	playerB.CurrentMana = shared.InitialMana // Initialize Mana for Enhanced TCR
+	// Create both players
+	playerA := newMatchPlayer(playerAName, jsonHandler, settings)
+	playerB := newMatchPlayer(playerBName, jsonHandler, settings)
 
 	// Initialize the game session
 	gs := &GameSession{
 		TroopSpecs:  troopSpecs,
 		TowerSpecs:  towerSpecs,
 		JSONHandler: jsonHandler, // Store the handler
+		Settings:    settings,
 	}
 
 	// Assign towers to players
@@ -71,87 +53,127 @@ func NewGameSession(playerAName, playerBName string, troopSpecs []models.TroopSp
 	gs.assignTroopsToPlayers(playerA, playerB)
 
 	// Create game state
-	gs.GameState = NewGameState(playerA, playerB)
+	gs.GameState = NewGameState(playerA, playerB, rules)
+
+	// Give the lower-level player the match's handicap bonus
+	gs.GameState.Handicap = applyHandicap(playerA, playerB, settings.Handicap, rules)
 
 	return gs
 }
 
-// assignTowersToPlayers assigns towers to both players
-func (gs *GameSession) assignTowersToPlayers(playerA, playerB *Player) {
-	// Find tower specs by type
-	var kingTowerSpec, guardTower1Spec, guardTower2Spec *models.TowerSpec
+// NewTeamGameSession creates a new 2v2 game session. Each team is given as two usernames; the first
+// player of each team owns the team's towers, and both players of a team get their own hand and mana.
+func NewTeamGameSession(teamA, teamB [2]string, troopSpecs []models.TroopSpec, towerSpecs []models.TowerSpec, jsonHandler *storage.JSONHandler, settings models.MatchSettings, rules RuleSet) *GameSession {
+	playerA := newMatchPlayer(teamA[0], jsonHandler, settings)
+	partnerA := newMatchPlayer(teamA[1], jsonHandler, settings)
+	playerB := newMatchPlayer(teamB[0], jsonHandler, settings)
+	partnerB := newMatchPlayer(teamB[1], jsonHandler, settings)
 
-	for i := range gs.TowerSpecs {
-		switch gs.TowerSpecs[i].Type {
-		case "KING":
-			kingTowerSpec = &gs.TowerSpecs[i]
-		case "GUARD1":
-			guardTower1Spec = &gs.TowerSpecs[i]
-		case "GUARD2":
-			guardTower2Spec = &gs.TowerSpecs[i]
+	gs := &GameSession{
+		TroopSpecs:  troopSpecs,
+		TowerSpecs:  towerSpecs,
+		JSONHandler: jsonHandler,
+		Settings:    settings,
+	}
+
+	// Partners defend the same towers, scaled to the average level of the team:
+	// their tower lists point at the tower owner's instances
+	playerA.Towers = gs.newTowers(playerA.Username, (playerA.EffectiveLevel()+partnerA.EffectiveLevel())/2)
+	playerB.Towers = gs.newTowers(playerB.Username, (playerB.EffectiveLevel()+partnerB.EffectiveLevel())/2)
+	partnerA.Towers = playerA.Towers
+	partnerB.Towers = playerB.Towers
+
+	gs.assignTroopsToPlayers(playerA, playerB)
+	gs.assignTroopsToPlayers(partnerA, partnerB)
+
+	gs.GameState = NewTeamGameState(playerA, partnerA, playerB, partnerB, rules)
+	return gs
+}
+
+// newMatchPlayer creates a player for a match from their saved profile, with the starting mana
+// and level cap of the match settings
+func newMatchPlayer(username string, jsonHandler *storage.JSONHandler, settings models.MatchSettings) *Player {
+	player := NewPlayer(username) // Initializes with defaults (Lvl 1, 0 EXP, etc)
+	profile, err := jsonHandler.LoadPlayerData(username)
+	if err != nil {
+		log.Printf("Error loading player data for %s: %v. Using default/initial stats.", username, err)
+		// Keep default NewPlayer stats, but ensure RequiredEXP is set based on level 1
+		player.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(player.Level) // Should be 100 for level 1
+	} else {
+		player.Level = profile.Level
+		player.CurrentEXP = profile.CurrentEXP
+		player.RequiredEXPForNextLevel = profile.RequiredEXPForNextLevel
+		player.Rating = profile.Rating
+		player.Deck = profile.Deck
+		if profile.Cards != nil {
+			player.Cards = profile.Cards
+		}
+		// If loaded profile had 0 for RequiredEXP (e.g. old format or error), recalculate
+		if player.RequiredEXPForNextLevel == 0 {
+			player.RequiredEXPForNextLevel = shared.CalculateRequiredEXP(player.Level)
 		}
 	}
+	player.CurrentMana = settings.StartingMana // Initialize Mana for Enhanced TCR
+
+	// Tournament-standard matches scale stats to the level cap at most
+	player.LevelCap = settings.LevelCap
+	return player
+}
 
-	// Assign towers to player A
-	playerA.KingTower = NewTowerInstance(kingTowerSpec, playerA.Username, playerA.Level)
-	playerA.GuardTower1 = NewTowerInstance(guardTower1Spec, playerA.Username, playerA.Level)
-	playerA.GuardTower2 = NewTowerInstance(guardTower2Spec, playerA.Username, playerA.Level)
+// assignTowersToPlayers gives both players one tower for every tower spec
+func (gs *GameSession) assignTowersToPlayers(playerA, playerB *Player) {
+	playerA.Towers = gs.newTowers(playerA.Username, playerA.EffectiveLevel())
+	playerB.Towers = gs.newTowers(playerB.Username, playerB.EffectiveLevel())
+}
 
-	// Assign towers to player B
-	playerB.KingTower = NewTowerInstance(kingTowerSpec, playerB.Username, playerB.Level)
-	playerB.GuardTower1 = NewTowerInstance(guardTower1Spec, playerB.Username, playerB.Level)
-	playerB.GuardTower2 = NewTowerInstance(guardTower2Spec, playerB.Username, playerB.Level)
+// newTowers creates a full set of towers for the given owner, scaled to the given level
+func (gs *GameSession) newTowers(ownerUsername string, level int) []*TowerInstance {
+	towers := make([]*TowerInstance, 0, len(gs.TowerSpecs))
+	for i := range gs.TowerSpecs {
+		towers = append(towers, NewTowerInstance(&gs.TowerSpecs[i], ownerUsername, level))
+	}
+	return towers
 }
 
-// assignTroopsToPlayers assigns random troops to both players
+// assignTroopsToPlayers assigns random troops from their decks to both players
 func (gs *GameSession) assignTroopsToPlayers(playerA, playerB *Player) {
-	// Filter out special-only troops (like Queen)
-	regularTroops := make([]models.TroopSpec, 0)
-	specialTroops := make([]models.TroopSpec, 0)
+	gs.dealHand(playerA)
+	gs.dealHand(playerB)
+}
 
-	for _, troop := range gs.TroopSpecs {
-		if troop.IsSpecialOnly {
+// dealHand gives a player 3 random regular cards of their deck and every spell in it.
+// Cards are scaled to the player's card levels.
+func (gs *GameSession) dealHand(player *Player) {
+	// Separate spells (like Queen) from regular troops
+	regularTroops := make([]*models.TroopSpec, 0)
+	specialTroops := make([]*models.TroopSpec, 0)
+
+	for _, troop := range gs.deckOf(player) {
+		if troop.Card() == shared.CardSpell {
 			specialTroops = append(specialTroops, troop)
 		} else {
 			regularTroops = append(regularTroops, troop)
 		}
 	}
+	if len(regularTroops) == 0 {
+		return
+	}
 
-	// Both players should always have access to special troops
-	// For regular troops, randomly select 3 unique troops for each player
+	// Randomly select 3 unique regular troops, repeating some if the deck has fewer
 	regularTroopIndices := rand.Perm(len(regularTroops))
-
-	// Ensure we have enough regular troops
-	if len(regularTroopIndices) < 6 {
-		// If we don't have 6 unique regular troops, just use what we have and repeat some
-		for i := len(regularTroopIndices); i < 6; i++ {
-			regularTroopIndices = append(regularTroopIndices, i%len(regularTroops))
-		}
+	for i := len(regularTroopIndices); i < 3; i++ {
+		regularTroopIndices = append(regularTroopIndices, i%len(regularTroops))
 	}
-
-	// Assign 3 regular troops to player A
 	for i := 0; i < 3; i++ {
 		troopSpec := regularTroops[regularTroopIndices[i]]
-		troopInstance := NewTroopInstance(&troopSpec, fmt.Sprintf("%s_troop_%d", playerA.Username, i), playerA.Level)
-		playerA.Troops = append(playerA.Troops, troopInstance)
+		troopInstance := NewTroopInstance(troopSpec, fmt.Sprintf("%s_troop_%d", player.Username, i), player.CardLevel(troopSpec.Name))
+		player.Troops = append(player.Troops, troopInstance)
 	}
 
-	// Assign 3 regular troops to player B
-	for i := 0; i < 3; i++ {
-		troopSpec := regularTroops[regularTroopIndices[i+3]]
-		troopInstance := NewTroopInstance(&troopSpec, fmt.Sprintf("%s_troop_%d", playerB.Username, i), playerB.Level)
-		playerB.Troops = append(playerB.Troops, troopInstance)
-	}
-
-	// Add special troops (like Queen) to both players
+	// The player always has access to the spells of their deck
 	for i, troopSpec := range specialTroops {
-		// Add to player A
-		troopInstanceA := NewTroopInstance(&troopSpec, fmt.Sprintf("%s_special_%d", playerA.Username, i), playerA.Level)
-		playerA.Troops = append(playerA.Troops, troopInstanceA)
-
-		// Add to player B
-		troopInstanceB := NewTroopInstance(&troopSpec, fmt.Sprintf("%s_special_%d", playerB.Username, i), playerB.Level)
-		playerB.Troops = append(playerB.Troops, troopInstanceB)
+		troopInstance := NewTroopInstance(troopSpec, fmt.Sprintf("%s_special_%d", player.Username, i), player.CardLevel(troopSpec.Name))
+		player.Troops = append(player.Troops, troopInstance)
 	}
 }
 
@@ -164,17 +186,13 @@ func (gs *GameSession) DeployTroop(playerUsername, troopName, targetTowerID stri
 	}
 
 	// Get the player who is deploying the troop
-	var actingPlayer *Player
-	if playerUsername == gs.GameState.PlayerA.Username {
-		actingPlayer = gs.GameState.PlayerA
-	} else if playerUsername == gs.GameState.PlayerB.Username {
-		actingPlayer = gs.GameState.PlayerB
-	} else {
+	actingPlayer := gs.GameState.Player(playerUsername)
+	if actingPlayer == nil {
 		return "Invalid player username.", false
 	}
 
-	// Check if it's the player's turn
-	if gs.GameState.CurrentTurn != playerUsername && !gs.GameState.CanContinueAttacking {
+	// Check if it's the player's turn; a bonus attack belongs to the player whose turn it is
+	if gs.GameState.CurrentTurn != playerUsername {
 		return "It's not your turn.", false
 	}
 
@@ -184,83 +202,151 @@ func (gs *GameSession) DeployTroop(playerUsername, troopName, targetTowerID stri
 		return "Troop not found in your hand.", false
 	}
 
-	// Check mana cost for non-special troops
-	if !troop.Spec.IsSpecialOnly {
-		if actingPlayer.CurrentMana < troop.Spec.ManaCost {
-			return fmt.Sprintf("Not enough mana to deploy %s. Requires %d, you have %d.", troopName, troop.Spec.ManaCost, actingPlayer.CurrentMana), false
+	// A side has room for a limited number of buildings
+	if isBuilding(troop) && len(gs.GameState.Side(playerUsername).Buildings) >= shared.MaxBuildings {
+		return fmt.Sprintf("You already have %d buildings standing.", shared.MaxBuildings), false
+	}
+
+	// Freeze acts on an enemy building, the only units that attack; towers cannot be frozen
+	var frozenBuilding *BuildingInstance
+	if isSpell(troop) && troop.Spec.SpecialAbility == shared.FreezeAbility {
+		if frozenBuilding = gs.freezeTarget(actingPlayer, targetTowerID); frozenBuilding == nil {
+			return "No enemy building to freeze (towers cannot be frozen).", false
 		}
-		// Deduct mana only if it's a regular troop and has mana cost
-		actingPlayer.CurrentMana -= troop.Spec.ManaCost
 	}
 
-	// Handle Queen's special ability (or other special-only troops)
-	if troop.Spec.IsSpecialOnly {
-		// Apply special ability
-		abilityResult := ApplySpecialAbility(actingPlayer, troop.Spec)
+	// Check the mana cost under the match's mana economy; it is paid once the card's target is valid
+	rules := gs.GameState.Rules
+	cost := rules.DeployCost(troop.Spec)
+	if cost > 0 && actingPlayer.CurrentMana < cost {
+		return fmt.Sprintf("Not enough mana to deploy %s. Requires %d, you have %d.", troopName, cost, actingPlayer.CurrentMana), false
+	}
+
+	// Spells without a target (like Queen's heal) act at once, and buildings are placed on the
+	// player's side
+	if isBuilding(troop) || (isSpell(troop) && !spellNeedsTarget(troop.Spec)) {
+		actingPlayer.CurrentMana -= cost
+		var cardResult string
+		if isBuilding(troop) {
+			cardResult = gs.placeBuilding(actingPlayer, troop)
+		} else {
+			cardResult = gs.castSpell(actingPlayer, troop, frozenBuilding)
+		}
 
-		// Remove Queen from hand after use
+		// Remove the card from hand after use
 		actingPlayer.Troops = append(actingPlayer.Troops[:troopIndex], actingPlayer.Troops[troopIndex+1:]...)
 
-		// Replenish one troop (even for Queen, as she is consumed)
+		// Replenish one troop (spells and buildings are consumed too)
 		gs.replenishTroopForPlayer(actingPlayer)
+		gs.GameState.LastHits = nil
 
 		// End turn (even if continue attacking was true)
 		if !gs.GameState.CanContinueAttacking {
-			gs.GameState.SwitchTurn()
+			cardResult += gs.switchTurn()
 		} else {
 			gs.GameState.CanContinueAttacking = false
 		}
 
-		return abilityResult, true
+		return cardResult, true
+	}
+
+	opponentPlayer := gs.GameState.GetOpponentPlayer()
+
+	// Troops attack an enemy building they name, or the first one if they prefer TROOPS and name no target
+	var targetBuilding *BuildingInstance
+	if !isSpell(troop) {
+		targetBuilding = opponentPlayer.Building(targetTowerID)
+		if targetTowerID == "" && troop.Spec.PreferredTarget == shared.TargetTroops && len(opponentPlayer.Buildings) > 0 {
+			targetBuilding = opponentPlayer.Buildings[0]
+		}
+	}
+
+	// Without a named target, the troop (or targeted spell) picks one by its preferred target
+	if targetBuilding == nil && targetTowerID == "" {
+		if chosen := ChooseTarget(troop, opponentPlayer, rules); chosen != nil {
+			targetTowerID = chosen.ID
+		}
 	}
 
 	// Validate the target
-	if !IsValidTarget(actingPlayer, targetTowerID, gs.GameState) {
+	if targetBuilding == nil && !IsValidTarget(actingPlayer, targetTowerID, gs.GameState) {
 		return "Invalid target tower.", false
 	}
 
+	// The target is valid: pay for the card
+	actingPlayer.CurrentMana -= cost
+
+	// Rage boosts the player's next troop
+	attacker := troop
+	var rageMessage string
+	if !isSpell(troop) && actingPlayer.RagePercent > 0 {
+		raged := *troop
+		raged.CurrentATK = troop.CurrentATK * (100 + actingPlayer.RagePercent) / 100
+		rageMessage = fmt.Sprintf(" (RAGE +%d%%)", actingPlayer.RagePercent)
+		actingPlayer.RagePercent = 0
+		attacker = &raged
+	}
+
 	// Get the target tower
-	var targetTower *TowerInstance
-	opponentPlayer := gs.GameState.GetOpponentPlayer()
+	targetTower := opponentPlayer.Tower(targetTowerID)
 
-	if targetTowerID == opponentPlayer.KingTower.ID {
-		targetTower = opponentPlayer.KingTower
-	} else if targetTowerID == opponentPlayer.GuardTower1.ID {
-		targetTower = opponentPlayer.GuardTower1
-	} else if targetTowerID == opponentPlayer.GuardTower2.ID {
-		targetTower = opponentPlayer.GuardTower2
+	// A building protecting the target tower intercepts troop attacks
+	if targetBuilding == nil && !isSpell(troop) {
+		targetBuilding = opponentPlayer.Protector(targetTower)
+	}
+	if targetBuilding != nil {
+		return gs.deployAgainstBuilding(actingPlayer, opponentPlayer, attacker, troopIndex, targetBuilding, targetTower, rageMessage), true
 	}
 
-	// Calculate damage using Enhanced Combat with Crit Chance
-	// For now, using DefaultTroopCritChance from shared constants for all troops.
-	// This could be made troop-specific later by fetching crit chance from troop.Spec if available.
-	damage, didCrit := CalculateDamageEnhanced(troop.CurrentATK, targetTower.CurrentDEF, float64(shared.DefaultTroopCritChance))
+	// Attack the target, plus the towers hit by the card's splash or chain
+	cardKind := "troop"
+	var hits []Hit
+	if isSpell(troop) {
+		cardKind = "spell"
+		hits = gs.resolveFireball(actingPlayer, troop, opponentPlayer, targetTower)
+	} else {
+		hits = gs.resolveAttack(actingPlayer, attacker, opponentPlayer, targetTower)
+	}
+	damage := hits[0].Damage
 
 	var critMessage string
-	if didCrit {
+	if hits[0].Critical {
 		critMessage = " (CRITICAL HIT!)"
 	}
 
-	// Apply damage to target tower
-	targetTower.CurrentHP -= damage
-
-	// Check if tower is destroyed
-	towerDestroyed := false
-	var destructionMessage string // To store the base destruction message
-	if targetTower.CurrentHP <= 0 {
-		targetTower.CurrentHP = 0
-		targetTower.Destroyed = true
+	// Award EXP for every destroyed tower, and find the first one that decides the match;
+	// in overtime, the first tower destroyed wins. Buildings award their EXP as they are destroyed.
+	towerDestroyed, buildingDestroyed := false, false
+	var decisiveTower *TowerInstance
+	for _, hit := range hits {
+		if !hit.Destroyed {
+			continue
+		}
+		if hit.Building {
+			buildingDestroyed = true
+			continue
+		}
+		tower := opponentPlayer.Tower(hit.TowerID)
 		towerDestroyed = true
-		gs.GameState.LastDestroyedTowerID = targetTowerID
-
-		// Award EXP for destroying the tower
-		actingPlayer.CurrentEXP += targetTower.Spec.DestroyEXP
-		levelUpMessage := gs.HandleExperienceAndLevelUp(actingPlayer)
+		gs.GameState.LastDestroyedTowerID = hit.TowerID
+		actingPlayer.CurrentEXP += rules.TowerEXP(tower)
+		if decisiveTower == nil && (gs.GameState.InOvertime() || rules.IsDecisive(opponentPlayer, tower)) {
+			decisiveTower = tower
+		}
+	}
 
-		destructionMessage = fmt.Sprintf("%s's troop %s dealt %d damage%s to %s and destroyed it!",
-			actingPlayer.Username, troopName, damage, critMessage, targetTowerID)
-		if levelUpMessage != "" {
-			destructionMessage += " " + levelUpMessage
+	var actionMessage string
+	if hits[0].Destroyed {
+		actionMessage = fmt.Sprintf("%s's %s %s dealt %d damage%s%s to %s and destroyed it!",
+			actingPlayer.Username, cardKind, troopName, damage, rageMessage, critMessage, targetTowerID)
+	} else {
+		actionMessage = fmt.Sprintf("%s's %s %s dealt %d damage%s%s to %s (HP remaining: %d).",
+			actingPlayer.Username, cardKind, troopName, damage, rageMessage, critMessage, targetTowerID, targetTower.CurrentHP)
+	}
+	actionMessage += describeHits(hits)
+	if towerDestroyed || buildingDestroyed {
+		if levelUpMessage := gs.HandleExperienceAndLevelUp(actingPlayer); levelUpMessage != "" {
+			actionMessage += " " + levelUpMessage
 		}
 	}
 
@@ -271,29 +357,66 @@ func (gs *GameSession) DeployTroop(playerUsername, troopName, targetTowerID stri
 	gs.replenishTroopForPlayer(actingPlayer)
 
 	// Check win condition
-	if targetTower == opponentPlayer.KingTower && targetTower.Destroyed {
+	if decisiveTower != nil {
+		gs.GameState.EndReason = rules.EndReason(opponentPlayer, decisiveTower)
+		if gs.GameState.InOvertime() {
+			gs.GameState.EndReason = fmt.Sprintf("Sudden death: %s destroyed", decisiveTower.Spec.Name)
+		}
 		gameOverMsg := gs.HandleGameOver(actingPlayer.Username, false) // false because it's not a draw
-		return destructionMessage + " " + gameOverMsg, true
+		gs.GameState.LastHits = hits
+		return actionMessage + " " + gameOverMsg, true
 	}
+	gs.GameState.LastHits = hits
 
-	// If a tower was destroyed, the player can continue attacking
-	if towerDestroyed {
+	// If a tower was destroyed, the player may be allowed to continue attacking
+	if towerDestroyed && rules.BonusAttackOnDestroy() {
 		gs.GameState.CanContinueAttacking = true
-		return fmt.Sprintf("%s You can attack again.", destructionMessage), true
+		return fmt.Sprintf("%s You can attack again.", actionMessage), true
 	}
 
 	// If not continuing attack, switch turn
 	if !gs.GameState.CanContinueAttacking {
-		gs.GameState.SwitchTurn()
+		actionMessage += gs.switchTurn()
 	} else {
 		// Player was continuing an attack, but didn't destroy another tower.
 		// Their bonus turn ends now.
 		gs.GameState.CanContinueAttacking = false
-		gs.GameState.SwitchTurn()
+		actionMessage += gs.switchTurn()
 	}
 
-	return fmt.Sprintf("%s's troop %s dealt %d damage%s to %s (HP remaining: %d).",
-		actingPlayer.Username, troopName, damage, critMessage, targetTowerID, targetTower.CurrentHP), true
+	return actionMessage, true
+}
+
+// deployAgainstBuilding resolves a troop attacking an enemy building, either as its target or
+// because the building intercepted an attack on a tower. It ends the turn. rageMessage marks a
+// troop boosted by rage, empty otherwise.
+func (gs *GameSession) deployAgainstBuilding(actingPlayer, opponentPlayer *Player, troop *TroopInstance, troopIndex int, building *BuildingInstance, interceptedTower *TowerInstance, rageMessage string) string {
+	damage, critical := gs.GameState.Rules.Damage(troop, building.CurrentDEF)
+	var critMessage string
+	if critical {
+		critMessage = " (CRITICAL HIT!)"
+	}
+	hit := gs.attackBuilding(actingPlayer, opponentPlayer, building, damage, critical)
+
+	actionMessage := fmt.Sprintf("%s's troop %s%s%s %s", actingPlayer.Username, troop.Spec.Name, rageMessage, critMessage, describeBuildingHit(hit))
+	if interceptedTower != nil {
+		actionMessage = fmt.Sprintf("%s intercepted %s's troop %s aimed at %s. %s%s%s %s", building.ID,
+			actingPlayer.Username, troop.Spec.Name, interceptedTower.ID, troop.Spec.Name, rageMessage, critMessage, describeBuildingHit(hit))
+	}
+	if hit.Destroyed {
+		if levelUpMessage := gs.HandleExperienceAndLevelUp(actingPlayer); levelUpMessage != "" {
+			actionMessage += " " + levelUpMessage
+		}
+	}
+
+	// Remove the troop from the player's hand after use, and replenish one troop
+	actingPlayer.Troops = append(actingPlayer.Troops[:troopIndex], actingPlayer.Troops[troopIndex+1:]...)
+	gs.replenishTroopForPlayer(actingPlayer)
+	gs.GameState.LastHits = []Hit{hit}
+
+	// Attacking a building ends the turn, bonus attack or not
+	gs.GameState.CanContinueAttacking = false
+	return actionMessage + gs.switchTurn()
 }
 
 // SkipTurn handles a player skipping their turn, granting them bonus mana.
@@ -305,12 +428,8 @@ func (gs *GameSession) SkipTurn(playerUsername string) (string, bool) {
 	}
 
 	// Get the player who is skipping the turn
-	var actingPlayer *Player
-	if playerUsername == gs.GameState.PlayerA.Username {
-		actingPlayer = gs.GameState.PlayerA
-	} else if playerUsername == gs.GameState.PlayerB.Username {
-		actingPlayer = gs.GameState.PlayerB
-	} else {
+	actingPlayer := gs.GameState.Player(playerUsername)
+	if actingPlayer == nil {
 		return "Invalid player username.", false
 	}
 
@@ -319,32 +438,29 @@ func (gs *GameSession) SkipTurn(playerUsername string) (string, bool) {
 		return "It's not your turn.", false
 	}
 
-	// Calculate mana gain for skipping (1.5x ManaRegenRate)
-	// Integer arithmetic: ManaRegenRate + ManaRegenRate / 2
-	manaGainOnSkip := shared.ManaRegenRate + (shared.ManaRegenRate / 2)
-
-	oldMana := actingPlayer.CurrentMana
-	actingPlayer.CurrentMana += manaGainOnSkip
-	if actingPlayer.CurrentMana > shared.MaxMana {
-		actingPlayer.CurrentMana = shared.MaxMana
+	// Skipping grants the mana economy's skip bonus (1.5x the regen rate with a mana pool)
+	rules := gs.GameState.Rules
+	skipMessage := fmt.Sprintf("%s skipped their turn.", actingPlayer.Username)
+	if rules.UsesMana() {
+		gainedMana := actingPlayer.GainMana(rules.SkipRegen(gs.GameState.InOvertime()), rules.MaxMana())
+		skipMessage = fmt.Sprintf("%s skipped their turn and gained %d mana.", actingPlayer.Username, gainedMana)
 	}
-	gainedMana := actingPlayer.CurrentMana - oldMana
-
-	// Prepare message
-	skipMessage := fmt.Sprintf("%s skipped their turn and gained %d mana.", actingPlayer.Username, gainedMana)
-	log.Printf(skipMessage) // Server-side log
+	log.Print(skipMessage) // Server-side log
 
-	// Switch turn to the other player.
+	// Switch turn to the next player.
 	// The SwitchTurn() method in state.go will handle giving the *next* player their normal ManaRegenRate.
-	gs.GameState.SwitchTurn()
+	skipMessage += gs.switchTurn()
 	gs.GameState.LastActionLog = skipMessage // Update last action for client display
+	gs.GameState.LastHits = nil
 
 	return skipMessage, true
 }
 
 // HandleGameOver processes end-of-game logic, including EXP awards and saving player data.
+// In 2v2, winnerUsername stands for their whole side: both of its players receive the win EXP.
 func (gs *GameSession) HandleGameOver(winnerUsername string, isDraw bool) string {
 	gs.GameState.IsGameOver = true
+	gs.GameState.LastHits = nil // A deploy that ends the match records its hits afterwards
 	finalMessage := ""
 
 	playerA := gs.GameState.PlayerA
@@ -353,45 +469,49 @@ func (gs *GameSession) HandleGameOver(winnerUsername string, isDraw bool) string
 	if isDraw {
 		gs.GameState.Winner = "DRAW"
 		finalMessage = "The game is a DRAW!"
-		log.Printf("Game ended in a draw between %s and %s.", playerA.Username, playerB.Username)
+		log.Printf("Game ended in a draw between %s and %s.", gs.GameState.TeamName(playerA), gs.GameState.TeamName(playerB))
 
-		// Award draw EXP to both players
-		playerA.CurrentEXP += shared.DrawEXPReward
-		playerB.CurrentEXP += shared.DrawEXPReward
-		levelUpMsgA := gs.HandleExperienceAndLevelUp(playerA) // This also saves data
-		levelUpMsgB := gs.HandleExperienceAndLevelUp(playerB) // This also saves data
+		// Update skill ratings before saving
+		finalMessage += "\n" + gs.UpdateRatings("", true)
 
-		if levelUpMsgA != "" {
-			finalMessage += "\nPlayer A: " + levelUpMsgA
-		}
-		if levelUpMsgB != "" {
-			finalMessage += "\nPlayer B: " + levelUpMsgB
+		// Award draw EXP and card copies to every player
+		for _, player := range gs.GameState.TurnOrder {
+			if cardMsg := gs.awardCards(player, shared.LossCardCopies); cardMsg != "" {
+				finalMessage += "\n" + cardMsg
+			}
+			player.CurrentEXP += gs.GameState.Rules.DrawEXP()
+			if levelUpMsg := gs.HandleExperienceAndLevelUp(player); levelUpMsg != "" { // This also saves data
+				finalMessage += "\n" + levelUpMsg
+			}
 		}
 
 	} else {
 		gs.GameState.Winner = winnerUsername
-		var winningPlayer *Player
-		var losingPlayer *Player // For potential future use, e.g. different save logic
-
-		if winnerUsername == playerA.Username {
-			winningPlayer = playerA
-			losingPlayer = playerB
-		} else {
-			winningPlayer = playerB
-			losingPlayer = playerA
-		}
+		winningSide := gs.GameState.Side(winnerUsername)
+		losingSide := gs.GameState.OpposingSide(winnerUsername)
 
-		finalMessage = fmt.Sprintf("Game Over! Winner: %s!", winnerUsername)
-		log.Printf("Game ended. Winner: %s. Loser: %s.", winningPlayer.Username, losingPlayer.Username)
+		finalMessage = fmt.Sprintf("Game Over! Winner: %s!", gs.GameState.TeamName(winningSide))
+		log.Printf("Game ended. Winner: %s. Loser: %s.", gs.GameState.TeamName(winningSide), gs.GameState.TeamName(losingSide))
 
-		// Award win EXP to the winner
-		winningPlayer.CurrentEXP += shared.WinEXPReward
-		levelUpMsgWinner := gs.HandleExperienceAndLevelUp(winningPlayer) // Saves winner's data
-		// Save losing player's data as well (they might have gained EXP from destroying units)
-		_ = gs.HandleExperienceAndLevelUp(losingPlayer) // We call this to ensure loser's data (like EXP from units) is saved.
+		// Update skill ratings before saving
+		finalMessage += "\n" + gs.UpdateRatings(winnerUsername, false)
 
-		if levelUpMsgWinner != "" {
-			finalMessage += "\n" + levelUpMsgWinner
+		// Award win EXP and card copies to the winners
+		for _, winningPlayer := range gs.GameState.Team(winningSide) {
+			if cardMsg := gs.awardCards(winningPlayer, shared.WinCardCopies); cardMsg != "" {
+				finalMessage += "\n" + cardMsg
+			}
+			winningPlayer.CurrentEXP += gs.GameState.Rules.WinEXP()
+			if levelUpMsg := gs.HandleExperienceAndLevelUp(winningPlayer); levelUpMsg != "" { // Saves winner's data
+				finalMessage += "\n" + levelUpMsg
+			}
+		}
+		// Losers receive fewer card copies. Save their data as well (they might have gained EXP from destroying units)
+		for _, losingPlayer := range gs.GameState.Team(losingSide) {
+			if cardMsg := gs.awardCards(losingPlayer, shared.LossCardCopies); cardMsg != "" {
+				finalMessage += "\n" + cardMsg
+			}
+			_ = gs.HandleExperienceAndLevelUp(losingPlayer)
 		}
 	}
 
@@ -400,9 +520,197 @@ func (gs *GameSession) HandleGameOver(winnerUsername string, isDraw bool) string
 	return finalMessage
 }
 
+// HandleForfeit ends the game in favour of the opponent of the forfeiting player (e.g. on disconnect).
+// In 2v2 the forfeiting player's whole side loses. No match EXP is awarded, but ratings are updated
+// and every player's data is saved.
+func (gs *GameSession) HandleForfeit(forfeitingUsername string) string {
+	if gs.GameState.IsGameOver {
+		return "Game is already over."
+	}
+
+	winnerUsername := gs.GameState.PlayerA.Username
+	if gs.GameState.Side(forfeitingUsername) == gs.GameState.PlayerA {
+		winnerUsername = gs.GameState.PlayerB.Username
+	}
+	winnerName := gs.GameState.TeamName(gs.GameState.Side(winnerUsername))
+
+	gs.GameState.SetWinner(winnerUsername)
+	gs.GameState.EndReason = fmt.Sprintf("%s forfeited", forfeitingUsername)
+	log.Printf("%s forfeited. Winner: %s.", forfeitingUsername, winnerName)
+
+	ratingMessage := gs.UpdateRatings(winnerUsername, false)
+
+	// Save every player (EXP earned from destroying units during the match is kept)
+	for _, player := range gs.GameState.TurnOrder {
+		_ = gs.HandleExperienceAndLevelUp(player)
+	}
+
+	return fmt.Sprintf("%s forfeited. Winner: %s!\n%s", forfeitingUsername, winnerName, ratingMessage)
+}
+
+// HandleNoContest ends the game without a result (e.g. when the server shuts down).
+// Ratings are left unchanged and no match EXP is awarded, but every player's data is saved.
+func (gs *GameSession) HandleNoContest(reason string) string {
+	if gs.GameState.IsGameOver {
+		return "Game is already over."
+	}
+
+	gs.GameState.SetWinner("")
+	gs.GameState.EndReason = reason
+	log.Printf("Game between %s and %s ended without a result: %s.", gs.GameState.TeamName(gs.GameState.PlayerA), gs.GameState.TeamName(gs.GameState.PlayerB), reason)
+
+	// Save every player (EXP earned from destroying units during the match is kept)
+	for _, player := range gs.GameState.TurnOrder {
+		_ = gs.HandleExperienceAndLevelUp(player)
+	}
+
+	return fmt.Sprintf("No contest: %s", reason)
+}
+
+// opponentOf returns the opponent of the given player (in 2v2, the owner of the opposing side's
+// towers), or nil if the username is not in this game
+func (gs *GameSession) opponentOf(username string) *Player {
+	return gs.GameState.OpposingSide(username)
+}
+
+// Surrender ends the game in favour of the surrendering player's opponent.
+// Unlike a forfeit, the game ends normally: the winner receives the win EXP.
+// In 2v2 a player surrenders for their whole side.
+func (gs *GameSession) Surrender(playerUsername string) (string, bool) {
+	if gs.GameState.IsGameOver {
+		return "Game is already over.", false
+	}
+
+	opponent := gs.opponentOf(playerUsername)
+	if opponent == nil {
+		return "Invalid player username.", false
+	}
+
+	gs.GameState.EndReason = fmt.Sprintf("%s surrendered", playerUsername)
+	gameOverMsg := gs.HandleGameOver(opponent.Username, false)
+	gs.GameState.LastActionLog = fmt.Sprintf("%s surrendered.", playerUsername)
+
+	return fmt.Sprintf("%s surrendered. %s", playerUsername, gameOverMsg), true
+}
+
+// OfferDraw records a draw offer from a player. The opponent may accept it until the
+// offering player's next turn begins. In 2v2 the offer is made for the whole side,
+// and either opponent may answer it.
+func (gs *GameSession) OfferDraw(playerUsername string) (string, bool) {
+	if gs.GameState.IsGameOver {
+		return "Game is already over.", false
+	}
+
+	opponent := gs.opponentOf(playerUsername)
+	if opponent == nil {
+		return "Invalid player username.", false
+	}
+	offeredBy := gs.GameState.DrawOfferedBy
+	if offeredBy == playerUsername {
+		return "You already offered a draw.", false
+	}
+	if offeredBy != "" && gs.GameState.SameSide(offeredBy, playerUsername) {
+		return fmt.Sprintf("%s already offered a draw for your team.", offeredBy), false
+	}
+	if offeredBy != "" {
+		return fmt.Sprintf("%s already offered a draw. Accept it instead.", offeredBy), false
+	}
+
+	gs.GameState.DrawOfferedBy = playerUsername
+	log.Printf("%s offered a draw to %s.", playerUsername, gs.GameState.TeamName(opponent))
+
+	return fmt.Sprintf("%s offered a draw.", playerUsername), true
+}
+
+// AcceptDraw ends the game as a draw if the opponent has a pending draw offer.
+// Every player receives the draw EXP.
+func (gs *GameSession) AcceptDraw(playerUsername string) (string, bool) {
+	if gs.GameState.IsGameOver {
+		return "Game is already over.", false
+	}
+
+	if !gs.hasDrawOfferFor(playerUsername) {
+		return "There is no draw offer to accept.", false
+	}
+
+	gs.GameState.DrawOfferedBy = ""
+	gs.GameState.EndReason = "Draw agreed"
+	gameOverMsg := gs.HandleGameOver("", true)
+	gs.GameState.LastActionLog = fmt.Sprintf("%s accepted the draw offer.", playerUsername)
+
+	return fmt.Sprintf("%s accepted the draw offer. %s", playerUsername, gameOverMsg), true
+}
+
+// DeclineDraw rejects the opponent's pending draw offer
+func (gs *GameSession) DeclineDraw(playerUsername string) (string, bool) {
+	if gs.GameState.IsGameOver {
+		return "Game is already over.", false
+	}
+
+	if !gs.hasDrawOfferFor(playerUsername) {
+		return "There is no draw offer to decline.", false
+	}
+
+	gs.GameState.DrawOfferedBy = ""
+	return fmt.Sprintf("%s declined the draw offer.", playerUsername), true
+}
+
+// hasDrawOfferFor checks if the opposing side of a player has a pending draw offer
+func (gs *GameSession) hasDrawOfferFor(playerUsername string) bool {
+	offeredBy := gs.GameState.DrawOfferedBy
+	return offeredBy != "" && gs.GameState.OpposingSide(offeredBy) == gs.GameState.Side(playerUsername)
+}
+
+// UpdateRatings applies the Elo rating change for a finished match to both players. In 2v2 the
+// change is worked out between the teams' average ratings and applied to both players of each team.
+// winnerUsername is ignored when isDraw is true. The caller is responsible for saving player data.
+func (gs *GameSession) UpdateRatings(winnerUsername string, isDraw bool) string {
+	teamA := gs.GameState.Team(gs.GameState.PlayerA)
+	teamB := gs.GameState.Team(gs.GameState.PlayerB)
+
+	// Score from side A's point of view
+	scoreA := 0.0
+	if isDraw {
+		scoreA = 0.5
+	} else if gs.GameState.Side(winnerUsername) == gs.GameState.PlayerA {
+		scoreA = 1.0
+	}
+
+	ratingA, ratingB := averageRating(teamA), averageRating(teamB)
+	newRatingA, _ := shared.CalculateEloRatings(ratingA, ratingB, scoreA)
+	delta := newRatingA - ratingA
+
+	ratingMessage := "Ratings:"
+	for i, player := range gs.GameState.TurnOrder {
+		change := delta
+		if gs.GameState.Side(player.Username) == gs.GameState.PlayerB {
+			change = -delta
+		}
+		player.Rating += change
+
+		if i > 0 {
+			ratingMessage += ","
+		}
+		ratingMessage += fmt.Sprintf(" %s %d (%+d)", player.Username, player.Rating, change)
+	}
+	log.Println(ratingMessage) // Server-side log
+
+	return ratingMessage
+}
+
+// averageRating returns the average skill rating of a team
+func averageRating(team []*Player) int {
+	total := 0
+	for _, player := range team {
+		total += player.Rating
+	}
+	return total / len(team)
+}
+
 // HandleExperienceAndLevelUp checks for player level up and updates stats accordingly.
 // It returns a message if the player leveled up, otherwise an empty string.
 func (gs *GameSession) HandleExperienceAndLevelUp(player *Player) string {
+	oldLevel := player.Level
 	leveledUp := false
 	levelUpMessage := ""
 	for player.CurrentEXP >= player.RequiredEXPForNextLevel && player.RequiredEXPForNextLevel > 0 { // Add check for > 0 to prevent infinite loop if misconfigured
@@ -417,18 +725,15 @@ func (gs *GameSession) HandleExperienceAndLevelUp(player *Player) string {
 	if leveledUp {
 		levelUpMessage = fmt.Sprintf("%s leveled up to Level %d! Next level at %d EXP.",
 			player.Username, player.Level, player.RequiredEXPForNextLevel)
+		if unlocked := gs.unlockedBetween(oldLevel, player.Level); len(unlocked) > 0 {
+			levelUpMessage += fmt.Sprintf(" Unlocked: %s!", strings.Join(unlocked, ", "))
+		}
 		log.Println(levelUpMessage) // Server-side log
 	}
 
 	// Always save player data after EXP change (level up or not)
 	if gs.JSONHandler != nil {
-		playerProfile := storage.PlayerProfile{
-			Username:                player.Username,
-			Level:                   player.Level,
-			CurrentEXP:              player.CurrentEXP,
-			RequiredEXPForNextLevel: player.RequiredEXPForNextLevel,
-		}
-		if err := gs.JSONHandler.SavePlayerData(playerProfile); err != nil {
+		if err := gs.JSONHandler.SavePlayerData(player.ToProfile()); err != nil {
 			log.Printf("Error saving player data for %s after EXP update: %v", player.Username, err)
 		}
 	} else {
@@ -450,10 +755,11 @@ func (gs *GameSession) replenishTroopForPlayer(player *Player) {
 		handTroopNames[troopInstance.Spec.Name] = true
 	}
 
-	// Find available troop specs not currently in hand
-	availableToReplenish := make([]models.TroopSpec, 0)
-	for _, spec := range gs.TroopSpecs {
-		if !spec.IsSpecialOnly { // Typically replenish with regular troops
+	// Find available troop specs of the player's deck not currently in hand
+	deck := gs.deckOf(player)
+	availableToReplenish := make([]*models.TroopSpec, 0)
+	for _, spec := range deck {
+		if spec.Card() != shared.CardSpell { // Typically replenish with regular troops and buildings
 			if !handTroopNames[spec.Name] {
 				availableToReplenish = append(availableToReplenish, spec)
 			}
@@ -466,7 +772,7 @@ func (gs *GameSession) replenishTroopForPlayer(player *Player) {
 	if len(availableToReplenish) == 0 {
 		// Fallback: if no distinct regular troops, try adding any troop not in hand (including special)
 		// This part can be adjusted based on desired game mechanics for replenishment limits.
-		for _, spec := range gs.TroopSpecs {
+		for _, spec := range deck {
 			if !handTroopNames[spec.Name] {
 				availableToReplenish = append(availableToReplenish, spec)
 			}
@@ -482,7 +788,7 @@ func (gs *GameSession) replenishTroopForPlayer(player *Player) {
 	newTroopSpec := availableToReplenish[randIndex]
 
 	// Add the new troop to the player's hand
-	newTroopInstance := NewTroopInstance(&newTroopSpec, fmt.Sprintf("%s_troop_%d", player.Username, len(player.Troops)+1), player.Level)
+	newTroopInstance := NewTroopInstance(newTroopSpec, fmt.Sprintf("%s_troop_%d", player.Username, len(player.Troops)+1), player.CardLevel(newTroopSpec.Name))
 	player.Troops = append(player.Troops, newTroopInstance)
 
 	// It might be good to send a message to the client that a troop has been replenished.
@@ -500,40 +806,23 @@ func (gs *GameSession) GetGameStateInfo() string {
 
 	info := fmt.Sprintf("Current Turn: %s\n\n", gs.GameState.CurrentTurn)
 
-	// Player A info
-	info += fmt.Sprintf("Player A (%s):\n", playerA.Username)
-	info += fmt.Sprintf("  King Tower: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerA.KingTower.CurrentHP, playerA.KingTower.Spec.BaseHP,
-		playerA.KingTower.CurrentATK, playerA.KingTower.CurrentDEF, playerA.KingTower.Destroyed)
-	info += fmt.Sprintf("  Guard Tower 1: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerA.GuardTower1.CurrentHP, playerA.GuardTower1.Spec.BaseHP,
-		playerA.GuardTower1.CurrentATK, playerA.GuardTower1.CurrentDEF, playerA.GuardTower1.Destroyed)
-	info += fmt.Sprintf("  Guard Tower 2: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerA.GuardTower2.CurrentHP, playerA.GuardTower2.Spec.BaseHP,
-		playerA.GuardTower2.CurrentATK, playerA.GuardTower2.CurrentDEF, playerA.GuardTower2.Destroyed)
-
-	info += "  Available Troops:\n"
-	for _, troop := range playerA.Troops {
-		info += fmt.Sprintf("    %s: HP=%d, ATK=%d, DEF=%d\n",
-			troop.Spec.Name, troop.CurrentHP, troop.CurrentATK, troop.CurrentDEF)
-	}
-
-	// Player B info
-	info += fmt.Sprintf("\nPlayer B (%s):\n", playerB.Username)
-	info += fmt.Sprintf("  King Tower: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerB.KingTower.CurrentHP, playerB.KingTower.Spec.BaseHP,
-		playerB.KingTower.CurrentATK, playerB.KingTower.CurrentDEF, playerB.KingTower.Destroyed)
-	info += fmt.Sprintf("  Guard Tower 1: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerB.GuardTower1.CurrentHP, playerB.GuardTower1.Spec.BaseHP,
-		playerB.GuardTower1.CurrentATK, playerB.GuardTower1.CurrentDEF, playerB.GuardTower1.Destroyed)
-	info += fmt.Sprintf("  Guard Tower 2: HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
-		playerB.GuardTower2.CurrentHP, playerB.GuardTower2.Spec.BaseHP,
-		playerB.GuardTower2.CurrentATK, playerB.GuardTower2.CurrentDEF, playerB.GuardTower2.Destroyed)
-
-	info += "  Available Troops:\n"
-	for _, troop := range playerB.Troops {
-		info += fmt.Sprintf("    %s: HP=%d, ATK=%d, DEF=%d\n",
-			troop.Spec.Name, troop.CurrentHP, troop.CurrentATK, troop.CurrentDEF)
+	for _, player := range []struct {
+		label  string
+		player *Player
+	}{{"Player A", playerA}, {"Player B", playerB}} {
+		info += fmt.Sprintf("%s (%s):\n", player.label, player.player.Username)
+		for _, tower := range player.player.Towers {
+			info += fmt.Sprintf("  %s (ID: %s): HP=%d/%d, ATK=%d, DEF=%d, Destroyed=%v\n",
+				tower.Spec.Name, tower.ID, tower.CurrentHP, tower.MaxHP,
+				tower.CurrentATK, tower.CurrentDEF, tower.Destroyed)
+		}
+
+		info += "  Available Troops:\n"
+		for _, troop := range player.player.Troops {
+			info += fmt.Sprintf("    %s: HP=%d, ATK=%d, DEF=%d\n",
+				troop.Spec.Name, troop.CurrentHP, troop.CurrentATK, troop.CurrentDEF)
+		}
+		info += "\n"
 	}
 
 	return info
@@ -0,0 +1,247 @@
+package game
+
+import (
+	"strings"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+	"testing"
+)
+
+// newTestSession creates a game between alice and bob with small in-memory specs
+func newTestSession(t *testing.T) *GameSession {
+	t.Helper()
+
+	troopSpecs := []models.TroopSpec{
+		{Name: "Pawn", BaseHP: 50, BaseATK: 50, BaseDEF: 0, ManaCost: 2},
+		{Name: "Bishop", BaseHP: 100, BaseATK: 100, BaseDEF: 0, ManaCost: 4},
+		{Name: "Rook", BaseHP: 250, BaseATK: 200, BaseDEF: 0, ManaCost: 5},
+	}
+	towerSpecs := []models.TowerSpec{
+		{Name: "King Tower", Type: "KING", Role: shared.KingTowerRole, Requires: []string{"LEFT", "RIGHT"}, BaseHP: 2000, BaseATK: 500, BaseDEF: 300},
+		{Name: "Left Guard", Type: "LEFT", Role: shared.GuardTowerRole, BaseHP: 1000, BaseATK: 300, BaseDEF: 100},
+		{Name: "Right Guard", Type: "RIGHT", Role: shared.GuardTowerRole, BaseHP: 1000, BaseATK: 300, BaseDEF: 100},
+	}
+	jsonHandler := storage.NewJSONHandler("", t.TempDir())
+
+	return NewGameSession("alice", "bob", troopSpecs, towerSpecs, jsonHandler)
+}
+
+// newTestTeamSession creates a 2v2 game of alice and carol against bob and dave
+func newTestTeamSession(t *testing.T) *GameSession {
+	t.Helper()
+
+	gs := newTestSession(t)
+	settings := gs.Settings
+	settings.TeamSize = shared.TeamMatchSize
+	return NewTeamGameSession([2]string{"alice", "carol"}, [2]string{"bob", "dave"},
+		gs.TroopSpecs, gs.TowerSpecs, gs.JSONHandler, settings, gs.GameState.Rules)
+}
+
+func TestSurrender(t *testing.T) {
+	tests := []struct {
+		name       string
+		player     string
+		gameOver   bool
+		wantOK     bool
+		wantWinner string
+	}{
+		{name: "player surrenders", player: "alice", wantOK: true, wantWinner: "bob"},
+		{name: "opponent surrenders", player: "bob", wantOK: true, wantWinner: "alice"},
+		{name: "not in the game", player: "carol", wantOK: false},
+		{name: "game already over", player: "alice", gameOver: true, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestSession(t)
+			gs.GameState.IsGameOver = tt.gameOver
+
+			msg, ok := gs.Surrender(tt.player)
+			if ok != tt.wantOK {
+				t.Fatalf("Surrender(%q) ok = %v, want %v (%s)", tt.player, ok, tt.wantOK, msg)
+			}
+			if !ok {
+				return
+			}
+			if !gs.GameState.IsGameOver {
+				t.Error("game is not over after surrender")
+			}
+			if gs.GameState.Winner != tt.wantWinner {
+				t.Errorf("Winner = %q, want %q", gs.GameState.Winner, tt.wantWinner)
+			}
+			if !strings.Contains(gs.GameState.EndReason, "surrendered") {
+				t.Errorf("EndReason = %q, want a surrender reason", gs.GameState.EndReason)
+			}
+		})
+	}
+}
+
+func TestDrawOffers(t *testing.T) {
+	type step struct {
+		action string // "offer", "accept" or "decline"
+		player string
+		wantOK bool
+	}
+
+	tests := []struct {
+		name          string
+		steps         []step
+		wantGameOver  bool
+		wantOfferedBy string
+	}{
+		{
+			name:          "offer stays pending",
+			steps:         []step{{"offer", "alice", true}},
+			wantOfferedBy: "alice",
+		},
+		{
+			name:         "offer accepted",
+			steps:        []step{{"offer", "alice", true}, {"accept", "bob", true}},
+			wantGameOver: true,
+		},
+		{
+			name:  "offer declined",
+			steps: []step{{"offer", "alice", true}, {"decline", "bob", true}},
+		},
+		{
+			name:          "offer twice",
+			steps:         []step{{"offer", "alice", true}, {"offer", "alice", false}},
+			wantOfferedBy: "alice",
+		},
+		{
+			name:          "counter offer",
+			steps:         []step{{"offer", "alice", true}, {"offer", "bob", false}},
+			wantOfferedBy: "alice",
+		},
+		{
+			name:          "accept own offer",
+			steps:         []step{{"offer", "alice", true}, {"accept", "alice", false}},
+			wantOfferedBy: "alice",
+		},
+		{
+			name:  "accept without offer",
+			steps: []step{{"accept", "bob", false}},
+		},
+		{
+			name:  "decline without offer",
+			steps: []step{{"decline", "bob", false}},
+		},
+		{
+			name:  "offer from outsider",
+			steps: []step{{"offer", "carol", false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestSession(t)
+
+			for _, s := range tt.steps {
+				var msg string
+				var ok bool
+				switch s.action {
+				case "offer":
+					msg, ok = gs.OfferDraw(s.player)
+				case "accept":
+					msg, ok = gs.AcceptDraw(s.player)
+				case "decline":
+					msg, ok = gs.DeclineDraw(s.player)
+				}
+				if ok != s.wantOK {
+					t.Fatalf("%s by %s ok = %v, want %v (%s)", s.action, s.player, ok, s.wantOK, msg)
+				}
+			}
+
+			if gs.GameState.IsGameOver != tt.wantGameOver {
+				t.Errorf("IsGameOver = %v, want %v", gs.GameState.IsGameOver, tt.wantGameOver)
+			}
+			if gs.GameState.DrawOfferedBy != tt.wantOfferedBy {
+				t.Errorf("DrawOfferedBy = %q, want %q", gs.GameState.DrawOfferedBy, tt.wantOfferedBy)
+			}
+			if tt.wantGameOver && gs.GameState.Winner != "DRAW" {
+				t.Errorf("Winner = %q, want DRAW", gs.GameState.Winner)
+			}
+		})
+	}
+}
+
+func TestHandleNoContest(t *testing.T) {
+	gs := newTestSession(t)
+	ratingA := gs.GameState.PlayerA.Rating
+	ratingB := gs.GameState.PlayerB.Rating
+
+	gs.HandleNoContest("Server shutting down")
+
+	if !gs.GameState.IsGameOver {
+		t.Fatal("game is not over after a no contest")
+	}
+	if gs.GameState.Winner != "" {
+		t.Errorf("Winner = %q, want none", gs.GameState.Winner)
+	}
+	if gs.GameState.EndReason != "Server shutting down" {
+		t.Errorf("EndReason = %q, want %q", gs.GameState.EndReason, "Server shutting down")
+	}
+	if gs.GameState.PlayerA.Rating != ratingA || gs.GameState.PlayerB.Rating != ratingB {
+		t.Errorf("ratings changed to %d/%d, want %d/%d", gs.GameState.PlayerA.Rating, gs.GameState.PlayerB.Rating, ratingA, ratingB)
+	}
+
+	if msg := gs.HandleNoContest("again"); msg != "Game is already over." {
+		t.Errorf("second HandleNoContest = %q, want the game to be over already", msg)
+	}
+}
+
+func TestTeamEndgame(t *testing.T) {
+	t.Run("partner surrenders for the team", func(t *testing.T) {
+		gs := newTestTeamSession(t)
+		if msg, ok := gs.Surrender("carol"); !ok {
+			t.Fatalf("Surrender(carol) failed: %s", msg)
+		}
+		if !gs.GameState.IsGameOver || gs.GameState.Winner != "bob" {
+			t.Errorf("Winner = %q (game over %v), want bob's team", gs.GameState.Winner, gs.GameState.IsGameOver)
+		}
+	})
+
+	t.Run("either opponent answers a draw offer", func(t *testing.T) {
+		gs := newTestTeamSession(t)
+		if msg, ok := gs.OfferDraw("carol"); !ok {
+			t.Fatalf("OfferDraw(carol) failed: %s", msg)
+		}
+		if _, ok := gs.AcceptDraw("alice"); ok {
+			t.Fatal("alice accepted her own team's draw offer")
+		}
+		if msg, ok := gs.AcceptDraw("dave"); !ok {
+			t.Fatalf("AcceptDraw(dave) failed: %s", msg)
+		}
+		if !gs.GameState.IsGameOver || gs.GameState.Winner != "DRAW" {
+			t.Errorf("Winner = %q (game over %v), want a draw", gs.GameState.Winner, gs.GameState.IsGameOver)
+		}
+	})
+}
+
+func TestDeployTurn(t *testing.T) {
+	tests := []struct {
+		name           string
+		player         string
+		continueAttack bool
+		wantOK         bool
+	}{
+		{"player whose turn it is", "alice", false, true},
+		{"opponent", "bob", false, false},
+		{"opponent during a bonus attack", "bob", true, false},
+		{"player with a bonus attack", "alice", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestSession(t)
+			gs.GameState.CanContinueAttacking = tt.continueAttack
+			player := gs.GameState.Player(tt.player)
+			player.CurrentMana = 20
+
+			msg, ok := gs.DeployTroop(tt.player, player.Troops[0].Spec.Name, "")
+			if ok != tt.wantOK {
+				t.Errorf("DeployTroop() by %s ok = %v, want %v (%s)", tt.player, ok, tt.wantOK, msg)
+			}
+		})
+	}
+}
@@ -1,23 +1,35 @@
 package game
 
 import (
+	"slices"
 	"tcr/internal/models"
 	"tcr/internal/shared"
+	"tcr/internal/storage"
 )
 
 // Player represents a player in the game
 type Player struct {
-	Username    string
-	KingTower   *TowerInstance
-	GuardTower1 *TowerInstance
-	GuardTower2 *TowerInstance
-	Troops      []*TroopInstance // Available troops (hand)
+	Username  string
+	Towers    []*TowerInstance    // Towers in towers.json order
+	Troops    []*TroopInstance    // Available troops (hand)
+	Buildings []*BuildingInstance // Buildings standing on the player's side, in the order they were placed
 
 	// Enhanced TCR features
 	CurrentEXP              int
 	Level                   int
+	LevelCap                int // Highest level the player's towers and cards are scaled to (0 = no cap)
 	CurrentMana             int
 	RequiredEXPForNextLevel int
+
+	// Skill rating (Elo), updated after every match result
+	Rating int
+
+	// Card collection (see collection.go)
+	Cards map[string]storage.CardProgress // Progress per card; unlocked cards without an entry are at level 1
+	Deck  []string                        // Cards the player plays with; empty plays with every unlocked card
+
+	// Spell effects on the player
+	RagePercent int // ATK bonus of the player's next troop (RAGE)
 }
 
 // TowerInstance represents a tower instance in the game with current stats
@@ -40,6 +52,19 @@ type TroopInstance struct {
 	CurrentDEF int
 }
 
+// BuildingInstance represents a building card placed on a side of the field
+type BuildingInstance struct {
+	Spec        *models.TroopSpec
+	ID          string // e.g., "PlayerA_Cannon_7"
+	Owner       string // Username of the player who placed it
+	MaxHP       int
+	CurrentHP   int
+	CurrentATK  int
+	CurrentDEF  int
+	TurnsLeft   int // Turns of its side the building still stands
+	FrozenTurns int // Attacks the building will skip (FREEZE)
+}
+
 // NewPlayer creates a new player with initialized values
 func NewPlayer(username string) *Player {
 	return &Player{
@@ -49,7 +74,130 @@ func NewPlayer(username string) *Player {
 		CurrentEXP:              0,
 		CurrentMana:             0,
 		RequiredEXPForNextLevel: shared.BaseEXPForLevelUp,
+		Rating:                  shared.DefaultRating,
+		Cards:                   make(map[string]storage.CardProgress),
+	}
+}
+
+// ToProfile returns the persisted profile data for this player
+func (p *Player) ToProfile() storage.PlayerProfile {
+	return storage.PlayerProfile{
+		Username:                p.Username,
+		Level:                   p.Level,
+		CurrentEXP:              p.CurrentEXP,
+		RequiredEXPForNextLevel: p.RequiredEXPForNextLevel,
+		Rating:                  p.Rating,
+		Cards:                   p.Cards,
+		Deck:                    p.Deck,
+	}
+}
+
+// Tower returns the player's tower with the given ID, or nil if the player has none
+func (p *Player) Tower(id string) *TowerInstance {
+	for _, tower := range p.Towers {
+		if tower.ID == id {
+			return tower
+		}
+	}
+	return nil
+}
+
+// Building returns the building with the given ID on the player's side, or nil if there is none
+func (p *Player) Building(id string) *BuildingInstance {
+	for _, building := range p.Buildings {
+		if building.ID == id {
+			return building
+		}
+	}
+	return nil
+}
+
+// Protector returns the first building on the player's side that protects a tower, or nil if none does
+func (p *Player) Protector(tower *TowerInstance) *BuildingInstance {
+	for _, building := range p.Buildings {
+		if building.Protects(tower) {
+			return building
+		}
+	}
+	return nil
+}
+
+// removeBuilding removes a destroyed or expired building from the player's side
+func (p *Player) removeBuilding(building *BuildingInstance) {
+	p.Buildings = slices.DeleteFunc(p.Buildings, func(b *BuildingInstance) bool { return b == building })
+}
+
+// TowerOfType returns the player's tower of the given type, or nil if the player has none
+func (p *Player) TowerOfType(towerType string) *TowerInstance {
+	for _, tower := range p.Towers {
+		if tower.Spec.Type == towerType {
+			return tower
+		}
 	}
+	return nil
+}
+
+// KingTower returns the tower whose destruction loses the player the match
+func (p *Player) KingTower() *TowerInstance {
+	for _, tower := range p.Towers {
+		if tower.Spec.Role == shared.KingTowerRole {
+			return tower
+		}
+	}
+	return nil
+}
+
+// EffectiveLevel returns the level the player's towers are scaled to (cards scale with their card level):
+// the player's level, lowered to the level cap of the match if there is one
+func (p *Player) EffectiveLevel() int {
+	if p.LevelCap > 0 && p.Level > p.LevelCap {
+		return p.LevelCap
+	}
+	return p.Level
+}
+
+// DestroyedTowers returns how many of the player's towers are destroyed
+func (p *Player) DestroyedTowers() int {
+	destroyed := 0
+	for _, tower := range p.Towers {
+		if tower.Destroyed {
+			destroyed++
+		}
+	}
+	return destroyed
+}
+
+// TowerHPPercent returns how much of the total HP of the player's towers is left, in percent
+func (p *Player) TowerHPPercent() float64 {
+	currentHP, maxHP := 0, 0
+	for _, tower := range p.Towers {
+		currentHP += tower.CurrentHP
+		maxHP += tower.MaxHP
+	}
+	if maxHP == 0 {
+		return 0
+	}
+	return float64(currentHP) * 100 / float64(maxHP)
+}
+
+// GainMana adds mana up to maxMana and returns how much was actually gained
+func (p *Player) GainMana(amount, maxMana int) int {
+	oldMana := p.CurrentMana
+	p.CurrentMana += amount
+	if p.CurrentMana > maxMana {
+		p.CurrentMana = maxMana
+	}
+	return p.CurrentMana - oldMana
+}
+
+// hasGuardTowers checks if the player's layout has any guard towers
+func (p *Player) hasGuardTowers() bool {
+	for _, tower := range p.Towers {
+		if tower.Spec.Role == shared.GuardTowerRole {
+			return true
+		}
+	}
+	return false
 }
 
 // NewTowerInstance creates a new tower instance from a tower spec
@@ -68,6 +216,26 @@ func NewTowerInstance(spec *models.TowerSpec, playerUsername string, playerLevel
 	}
 }
 
+// NewBuildingInstance places a building card, keeping the card's scaled stats
+func NewBuildingInstance(card *TroopInstance, id, owner string) *BuildingInstance {
+	return &BuildingInstance{
+		Spec:       card.Spec,
+		ID:         id,
+		Owner:      owner,
+		MaxHP:      card.CurrentHP,
+		CurrentHP:  card.CurrentHP,
+		CurrentATK: card.CurrentATK,
+		CurrentDEF: card.CurrentDEF,
+		TurnsLeft:  card.Spec.Lifetime,
+	}
+}
+
+// Protects checks if a building intercepts attacks on a tower: every tower if it names none,
+// or the tower of the type it names
+func (b *BuildingInstance) Protects(tower *TowerInstance) bool {
+	return b.Spec.Protects == "" || b.Spec.Protects == tower.Spec.Type
+}
+
 // NewTroopInstance creates a new troop instance from a troop spec
 func NewTroopInstance(spec *models.TroopSpec, id string, playerLevel int) *TroopInstance {
 	levelMultiplier := 1.0 + float64(playerLevel-1)*0.1
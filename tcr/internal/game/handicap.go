@@ -0,0 +1,43 @@
+package game
+
+import "tcr/internal/shared"
+
+// Handicap is the bonus the lower-level player of a match started with
+type Handicap struct {
+	Username string
+	Kind     string // shared.HandicapMana or shared.HandicapTowerHP
+	Amount   int    // Bonus starting mana, or bonus tower HP in percent
+}
+
+// applyHandicap gives the lower-level player of a match a bonus for every effective level they
+// are behind, sized by the rule set. It returns the bonus given, or nil if the match has no
+// handicap or both players are on the same level.
+func applyHandicap(playerA, playerB *Player, kind string, rules RuleSet) *Handicap {
+	if kind == "" {
+		return nil
+	}
+
+	lower, levelGap := playerA, playerB.EffectiveLevel()-playerA.EffectiveLevel()
+	if levelGap < 0 {
+		lower, levelGap = playerB, -levelGap
+	}
+	if levelGap == 0 {
+		return nil
+	}
+
+	params := rules.Params()
+	switch kind {
+	case shared.HandicapMana:
+		bonus := lower.GainMana(params.HandicapManaPerLevel*levelGap, rules.MaxMana())
+		return &Handicap{Username: lower.Username, Kind: kind, Amount: bonus}
+
+	case shared.HandicapTowerHP:
+		percent := params.HandicapTowerHPPercentPerLevel * levelGap
+		for _, tower := range lower.Towers {
+			tower.MaxHP += tower.MaxHP * percent / 100
+			tower.CurrentHP = tower.MaxHP
+		}
+		return &Handicap{Username: lower.Username, Kind: kind, Amount: percent}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/shared"
+	"testing"
+)
+
+// newHandicapPlayer creates a player on the given level with one 1000 HP tower
+func newHandicapPlayer(username string, level, levelCap, mana int) *Player {
+	return &Player{
+		Username:    username,
+		Level:       level,
+		LevelCap:    levelCap,
+		CurrentMana: mana,
+		Towers:      []*TowerInstance{{MaxHP: 1000, CurrentHP: 1000}},
+	}
+}
+
+func TestApplyHandicap(t *testing.T) {
+	rules := config.Default().Rules.Enhanced
+	rules.HandicapManaPerLevel = 2
+	rules.HandicapTowerHPPercentPerLevel = 10
+	rules.MaxMana = 20
+	ruleSet := NewEnhancedRuleSet(rules)
+
+	tests := []struct {
+		name        string
+		kind        string
+		playerA     *Player
+		playerB     *Player
+		want        *Handicap
+		wantMana    int // Mana of the lower-level player afterwards
+		wantTowerHP int // Tower HP of the lower-level player afterwards
+	}{
+		{
+			name:    "no handicap",
+			playerA: newHandicapPlayer("a", 1, 0, 10),
+			playerB: newHandicapPlayer("b", 4, 0, 10),
+		},
+		{
+			name:    "same level",
+			kind:    shared.HandicapMana,
+			playerA: newHandicapPlayer("a", 3, 0, 10),
+			playerB: newHandicapPlayer("b", 3, 0, 10),
+		},
+		{
+			name:        "mana per level behind",
+			kind:        shared.HandicapMana,
+			playerA:     newHandicapPlayer("a", 1, 0, 10),
+			playerB:     newHandicapPlayer("b", 4, 0, 10),
+			want:        &Handicap{Username: "a", Kind: shared.HandicapMana, Amount: 6},
+			wantMana:    16,
+			wantTowerHP: 1000,
+		},
+		{
+			name:        "mana capped at the maximum",
+			kind:        shared.HandicapMana,
+			playerA:     newHandicapPlayer("a", 6, 0, 15),
+			playerB:     newHandicapPlayer("b", 1, 0, 15),
+			want:        &Handicap{Username: "b", Kind: shared.HandicapMana, Amount: 5},
+			wantMana:    20,
+			wantTowerHP: 1000,
+		},
+		{
+			name:        "tower HP per level behind",
+			kind:        shared.HandicapTowerHP,
+			playerA:     newHandicapPlayer("a", 5, 0, 10),
+			playerB:     newHandicapPlayer("b", 3, 0, 10),
+			want:        &Handicap{Username: "b", Kind: shared.HandicapTowerHP, Amount: 20},
+			wantMana:    10,
+			wantTowerHP: 1200,
+		},
+		{
+			name:    "level cap evens the players out",
+			kind:    shared.HandicapTowerHP,
+			playerA: newHandicapPlayer("a", 2, 2, 10),
+			playerB: newHandicapPlayer("b", 8, 2, 10),
+		},
+		{
+			name:        "level cap narrows the gap",
+			kind:        shared.HandicapTowerHP,
+			playerA:     newHandicapPlayer("a", 1, 3, 10),
+			playerB:     newHandicapPlayer("b", 5, 3, 10),
+			want:        &Handicap{Username: "a", Kind: shared.HandicapTowerHP, Amount: 20},
+			wantMana:    10,
+			wantTowerHP: 1200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyHandicap(tt.playerA, tt.playerB, tt.kind, ruleSet)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("applyHandicap() = %+v, want no handicap", *got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Fatalf("applyHandicap() = %+v, want %+v", got, *tt.want)
+			}
+
+			lower := tt.playerA
+			if tt.want.Username == tt.playerB.Username {
+				lower = tt.playerB
+			}
+			if lower.CurrentMana != tt.wantMana {
+				t.Errorf("mana = %d, want %d", lower.CurrentMana, tt.wantMana)
+			}
+			if tower := lower.Towers[0]; tower.MaxHP != tt.wantTowerHP || tower.CurrentHP != tt.wantTowerHP {
+				t.Errorf("tower HP = %d/%d, want %d/%d", tower.CurrentHP, tower.MaxHP, tt.wantTowerHP, tt.wantTowerHP)
+			}
+		})
+	}
+}
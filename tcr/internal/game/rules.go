@@ -6,46 +6,51 @@ import (
 	"tcr/internal/shared"
 )
 
-// IsValidTarget checks if the target tower is a valid target based on the game rules
-// For Simple TCR, G1 (GuardTower1) must be destroyed before G2 or King can be targeted
+// IsValidTarget checks if the target tower is a valid target based on the game rules.
+// Which towers are open depends on the targeting rule of the match's rule set.
 func IsValidTarget(attackingPlayer *Player, targetTowerID string, gameState *GameState) bool {
 	// Get opponent (tower owner)
 	opponentPlayer := gameState.GetOpponentPlayer()
 
 	// Can't target your own towers
-	if targetTowerID == attackingPlayer.KingTower.ID ||
-		targetTowerID == attackingPlayer.GuardTower1.ID ||
-		targetTowerID == attackingPlayer.GuardTower2.ID {
+	if attackingPlayer.Tower(targetTowerID) != nil {
 		return false
 	}
 
-	// First check if the target tower exists and is not already destroyed
-	var targetTower *TowerInstance
-	if targetTowerID == opponentPlayer.KingTower.ID {
-		targetTower = opponentPlayer.KingTower
-	} else if targetTowerID == opponentPlayer.GuardTower1.ID {
-		targetTower = opponentPlayer.GuardTower1
-	} else if targetTowerID == opponentPlayer.GuardTower2.ID {
-		targetTower = opponentPlayer.GuardTower2
-	} else {
-		// Target tower ID doesn't match any known tower
+	// Target tower ID must match one of the opponent's towers
+	targetTower := opponentPlayer.Tower(targetTowerID)
+	if targetTower == nil {
 		return false
 	}
 
-	if targetTower.Destroyed {
-		// Can't target already destroyed towers
-		return false
-	}
+	// Can't target destroyed towers or towers still protected by others
+	return gameState.Rules.CanTarget(opponentPlayer, targetTower)
+}
 
-	// Simple TCR Rule: GuardTower1 must be destroyed before targeting GuardTower2 or King
-	if targetTowerID == opponentPlayer.GuardTower2.ID || targetTowerID == opponentPlayer.KingTower.ID {
-		// Check if GuardTower1 is destroyed
-		if !opponentPlayer.GuardTower1.Destroyed {
-			return false
+// ChooseTarget picks the tower a troop attacks when the player names none, following the troop's
+// PreferredTarget. It returns nil if none of the owner's towers can be attacked.
+// Troops preferring TROOPS attack enemy buildings first (see DeployTroop); without any, they attack towers like the others.
+func ChooseTarget(troop *TroopInstance, owner *Player, rules RuleSet) *TowerInstance {
+	var target, king *TowerInstance
+	for _, tower := range owner.Towers {
+		if !rules.CanTarget(owner, tower) {
+			continue
+		}
+		switch {
+		case troop.Spec.PreferredTarget == shared.TargetLowestHP:
+			if target == nil || tower.CurrentHP < target.CurrentHP {
+				target = tower
+			}
+		case tower.Spec.Role == shared.KingTowerRole:
+			king = tower
+		case target == nil:
+			target = tower
 		}
 	}
-
-	return true
+	if target == nil {
+		return king
+	}
+	return target
 }
 
 // CanDeployTroop checks if a player can deploy a specific troop
@@ -78,54 +83,39 @@ func FindTroopInHand(player *Player, troopName string) (*TroopInstance, int) {
 
 // ApplySpecialAbility handles special abilities of troops
 // For now, this only implements Queen's heal ability
-func ApplySpecialAbility(actingPlayer *Player, troopSpec *models.TroopSpec) string {
+func ApplySpecialAbility(actingPlayer *Player, troopSpec *models.TroopSpec, rules RuleSet) string {
 	if troopSpec.SpecialAbility == shared.HealLowestHPTowerAbility {
-		return applyQueenHeal(actingPlayer)
+		return applyQueenHeal(actingPlayer, rules.Params().QueenHealAmount)
 	}
 	return "No special ability applied."
 }
 
 // applyQueenHeal implements the Queen's heal ability
-// It finds the friendly tower with the lowest HP percentage and heals it
-func applyQueenHeal(player *Player) string {
+// It finds the standing friendly tower with the lowest HP percentage and heals it by up to maxHeal HP
+func applyQueenHeal(player *Player, maxHeal int) string {
 	// Find the tower with the lowest HP percentage
 	var lowestHPTower *TowerInstance
 	lowestHPPercentage := 1.0 // Start with 100%
 
-	// Check KingTower
-	kingHPPerc := float64(player.KingTower.CurrentHP) / float64(player.KingTower.Spec.BaseHP)
-	if kingHPPerc < lowestHPPercentage {
-		lowestHPTower = player.KingTower
-		lowestHPPercentage = kingHPPerc
-	}
-
-	// Check GuardTower1 if not destroyed
-	if !player.GuardTower1.Destroyed {
-		g1HPPerc := float64(player.GuardTower1.CurrentHP) / float64(player.GuardTower1.Spec.BaseHP)
-		if g1HPPerc < lowestHPPercentage {
-			lowestHPTower = player.GuardTower1
-			lowestHPPercentage = g1HPPerc
+	for _, tower := range player.Towers {
+		if tower.Destroyed {
+			continue
 		}
-	}
-
-	// Check GuardTower2 if not destroyed
-	if !player.GuardTower2.Destroyed {
-		g2HPPerc := float64(player.GuardTower2.CurrentHP) / float64(player.GuardTower2.Spec.BaseHP)
-		if g2HPPerc < lowestHPPercentage {
-			lowestHPTower = player.GuardTower2
-			lowestHPPercentage = g2HPPerc
+		hpPercentage := float64(tower.CurrentHP) / float64(tower.MaxHP)
+		if hpPercentage < lowestHPPercentage {
+			lowestHPTower = tower
+			lowestHPPercentage = hpPercentage
 		}
 	}
 
 	// Apply healing
 	if lowestHPTower != nil {
 		oldHP := lowestHPTower.CurrentHP
-		originalMaxHP := lowestHPTower.Spec.BaseHP
 
 		// Calculate how much to heal (not exceeding max HP)
-		healAmount := shared.QueenHealAmount
-		if oldHP+healAmount > originalMaxHP {
-			healAmount = originalMaxHP - oldHP
+		healAmount := maxHeal
+		if oldHP+healAmount > lowestHPTower.MaxHP {
+			healAmount = lowestHPTower.MaxHP - oldHP
 		}
 
 		lowestHPTower.CurrentHP += healAmount
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+func TestChooseTarget(t *testing.T) {
+	lanes := config.Default().Rules.Simple
+	lanes.Targeting = shared.TargetingLanes
+
+	tests := []struct {
+		name      string
+		preferred string
+		lanes     bool
+		towerHP   map[string]int // HP of each tower type; 0 destroys the tower
+		want      string         // Type of the chosen tower; empty for none
+	}{
+		{name: "towers: first guard", preferred: shared.TargetTowers, want: "LEFT"},
+		{name: "default is towers", preferred: "", want: "LEFT"},
+		{name: "towers: next guard", preferred: shared.TargetTowers, towerHP: map[string]int{"LEFT": 0}, want: "RIGHT"},
+		{name: "towers: king last", preferred: shared.TargetTowers, towerHP: map[string]int{"LEFT": 0, "RIGHT": 0}, want: "KING"},
+		{name: "towers: guard before an open king", preferred: shared.TargetTowers, lanes: true, towerHP: map[string]int{"LEFT": 0}, want: "RIGHT"},
+		{name: "troops fall back to towers", preferred: shared.TargetTroops, want: "LEFT"},
+		{name: "lowest HP guard", preferred: shared.TargetLowestHP, towerHP: map[string]int{"LEFT": 900, "RIGHT": 400}, want: "RIGHT"},
+		{name: "lowest HP skips closed towers", preferred: shared.TargetLowestHP, towerHP: map[string]int{"KING": 100, "LEFT": 900}, want: "LEFT"},
+		{name: "lowest HP includes an open king", preferred: shared.TargetLowestHP, lanes: true, towerHP: map[string]int{"KING": 100, "LEFT": 0}, want: "KING"},
+		{name: "nothing open", preferred: shared.TargetTowers, towerHP: map[string]int{"KING": 0, "LEFT": 0, "RIGHT": 0}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestSession(t)
+			rules := gs.GameState.Rules
+			if tt.lanes {
+				rules = ComposeRuleSet("lanes", lanes)
+			}
+			owner := gs.GameState.PlayerB
+			for towerType, hp := range tt.towerHP {
+				tower := owner.TowerOfType(towerType)
+				tower.CurrentHP = hp
+				tower.Destroyed = hp == 0
+			}
+			troop := &TroopInstance{Spec: &models.TroopSpec{Name: "Scout", PreferredTarget: tt.preferred}}
+
+			got := ChooseTarget(troop, owner, rules)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("ChooseTarget() = %s, want none", got.Spec.Type)
+			case tt.want != "" && got == nil:
+				t.Errorf("ChooseTarget() = none, want %s", tt.want)
+			case got != nil && got.Spec.Type != tt.want:
+				t.Errorf("ChooseTarget() = %s, want %s", got.Spec.Type, tt.want)
+			}
+		})
+	}
+}
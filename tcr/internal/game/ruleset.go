@@ -0,0 +1,489 @@
+package game
+
+import (
+	"fmt"
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
+
+// TargetingRule decides which towers can be attacked
+type TargetingRule interface {
+	// CanTarget checks if one of the owner's towers can be attacked
+	CanTarget(owner *Player, tower *TowerInstance) bool
+}
+
+// DamageFormula decides how much damage an attack deals
+type DamageFormula interface {
+	// Damage returns the damage a troop's attack deals and whether it was a critical hit
+	Damage(attacker *TroopInstance, defenderDEF int) (damage int, critical bool)
+
+	// CriticalHit returns a troop's crit chance and multiplier in this formula; 0 and 1 without critical hits
+	CriticalHit(attacker *models.TroopSpec) (chancePercent, multiplier float64)
+}
+
+// ManaEconomy decides what deploying a troop costs and how mana comes back
+type ManaEconomy interface {
+	UsesMana() bool                        // Whether players have mana at all
+	DeployCost(spec *models.TroopSpec) int // Mana needed to deploy a troop
+	TurnRegen(overtime bool) int           // Mana gained when a player's turn begins
+	SkipRegen(overtime bool) int           // Mana gained by skipping a turn
+	MaxMana() int                          // Most mana a player can hold
+}
+
+// TurnStructure decides when the turn passes to the other player
+type TurnStructure interface {
+	// BonusAttackOnDestroy checks if destroying a tower grants another attack in the same turn
+	BonusAttackOnDestroy() bool
+}
+
+// WinCondition decides when a match is won
+type WinCondition interface {
+	// IsDecisive checks if destroying one of the owner's towers wins the match for the opponent
+	IsDecisive(owner *Player, destroyed *TowerInstance) bool
+
+	// EndReason describes a decisive destruction, e.g. "King Tower destroyed"
+	EndReason(owner *Player, destroyed *TowerInstance) string
+}
+
+// MatchClock decides how long a match lasts
+type MatchClock interface {
+	MatchDuration() time.Duration    // Length of regular time; 0 if the match has no clock
+	OvertimeDuration() time.Duration // Length of sudden-death overtime after a tie on time; 0 for none
+}
+
+// EXPAwards decides how much EXP players earn
+type EXPAwards interface {
+	TowerEXP(tower *TowerInstance) int          // EXP for destroying a tower
+	BuildingEXP(building *BuildingInstance) int // EXP for destroying a building
+	WinEXP() int                                // EXP for winning a match
+	DrawEXP() int                               // EXP for each player on a draw
+}
+
+// RuleSet is everything that differs between game modes. The engine asks it instead of
+// checking the mode, so new modes only need a new rule set.
+type RuleSet interface {
+	Name() string
+	Params() config.GameRules // The tunable values the rule set was built from
+
+	TargetingRule
+	DamageFormula
+	ManaEconomy
+	TurnStructure
+	WinCondition
+	MatchClock
+	EXPAwards
+}
+
+// ComposedRuleSet is a rule set put together from one rule of each kind
+type ComposedRuleSet struct {
+	name   string
+	params config.GameRules
+
+	TargetingRule
+	DamageFormula
+	ManaEconomy
+	TurnStructure
+	WinCondition
+	MatchClock
+	EXPAwards
+}
+
+// Name returns the name of the rule set (the game mode or the custom rule set)
+func (r *ComposedRuleSet) Name() string {
+	return r.name
+}
+
+// Params returns the config values the rule set was built from
+func (r *ComposedRuleSet) Params() config.GameRules {
+	return r.params
+}
+
+// ComposeRuleSet builds a rule set from the rules chosen in a rules config section.
+// The rules must have passed config validation.
+func ComposeRuleSet(name string, rules config.GameRules) *ComposedRuleSet {
+	return &ComposedRuleSet{
+		name:          name,
+		params:        rules,
+		TargetingRule: targetingRule(rules),
+		DamageFormula: damageFormula(rules),
+		ManaEconomy:   manaEconomy(rules),
+		TurnStructure: turnStructure(rules),
+		WinCondition:  winCondition(rules),
+		MatchClock:    matchClock(rules),
+		EXPAwards:     expAwards(rules),
+	}
+}
+
+// targetingRule returns the targeting rule chosen in a rules config section
+func targetingRule(rules config.GameRules) TargetingRule {
+	if rules.Targeting == shared.TargetingLanes {
+		return LanesTargeting{}
+	}
+	return ClassicTargeting{}
+}
+
+// damageFormula returns the damage formula chosen in a rules config section
+func damageFormula(rules config.GameRules) DamageFormula {
+	if rules.Damage == shared.DamageFlat {
+		return FlatDamage{}
+	}
+	return criticalDamage(rules)
+}
+
+// criticalDamage returns the critical damage formula with the chance and multiplier of a rules config section
+func criticalDamage(rules config.GameRules) CriticalDamage {
+	return CriticalDamage{ChancePercent: rules.TroopCritChance, Multiplier: rules.CritDamageMultiplier}
+}
+
+// manaEconomy returns the mana economy chosen in a rules config section
+func manaEconomy(rules config.GameRules) ManaEconomy {
+	if rules.Mana == shared.ManaFree {
+		return FreeDeploys{}
+	}
+	return manaPool(rules)
+}
+
+// manaPool returns the mana pool with the regeneration and maximum of a rules config section
+func manaPool(rules config.GameRules) ManaPool {
+	return ManaPool{
+		Regen:         rules.ManaRegenRate,
+		OvertimeRegen: int(float64(rules.ManaRegenRate) * rules.OvertimeManaRegenMultiplier),
+		Max:           rules.MaxMana,
+	}
+}
+
+// turnStructure returns the turn structure chosen in a rules config section
+func turnStructure(rules config.GameRules) TurnStructure {
+	if rules.Turns == shared.TurnsAlternate {
+		return AlternatingTurns{}
+	}
+	return BonusAttackTurns{}
+}
+
+// winCondition returns the win condition chosen in a rules config section
+func winCondition(rules config.GameRules) WinCondition {
+	if rules.WinCondition == shared.WinAllTowers {
+		return AllTowersWin{}
+	}
+	return KingTowerWin{}
+}
+
+// matchClock returns the match clock of a rules config section
+func matchClock(rules config.GameRules) TimedMatch {
+	return TimedMatch{
+		Regular:  config.Seconds(rules.MatchSeconds),
+		Overtime: config.Seconds(rules.OvertimeSeconds),
+	}
+}
+
+// expAwards returns the EXP awards chosen in a rules config section
+func expAwards(rules config.GameRules) EXPRewards {
+	return EXPRewards{
+		Win:    rules.WinEXPReward,
+		Draw:   rules.DrawEXPReward,
+		Towers: rules.EXP != shared.EXPMatchOnly,
+	}
+}
+
+// SimpleRuleSet is the rule set of Simple TCR: troops deal ATK - DEF without critical hits and
+// cost no mana. Targeting, turns, the win condition, the clock and EXP awards come from the
+// mode's rules section.
+type SimpleRuleSet struct {
+	params config.GameRules
+
+	TargetingRule
+	FlatDamage
+	FreeDeploys
+	TurnStructure
+	WinCondition
+	TimedMatch
+	EXPRewards
+}
+
+// NewSimpleRuleSet returns the rule set of the Simple TCR mode
+func NewSimpleRuleSet(rules config.GameRules) RuleSet {
+	return &SimpleRuleSet{
+		params:        rules,
+		TargetingRule: targetingRule(rules),
+		TurnStructure: turnStructure(rules),
+		WinCondition:  winCondition(rules),
+		TimedMatch:    matchClock(rules),
+		EXPRewards:    expAwards(rules),
+	}
+}
+
+// Name returns the name of the Simple TCR mode
+func (r *SimpleRuleSet) Name() string {
+	return shared.GameModeSimple
+}
+
+// Params returns the config values the rule set was built from
+func (r *SimpleRuleSet) Params() config.GameRules {
+	return r.params
+}
+
+// EnhancedRuleSet is the rule set of Enhanced TCR: troops may deal critical hits and cost mana,
+// which regenerates every turn. Targeting, turns, the win condition, the clock and EXP awards
+// come from the mode's rules section.
+type EnhancedRuleSet struct {
+	params config.GameRules
+
+	TargetingRule
+	CriticalDamage
+	ManaPool
+	TurnStructure
+	WinCondition
+	TimedMatch
+	EXPRewards
+}
+
+// NewEnhancedRuleSet returns the rule set of the Enhanced TCR mode
+func NewEnhancedRuleSet(rules config.GameRules) RuleSet {
+	return &EnhancedRuleSet{
+		params:         rules,
+		TargetingRule:  targetingRule(rules),
+		CriticalDamage: criticalDamage(rules),
+		ManaPool:       manaPool(rules),
+		TurnStructure:  turnStructure(rules),
+		WinCondition:   winCondition(rules),
+		TimedMatch:     matchClock(rules),
+		EXPRewards:     expAwards(rules),
+	}
+}
+
+// Name returns the name of the Enhanced TCR mode
+func (r *EnhancedRuleSet) Name() string {
+	return shared.GameModeEnhanced
+}
+
+// Params returns the config values the rule set was built from
+func (r *EnhancedRuleSet) Params() config.GameRules {
+	return r.params
+}
+
+// NewRuleSet returns the rule set a match is played with: the custom rule set named in its
+// settings, or else the rule set of its game mode
+func NewRuleSet(cfg *config.Config, settings models.MatchSettings) RuleSet {
+	if settings.RuleSet != "" {
+		if rules, exists := cfg.CustomRules(settings.RuleSet); exists {
+			return ComposeRuleSet(settings.RuleSet, rules)
+		}
+	}
+
+	rules := cfg.RulesFor(settings.Mode)
+	if settings.Mode == shared.GameModeEnhanced {
+		return NewEnhancedRuleSet(rules)
+	}
+	return NewSimpleRuleSet(rules)
+}
+
+// ClassicTargeting opens a tower once every tower in its Requires is destroyed
+type ClassicTargeting struct{}
+
+// CanTarget checks if the tower is standing and no longer protected
+func (ClassicTargeting) CanTarget(owner *Player, tower *TowerInstance) bool {
+	if tower.Destroyed {
+		return false
+	}
+	for _, required := range tower.Spec.Requires {
+		if requiredTower := owner.TowerOfType(required); requiredTower != nil && !requiredTower.Destroyed {
+			return false
+		}
+	}
+	return true
+}
+
+// LanesTargeting ignores Requires: guard towers are always open, and the King opens
+// once any guard is destroyed
+type LanesTargeting struct{}
+
+// CanTarget checks if the tower is standing and its lane is open
+func (LanesTargeting) CanTarget(owner *Player, tower *TowerInstance) bool {
+	if tower.Destroyed {
+		return false
+	}
+	if tower.Spec.Role != shared.KingTowerRole {
+		return true
+	}
+	for _, other := range owner.Towers {
+		if other.Spec.Role == shared.GuardTowerRole && other.Destroyed {
+			return true
+		}
+	}
+	return !owner.hasGuardTowers()
+}
+
+// CriticalDamage is DMG = ATK - DEF, where an attack is critical with the given chance
+// and its ATK is then multiplied. Troops may set their own chance and multiplier.
+type CriticalDamage struct {
+	ChancePercent float64
+	Multiplier    float64
+}
+
+// Damage rolls for a critical hit and calculates the damage
+func (d CriticalDamage) Damage(attacker *TroopInstance, defenderDEF int) (int, bool) {
+	chance, multiplier := d.CriticalHit(attacker.Spec)
+	return CalculateDamageEnhanced(attacker.CurrentATK, defenderDEF, chance, multiplier, attacker.Spec.ArmorPenetrationPercent)
+}
+
+// CriticalHit returns the troop's own crit chance and multiplier, or else the formula's
+func (d CriticalDamage) CriticalHit(attacker *models.TroopSpec) (float64, float64) {
+	chance, multiplier := d.ChancePercent, d.Multiplier
+	if attacker.CritChancePercent > 0 {
+		chance = attacker.CritChancePercent
+	}
+	if attacker.CritMultiplier > 0 {
+		multiplier = attacker.CritMultiplier
+	}
+	return chance, multiplier
+}
+
+// FlatDamage is DMG = ATK - DEF, without critical hits
+type FlatDamage struct{}
+
+// Damage calculates the damage
+func (FlatDamage) Damage(attacker *TroopInstance, defenderDEF int) (int, bool) {
+	return CalculateDamage(attacker.CurrentATK, PenetrateArmor(defenderDEF, attacker.Spec.ArmorPenetrationPercent)), false
+}
+
+// CriticalHit returns no crit chance
+func (FlatDamage) CriticalHit(*models.TroopSpec) (float64, float64) {
+	return 0, 1
+}
+
+// ManaPool makes troops and spells cost mana, which regenerates every turn up to a maximum.
+// Skipping a turn regenerates 1.5x the usual amount.
+type ManaPool struct {
+	Regen         int
+	OvertimeRegen int // Regen during overtime
+	Max           int
+}
+
+// UsesMana reports that players have mana
+func (m ManaPool) UsesMana() bool { return true }
+
+// DeployCost returns the card's ManaCost
+func (m ManaPool) DeployCost(spec *models.TroopSpec) int {
+	return spec.ManaCost
+}
+
+// TurnRegen returns the mana regained when a turn begins, the overtime rate in overtime
+func (m ManaPool) TurnRegen(overtime bool) int {
+	if overtime {
+		return m.OvertimeRegen
+	}
+	return m.Regen
+}
+
+// SkipRegen returns 1.5x the turn's regeneration
+func (m ManaPool) SkipRegen(overtime bool) int {
+	regen := m.TurnRegen(overtime)
+	return regen + regen/2
+}
+
+// MaxMana returns the most mana a player can hold
+func (m ManaPool) MaxMana() int { return m.Max }
+
+// FreeDeploys lets players deploy any troop in their hand; mana is not used
+type FreeDeploys struct{}
+
+// UsesMana reports that players have no mana
+func (FreeDeploys) UsesMana() bool { return false }
+
+// DeployCost returns 0: every card is free
+func (FreeDeploys) DeployCost(*models.TroopSpec) int { return 0 }
+
+// TurnRegen returns 0: there is no mana to regain
+func (FreeDeploys) TurnRegen(bool) int { return 0 }
+
+// SkipRegen returns 0: skipping a turn gains nothing
+func (FreeDeploys) SkipRegen(bool) int { return 0 }
+
+// MaxMana returns 0: players hold no mana
+func (FreeDeploys) MaxMana() int { return 0 }
+
+// BonusAttackTurns gives a player another attack after destroying a tower
+type BonusAttackTurns struct{}
+
+// BonusAttackOnDestroy grants another attack after a tower is destroyed
+func (BonusAttackTurns) BonusAttackOnDestroy() bool { return true }
+
+// AlternatingTurns passes the turn after every deploy
+type AlternatingTurns struct{}
+
+// BonusAttackOnDestroy never grants another attack
+func (AlternatingTurns) BonusAttackOnDestroy() bool { return false }
+
+// KingTowerWin wins the match for whoever destroys the opponent's King tower
+type KingTowerWin struct{}
+
+// IsDecisive checks if the destroyed tower is the King
+func (KingTowerWin) IsDecisive(owner *Player, destroyed *TowerInstance) bool {
+	return destroyed.Spec.Role == shared.KingTowerRole
+}
+
+// EndReason names the destroyed King tower
+func (KingTowerWin) EndReason(owner *Player, destroyed *TowerInstance) string {
+	return fmt.Sprintf("%s destroyed", destroyed.Spec.Name)
+}
+
+// AllTowersWin wins the match for whoever destroys every tower of the opponent
+type AllTowersWin struct{}
+
+// IsDecisive checks if the destroyed tower was the owner's last one standing
+func (AllTowersWin) IsDecisive(owner *Player, destroyed *TowerInstance) bool {
+	for _, tower := range owner.Towers {
+		if !tower.Destroyed {
+			return false
+		}
+	}
+	return true
+}
+
+// EndReason names the player who lost every tower
+func (AllTowersWin) EndReason(owner *Player, destroyed *TowerInstance) string {
+	return fmt.Sprintf("All of %s's towers destroyed", owner.Username)
+}
+
+// TimedMatch is a match clock with the given regular time and overtime
+type TimedMatch struct {
+	Regular  time.Duration
+	Overtime time.Duration
+}
+
+// MatchDuration returns the regular time; 0 if the match has no clock
+func (c TimedMatch) MatchDuration() time.Duration { return c.Regular }
+
+// OvertimeDuration returns the length of sudden-death overtime; 0 for none
+func (c TimedMatch) OvertimeDuration() time.Duration { return c.Overtime }
+
+// EXPRewards awards fixed EXP for match results and, if Towers is set, each tower's and building's DestroyEXP
+type EXPRewards struct {
+	Win    int
+	Draw   int
+	Towers bool
+}
+
+// TowerEXP returns the tower's DestroyEXP, or 0 if towers award no EXP
+func (e EXPRewards) TowerEXP(tower *TowerInstance) int {
+	if !e.Towers {
+		return 0
+	}
+	return tower.Spec.DestroyEXP
+}
+
+// BuildingEXP returns the building's DestroyEXP, or 0 if towers and buildings award no EXP
+func (e EXPRewards) BuildingEXP(building *BuildingInstance) int {
+	if !e.Towers {
+		return 0
+	}
+	return building.Spec.DestroyEXP
+}
+
+// WinEXP returns the EXP for winning a match
+func (e EXPRewards) WinEXP() int { return e.Win }
+
+// DrawEXP returns the EXP each player gets on a draw
+func (e EXPRewards) DrawEXP() int { return e.Draw }
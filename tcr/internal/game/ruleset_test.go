@@ -0,0 +1,248 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+	"time"
+)
+
+func TestRuleSets(t *testing.T) {
+	cfg := config.Default()
+	lanes := cfg.Rules.Simple
+	lanes.Targeting = shared.TargetingLanes
+	lanes.Damage = shared.DamageFlat
+	lanes.Mana = shared.ManaFree
+	lanes.Turns = shared.TurnsAlternate
+	lanes.WinCondition = shared.WinAllTowers
+	lanes.EXP = shared.EXPMatchOnly
+	lanes.MatchSeconds = 90
+	cfg.Rules.Custom = map[string]config.GameRules{"lanes": lanes}
+
+	tests := []struct {
+		name         string
+		settings     models.MatchSettings
+		wantName     string
+		wantMana     bool
+		wantCrit     bool
+		wantBonus    bool
+		wantTarget   TargetingRule
+		wantWin      WinCondition
+		wantClock    time.Duration
+		wantTowerEXP bool
+	}{
+		{
+			name:         "simple has no mana and no critical hits",
+			settings:     models.MatchSettings{Mode: shared.GameModeSimple},
+			wantName:     shared.GameModeSimple,
+			wantBonus:    true,
+			wantTarget:   ClassicTargeting{},
+			wantWin:      KingTowerWin{},
+			wantTowerEXP: true,
+		},
+		{
+			name:         "enhanced uses mana and critical hits",
+			settings:     models.MatchSettings{Mode: shared.GameModeEnhanced},
+			wantName:     shared.GameModeEnhanced,
+			wantMana:     true,
+			wantCrit:     true,
+			wantBonus:    true,
+			wantTarget:   ClassicTargeting{},
+			wantWin:      KingTowerWin{},
+			wantClock:    config.Seconds(shared.GameDurationSeconds),
+			wantTowerEXP: true,
+		},
+		{
+			name:       "custom rule set composes its rules",
+			settings:   models.MatchSettings{Mode: shared.GameModeEnhanced, RuleSet: "lanes"},
+			wantName:   "lanes",
+			wantTarget: LanesTargeting{},
+			wantWin:    AllTowersWin{},
+			wantClock:  90 * time.Second,
+		},
+		{
+			name:         "unknown custom rule set falls back to the mode",
+			settings:     models.MatchSettings{Mode: shared.GameModeSimple, RuleSet: "missing"},
+			wantName:     shared.GameModeSimple,
+			wantBonus:    true,
+			wantTarget:   ClassicTargeting{},
+			wantWin:      KingTowerWin{},
+			wantTowerEXP: true,
+		},
+	}
+
+	troop := &models.TroopSpec{Name: "Knight", ManaCost: 4}
+	tower := &TowerInstance{Spec: &models.TowerSpec{DestroyEXP: 50}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := NewRuleSet(cfg, tt.settings)
+			if got := rules.Name(); got != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got, tt.wantName)
+			}
+			if got := rules.UsesMana(); got != tt.wantMana {
+				t.Errorf("UsesMana() = %v, want %v", got, tt.wantMana)
+			}
+			if cost := rules.DeployCost(troop); (cost > 0) != tt.wantMana {
+				t.Errorf("DeployCost() = %d with mana %v", cost, tt.wantMana)
+			}
+			if chance, _ := rules.CriticalHit(troop); (chance > 0) != tt.wantCrit {
+				t.Errorf("CriticalHit() chance = %v with critical hits %v", chance, tt.wantCrit)
+			}
+			if got := rules.BonusAttackOnDestroy(); got != tt.wantBonus {
+				t.Errorf("BonusAttackOnDestroy() = %v, want %v", got, tt.wantBonus)
+			}
+			targeting, win := embeddedRules(rules)
+			if targeting != tt.wantTarget {
+				t.Errorf("targeting rule = %T, want %T", targeting, tt.wantTarget)
+			}
+			if win != tt.wantWin {
+				t.Errorf("win condition = %T, want %T", win, tt.wantWin)
+			}
+			if got := rules.MatchDuration(); got != tt.wantClock {
+				t.Errorf("MatchDuration() = %v, want %v", got, tt.wantClock)
+			}
+			if exp := rules.TowerEXP(tower); (exp > 0) != tt.wantTowerEXP {
+				t.Errorf("TowerEXP() = %d with tower EXP %v", exp, tt.wantTowerEXP)
+			}
+		})
+	}
+}
+
+// embeddedRules returns the targeting rule and win condition a rule set was built with
+func embeddedRules(rules RuleSet) (TargetingRule, WinCondition) {
+	switch rules := rules.(type) {
+	case *SimpleRuleSet:
+		return rules.TargetingRule, rules.WinCondition
+	case *EnhancedRuleSet:
+		return rules.TargetingRule, rules.WinCondition
+	case *ComposedRuleSet:
+		return rules.TargetingRule, rules.WinCondition
+	}
+	return nil, nil
+}
+
+func TestTargetingRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		targeting TargetingRule
+		destroyed []string
+		kingOnly  bool // The layout has no guard towers
+		tower     string
+		want      bool
+	}{
+		{name: "classic guard without requirements", targeting: ClassicTargeting{}, tower: "LEFT", want: true},
+		{name: "classic king behind both guards", targeting: ClassicTargeting{}, tower: "KING", want: false},
+		{name: "classic king behind one guard", targeting: ClassicTargeting{}, destroyed: []string{"LEFT"}, tower: "KING", want: false},
+		{name: "classic king after both guards", targeting: ClassicTargeting{}, destroyed: []string{"LEFT", "RIGHT"}, tower: "KING", want: true},
+		{name: "classic destroyed tower", targeting: ClassicTargeting{}, destroyed: []string{"LEFT"}, tower: "LEFT", want: false},
+		{name: "lanes guard is open", targeting: LanesTargeting{}, tower: "RIGHT", want: true},
+		{name: "lanes king closed while both guards stand", targeting: LanesTargeting{}, tower: "KING", want: false},
+		{name: "lanes king opens with the left guard", targeting: LanesTargeting{}, destroyed: []string{"LEFT"}, tower: "KING", want: true},
+		{name: "lanes king opens with the right guard", targeting: LanesTargeting{}, destroyed: []string{"RIGHT"}, tower: "KING", want: true},
+		{name: "lanes other guard stays open", targeting: LanesTargeting{}, destroyed: []string{"RIGHT"}, tower: "LEFT", want: true},
+		{name: "lanes destroyed guard", targeting: LanesTargeting{}, destroyed: []string{"RIGHT"}, tower: "RIGHT", want: false},
+		{name: "lanes king without guards", targeting: LanesTargeting{}, kingOnly: true, tower: "KING", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := newTestSession(t).GameState.PlayerB
+			if tt.kingOnly {
+				player.Towers = []*TowerInstance{player.KingTower()}
+			}
+			for _, towerType := range tt.destroyed {
+				player.TowerOfType(towerType).Destroyed = true
+			}
+			if got := tt.targeting.CanTarget(player, player.TowerOfType(tt.tower)); got != tt.want {
+				t.Errorf("%T.CanTarget(%s) = %v, want %v", tt.targeting, tt.tower, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManaPool(t *testing.T) {
+	pool := ManaPool{Regen: 4, OvertimeRegen: 8, Max: 20}
+	tests := []struct {
+		name     string
+		overtime bool
+		wantTurn int
+		wantSkip int
+	}{
+		{"regular time", false, 4, 6},
+		{"overtime", true, 8, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pool.TurnRegen(tt.overtime); got != tt.wantTurn {
+				t.Errorf("TurnRegen(%v) = %d, want %d", tt.overtime, got, tt.wantTurn)
+			}
+			if got := pool.SkipRegen(tt.overtime); got != tt.wantSkip {
+				t.Errorf("SkipRegen(%v) = %d, want %d", tt.overtime, got, tt.wantSkip)
+			}
+		})
+	}
+
+	if got := pool.DeployCost(&models.TroopSpec{ManaCost: 5}); got != 5 {
+		t.Errorf("DeployCost() = %d for a troop, want 5", got)
+	}
+	if got := pool.DeployCost(&models.TroopSpec{ManaCost: 3, CardType: shared.CardSpell}); got != 3 {
+		t.Errorf("DeployCost() = %d for a spell, want 3", got)
+	}
+}
+
+func TestCriticalHit(t *testing.T) {
+	formula := CriticalDamage{ChancePercent: 20, Multiplier: 1.2}
+	tests := []struct {
+		name           string
+		spec           models.TroopSpec
+		wantChance     float64
+		wantMultiplier float64
+	}{
+		{"mode defaults", models.TroopSpec{Name: "Pawn"}, 20, 1.2},
+		{"own crit chance", models.TroopSpec{Name: "Rogue", CritChancePercent: 50}, 50, 1.2},
+		{"own multiplier", models.TroopSpec{Name: "Giant", CritMultiplier: 2}, 20, 2},
+		{"both of its own", models.TroopSpec{Name: "Assassin", CritChancePercent: 75, CritMultiplier: 3}, 75, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chance, multiplier := formula.CriticalHit(&tt.spec)
+			if chance != tt.wantChance || multiplier != tt.wantMultiplier {
+				t.Errorf("CriticalHit() = %v, %v; want %v, %v", chance, multiplier, tt.wantChance, tt.wantMultiplier)
+			}
+		})
+	}
+
+	if chance, multiplier := (FlatDamage{}).CriticalHit(&models.TroopSpec{CritChancePercent: 50, CritMultiplier: 2}); chance != 0 || multiplier != 1 {
+		t.Errorf("FlatDamage.CriticalHit() = %v, %v; want no critical hits", chance, multiplier)
+	}
+}
+
+func TestDamageFormulas(t *testing.T) {
+	tests := []struct {
+		name         string
+		formula      DamageFormula
+		spec         models.TroopSpec
+		atk, def     int
+		wantDamage   int
+		wantCritical bool
+	}{
+		{"flat", FlatDamage{}, models.TroopSpec{}, 300, 100, 200, false},
+		{"flat never negative", FlatDamage{}, models.TroopSpec{}, 50, 100, 0, false},
+		{"flat with armor penetration", FlatDamage{}, models.TroopSpec{ArmorPenetrationPercent: 50}, 300, 100, 250, false},
+		{"flat ignores the troop's crit chance", FlatDamage{}, models.TroopSpec{CritChancePercent: 100}, 300, 100, 200, false},
+		{"critical never crits at 0%", CriticalDamage{ChancePercent: 0, Multiplier: 2}, models.TroopSpec{}, 300, 100, 200, false},
+		{"critical always crits at 100%", CriticalDamage{ChancePercent: 0, Multiplier: 2}, models.TroopSpec{CritChancePercent: 100}, 300, 100, 500, true},
+		{"troop's own multiplier", CriticalDamage{ChancePercent: 100, Multiplier: 2}, models.TroopSpec{CritMultiplier: 3}, 300, 100, 800, true},
+		{"critical with full armor penetration", CriticalDamage{ChancePercent: 100, Multiplier: 1.5}, models.TroopSpec{ArmorPenetrationPercent: 100}, 300, 100, 450, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			troop := &TroopInstance{Spec: &tt.spec, CurrentATK: tt.atk}
+			damage, critical := tt.formula.Damage(troop, tt.def)
+			if damage != tt.wantDamage || critical != tt.wantCritical {
+				t.Errorf("Damage() = %d, %v; want %d, %v", damage, critical, tt.wantDamage, tt.wantCritical)
+			}
+		})
+	}
+}
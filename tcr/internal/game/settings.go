@@ -0,0 +1,76 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+)
+
+// DefaultMatchSettings returns the standard settings for a match in the given mode
+func DefaultMatchSettings(cfg *config.Config, mode string) models.MatchSettings {
+	return models.MatchSettings{
+		Mode:             mode,
+		TurnTimerSeconds: 0, // No turn timer by default
+		StartingMana:     cfg.RulesFor(mode).InitialMana,
+	}
+}
+
+// ValidateMatchSettings checks that match settings are within the ranges allowed by the server config
+func ValidateMatchSettings(cfg *config.Config, settings models.MatchSettings) error {
+	modeSupported := false
+	for _, mode := range shared.SupportedGameModes {
+		if settings.Mode == mode {
+			modeSupported = true
+			break
+		}
+	}
+	if !modeSupported {
+		return fmt.Errorf("unknown game mode: %s", settings.Mode)
+	}
+
+	minTurnTimer, maxTurnTimer := cfg.Timeouts.MinTurnTimerSeconds, cfg.Timeouts.MaxTurnTimerSeconds
+	if settings.TurnTimerSeconds != 0 &&
+		(settings.TurnTimerSeconds < minTurnTimer || settings.TurnTimerSeconds > maxTurnTimer) {
+		return fmt.Errorf("turn timer must be 0 (off) or between %d and %d seconds", minTurnTimer, maxTurnTimer)
+	}
+
+	rules, exists := matchRules(cfg, settings)
+	if !exists {
+		return fmt.Errorf("unknown rule set: %s", settings.RuleSet)
+	}
+
+	maxMana := rules.MaxMana
+	if settings.StartingMana < 0 || settings.StartingMana > maxMana {
+		return fmt.Errorf("starting mana must be between 0 and %d", maxMana)
+	}
+
+	if settings.TeamSize < 0 || settings.TeamSize > shared.TeamMatchSize {
+		return fmt.Errorf("team size must be 1 (1v1) or %d (%dv%d)", shared.TeamMatchSize, shared.TeamMatchSize, shared.TeamMatchSize)
+	}
+
+	if settings.LevelCap < 0 {
+		return fmt.Errorf("level cap must be 0 (off) or a level of at least 1")
+	}
+	if settings.Handicap != "" && !slices.Contains(shared.Handicaps, settings.Handicap) {
+		return fmt.Errorf("unknown handicap: %s (use one of %v)", settings.Handicap, shared.Handicaps)
+	}
+	if settings.Handicap == shared.HandicapMana && rules.Mana == shared.ManaFree {
+		return fmt.Errorf("a %s handicap needs a rule set that uses mana", shared.HandicapMana)
+	}
+	if settings.Handicap != "" && settings.TeamSize == shared.TeamMatchSize {
+		return fmt.Errorf("handicaps are only available in 1v1 matches")
+	}
+
+	return nil
+}
+
+// matchRules returns the config rules a match is played with: those of its custom rule set if it
+// names one, otherwise those of its game mode. It returns false if the custom rule set does not exist.
+func matchRules(cfg *config.Config, settings models.MatchSettings) (config.GameRules, bool) {
+	if settings.RuleSet != "" {
+		return cfg.CustomRules(settings.RuleSet)
+	}
+	return cfg.RulesFor(settings.Mode), true
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+func TestValidateMatchSettings(t *testing.T) {
+	cfg := config.Default()
+	free := cfg.Rules.Simple
+	free.Mana = shared.ManaFree
+	cfg.Rules.Custom = map[string]config.GameRules{"free": free}
+
+	tests := []struct {
+		name     string
+		settings models.MatchSettings
+		wantErr  bool
+	}{
+		{"default simple", DefaultMatchSettings(cfg, shared.GameModeSimple), false},
+		{"default enhanced", DefaultMatchSettings(cfg, shared.GameModeEnhanced), false},
+		{"unknown mode", models.MatchSettings{Mode: "TURBO"}, true},
+		{"turn timer off", models.MatchSettings{Mode: shared.GameModeSimple, TurnTimerSeconds: 0}, false},
+		{"shortest turn timer", models.MatchSettings{Mode: shared.GameModeSimple, TurnTimerSeconds: shared.MinTurnTimerSeconds}, false},
+		{"turn timer too short", models.MatchSettings{Mode: shared.GameModeSimple, TurnTimerSeconds: shared.MinTurnTimerSeconds - 1}, true},
+		{"turn timer too long", models.MatchSettings{Mode: shared.GameModeSimple, TurnTimerSeconds: shared.MaxTurnTimerSeconds + 1}, true},
+		{"no starting mana", models.MatchSettings{Mode: shared.GameModeEnhanced, StartingMana: 0}, false},
+		{"full starting mana", models.MatchSettings{Mode: shared.GameModeEnhanced, StartingMana: shared.MaxMana}, false},
+		{"negative starting mana", models.MatchSettings{Mode: shared.GameModeEnhanced, StartingMana: -1}, true},
+		{"starting mana above the maximum", models.MatchSettings{Mode: shared.GameModeEnhanced, StartingMana: shared.MaxMana + 1}, true},
+		{"unknown rule set", models.MatchSettings{Mode: shared.GameModeSimple, RuleSet: "siege"}, true},
+		{"level cap", models.MatchSettings{Mode: shared.GameModeSimple, LevelCap: 5}, false},
+		{"negative level cap", models.MatchSettings{Mode: shared.GameModeSimple, LevelCap: -1}, true},
+		{"mana handicap", models.MatchSettings{Mode: shared.GameModeEnhanced, Handicap: shared.HandicapMana}, false},
+		{"tower HP handicap", models.MatchSettings{Mode: shared.GameModeSimple, Handicap: shared.HandicapTowerHP}, false},
+		{"unknown handicap", models.MatchSettings{Mode: shared.GameModeSimple, Handicap: "EXTRA_CARD"}, true},
+		{"mana handicap without mana", models.MatchSettings{Mode: shared.GameModeSimple, RuleSet: "free", Handicap: shared.HandicapMana}, true},
+		{"team match", models.MatchSettings{Mode: shared.GameModeSimple, TeamSize: shared.TeamMatchSize}, false},
+		{"team size too large", models.MatchSettings{Mode: shared.GameModeSimple, TeamSize: shared.TeamMatchSize + 1}, true},
+		{"handicap in a team match", models.MatchSettings{Mode: shared.GameModeSimple, TeamSize: shared.TeamMatchSize, Handicap: shared.HandicapTowerHP}, true},
+		{"tower HP handicap without mana", models.MatchSettings{Mode: shared.GameModeSimple, RuleSet: "free", Handicap: shared.HandicapTowerHP}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMatchSettings(cfg, tt.settings); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMatchSettings(%+v) = %v, want error %v", tt.settings, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+)
+
+// isSpell checks if a card in a hand is a spell
+func isSpell(card *TroopInstance) bool {
+	return card.Spec.Card() == shared.CardSpell
+}
+
+// spellNeedsTarget checks if a spell acts on an enemy tower, which the player may name like a troop's target
+func spellNeedsTarget(spec *models.TroopSpec) bool {
+	return spec.SpecialAbility == shared.FireballAbility
+}
+
+// freezeTarget returns the enemy building a FREEZE spell cast by the player acts on: the building
+// named by targetID, or the first enemy building that attacks if none is named. It returns nil if
+// there is no such building.
+func (gs *GameSession) freezeTarget(caster *Player, targetID string) *BuildingInstance {
+	enemy := gs.GameState.OpposingSide(caster.Username)
+	if targetID != "" {
+		return enemy.Building(targetID)
+	}
+	for _, building := range enemy.Buildings {
+		if building.CurrentATK > 0 {
+			return building
+		}
+	}
+	return nil
+}
+
+// castSpell applies a spell that targets no tower and returns what happened. frozen is the enemy
+// building a FREEZE spell acts on (see freezeTarget), nil for other spells.
+func (gs *GameSession) castSpell(caster *Player, spell *TroopInstance, frozen *BuildingInstance) string {
+	spec := spell.Spec
+	switch spec.SpecialAbility {
+	case shared.FreezeAbility:
+		frozen.FrozenTurns += spec.SpellPower
+		return fmt.Sprintf("%s cast %s: %s is frozen and will skip its next %s.", caster.Username, spec.Name, frozen.ID, attacksText(frozen.FrozenTurns))
+	case shared.RageAbility:
+		caster.RagePercent = spec.SpellPower
+		return fmt.Sprintf("%s cast %s: their next troop attacks with +%d%% ATK.", caster.Username, spec.Name, spec.SpellPower)
+	default:
+		return ApplySpecialAbility(caster, spec, gs.GameState.Rules)
+	}
+}
+
+// resolveFireball deals a fireball cast by the player to a target tower of the owner, ignoring its
+// defense. The fireball never crits, but splashes like a troop.
+func (gs *GameSession) resolveFireball(player *Player, spell *TroopInstance, owner *Player, target *TowerInstance) []Hit {
+	return gs.spreadAttack(player, spell, owner, target, spell.CurrentATK, false)
+}
+
+// SpellDescription describes what a spell does for the player holding it, e.g. "Deals 350 damage to a tower, ignoring defense"
+func SpellDescription(spell *TroopInstance, rules RuleSet) string {
+	spec := spell.Spec
+	switch spec.SpecialAbility {
+	case shared.FireballAbility:
+		description := fmt.Sprintf("Deals %d damage to a tower, ignoring defense", spell.CurrentATK)
+		if spec.SplashPercent > 0 {
+			description += fmt.Sprintf(" (%d%% splash)", spec.SplashPercent)
+		}
+		return description
+	case shared.FreezeAbility:
+		return fmt.Sprintf("An enemy building (not a tower) skips its next %s", attacksText(spec.SpellPower))
+	case shared.RageAbility:
+		return fmt.Sprintf("Your next troop attacks with +%d%% ATK", spec.SpellPower)
+	case shared.HealLowestHPTowerAbility:
+		return fmt.Sprintf("Heals your weakest tower by up to %d HP", rules.Params().QueenHealAmount)
+	}
+	return spec.SpecialAbility
+}
+
+// turnsText returns "1 turn" or "N turns"
+func turnsText(turns int) string {
+	if turns == 1 {
+		return "1 turn"
+	}
+	return fmt.Sprintf("%d turns", turns)
+}
+
+// attacksText returns "attack" or "N attacks"
+func attacksText(attacks int) string {
+	if attacks == 1 {
+		return "attack"
+	}
+	return fmt.Sprintf("%d attacks", attacks)
+}
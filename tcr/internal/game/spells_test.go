@@ -0,0 +1,277 @@
+package game
+
+import (
+	"strings"
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+var (
+	fireballSpec = models.TroopSpec{Name: "Fireball", BaseATK: 300, ManaCost: 4, CardType: shared.CardSpell, SpecialAbility: shared.FireballAbility, SplashPercent: 50}
+	freezeSpec   = models.TroopSpec{Name: "Freeze", ManaCost: 3, CardType: shared.CardSpell, SpecialAbility: shared.FreezeAbility, SpellPower: 1}
+	rageSpec     = models.TroopSpec{Name: "Rage", ManaCost: 2, CardType: shared.CardSpell, SpecialAbility: shared.RageAbility, SpellPower: 50}
+	rookSpec     = models.TroopSpec{Name: "Rook", BaseHP: 250, BaseATK: 200, ManaCost: 5}
+)
+
+// newSpellSession creates a match between alice and bob played with a mana pool, lanes targeting,
+// alternating turns and the given damage formula. Alice starts with the given cards and mana.
+func newSpellSession(t *testing.T, damage string, mana int, hand ...models.TroopSpec) *GameSession {
+	t.Helper()
+
+	gs := newTestSession(t)
+	rules := config.Default().Rules.Simple
+	rules.Mana = shared.ManaPool
+	rules.Damage = damage
+	rules.TroopCritChance = 100
+	rules.Targeting = shared.TargetingLanes
+	rules.Turns = shared.TurnsAlternate
+	gs.GameState.Rules = ComposeRuleSet("test", rules)
+
+	alice := gs.GameState.PlayerA
+	alice.CurrentMana = mana
+	alice.Troops = nil
+	for i := range hand {
+		alice.Troops = append(alice.Troops, NewTroopInstance(&hand[i], hand[i].Name, 1))
+	}
+	return gs
+}
+
+func TestDeployManaCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		card     models.TroopSpec
+		target   string
+		mana     int
+		wantOK   bool
+		wantMana int
+	}{
+		{"troop paid", rookSpec, "bob_LEFT", 7, true, 2},
+		{"troop too expensive", rookSpec, "bob_LEFT", 4, false, 4},
+		{"troop aimed at a closed tower", rookSpec, "bob_KING", 7, false, 7},
+		{"targeted spell paid", fireballSpec, "bob_LEFT", 4, true, 0},
+		{"targeted spell too expensive", fireballSpec, "bob_LEFT", 3, false, 3},
+		{"targeted spell aimed at a closed tower", fireballSpec, "bob_KING", 4, false, 4},
+		{"spell without a target paid", rageSpec, "", 2, true, 0},
+		{"spell without a target too expensive", rageSpec, "", 1, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, tt.mana, tt.card)
+			alice := gs.GameState.PlayerA
+
+			msg, ok := gs.DeployTroop("alice", tt.card.Name, tt.target)
+			if ok != tt.wantOK {
+				t.Fatalf("DeployTroop(%s) ok = %v, want %v (%s)", tt.card.Name, ok, tt.wantOK, msg)
+			}
+			if alice.CurrentMana != tt.wantMana {
+				t.Errorf("mana = %d, want %d", alice.CurrentMana, tt.wantMana)
+			}
+			if inHand, _ := FindTroopInHand(alice, tt.card.Name); !ok && inHand == nil {
+				t.Errorf("%s left the hand of a refused deploy", tt.card.Name)
+			}
+		})
+	}
+}
+
+func TestFireball(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage string
+	}{
+		{"flat damage", shared.DamageFlat},
+		{"never crits", shared.DamageCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, tt.damage, 10, fireballSpec)
+			bob := gs.GameState.PlayerB
+			left := bob.TowerOfType("LEFT")
+			left.CurrentHP, left.Destroyed = 0, true
+
+			// The King has 300 DEF and the Right Guard 100, which the fireball ignores
+			if msg, ok := gs.DeployTroop("alice", "Fireball", "bob_KING"); !ok {
+				t.Fatalf("DeployTroop(Fireball) failed: %s", msg)
+			}
+			want := []wantHit{
+				{"KING", shared.HitPrimary, 300, false, 1700},
+				{"RIGHT", shared.HitSplash, 150, false, 850},
+			}
+			hits := gs.GameState.LastHits
+			if len(hits) != len(want) {
+				t.Fatalf("got %d hits %+v, want %d", len(hits), hits, len(want))
+			}
+			for i, w := range want {
+				hit := hits[i]
+				if hit.TowerID != "bob_"+w.tower || hit.Kind != w.kind || hit.Damage != w.damage || hit.HPRemaining != w.hpRemaining {
+					t.Errorf("hit %d = %+v, want %+v", i, hit, w)
+				}
+				if hit.Critical {
+					t.Errorf("hit %d on %s is critical", i, hit.TowerID)
+				}
+				if tower := bob.TowerOfType(w.tower); tower.CurrentHP != w.hpRemaining {
+					t.Errorf("%s HP = %d, want %d", w.tower, tower.CurrentHP, w.hpRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		frozenTurns int // Attacks bob's Cannon already skips
+		bob         []models.TroopSpec
+		wantOK      bool
+		wantFrozen  map[string]int // Frozen turns of bob's buildings by ID
+	}{
+		{
+			name:       "first building that attacks",
+			bob:        []models.TroopSpec{wallSpec, cannonSpec},
+			wantOK:     true,
+			wantFrozen: map[string]int{"bob_Wall": 0, "bob_Cannon": 1},
+		},
+		{
+			name:       "named building",
+			target:     "bob_Wall",
+			bob:        []models.TroopSpec{wallSpec, cannonSpec},
+			wantOK:     true,
+			wantFrozen: map[string]int{"bob_Wall": 1, "bob_Cannon": 0},
+		},
+		{
+			name:        "adds to a freeze",
+			frozenTurns: 1,
+			bob:         []models.TroopSpec{cannonSpec},
+			wantOK:      true,
+			wantFrozen:  map[string]int{"bob_Cannon": 2},
+		},
+		{
+			name: "no building that attacks",
+			bob:  []models.TroopSpec{wallSpec},
+		},
+		{
+			name:   "unknown building",
+			target: "bob_Tesla",
+			bob:    []models.TroopSpec{cannonSpec},
+		},
+		{
+			name:   "towers cannot be frozen",
+			target: "bob_LEFT",
+			bob:    []models.TroopSpec{cannonSpec},
+		},
+		{
+			name:   "no building at all",
+			target: "bob_KING",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, shared.DamageFlat, 10, freezeSpec)
+			alice, bob := gs.GameState.PlayerA, gs.GameState.PlayerB
+			for _, spec := range tt.bob {
+				placeTestBuilding(bob, spec, 300, 3)
+			}
+			if cannon := bob.Building("bob_Cannon"); cannon != nil {
+				cannon.FrozenTurns = tt.frozenTurns
+			}
+
+			msg, ok := gs.DeployTroop("alice", "Freeze", tt.target)
+			if ok != tt.wantOK {
+				t.Fatalf("DeployTroop(Freeze, %q) ok = %v, want %v (%s)", tt.target, ok, tt.wantOK, msg)
+			}
+			if !ok {
+				if alice.CurrentMana != 10 {
+					t.Errorf("mana = %d after a refused freeze, want 10", alice.CurrentMana)
+				}
+				return
+			}
+			for id, want := range tt.wantFrozen {
+				if got := bob.Building(id).FrozenTurns; got != want {
+					t.Errorf("%s FrozenTurns = %d, want %d", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRage(t *testing.T) {
+	gs := newSpellSession(t, shared.DamageFlat, 20, rageSpec, rookSpec, rookSpec)
+	alice := gs.GameState.PlayerA
+	left := gs.GameState.PlayerB.TowerOfType("LEFT")
+
+	if msg, ok := gs.DeployTroop("alice", "Rage", ""); !ok {
+		t.Fatalf("DeployTroop(Rage) failed: %s", msg)
+	}
+	if alice.RagePercent != 50 {
+		t.Fatalf("RagePercent = %d, want 50", alice.RagePercent)
+	}
+
+	// A troop refused for its target keeps the rage for the next one
+	gs.GameState.CurrentTurn = "alice"
+	if _, ok := gs.DeployTroop("alice", "Rook", "bob_KING"); ok || alice.RagePercent != 50 {
+		t.Fatalf("DeployTroop(Rook) at a closed tower ok = %v, RagePercent = %d; want refused with 50", ok, alice.RagePercent)
+	}
+
+	// The Left Guard has 100 DEF: the raged Rook deals 200*1.5 - 100, the next one 200 - 100
+	for _, wantHP := range []int{800, 700} {
+		gs.GameState.CurrentTurn = "alice"
+		if msg, ok := gs.DeployTroop("alice", "Rook", "bob_LEFT"); !ok {
+			t.Fatalf("DeployTroop(Rook) failed: %s", msg)
+		}
+		if left.CurrentHP != wantHP {
+			t.Errorf("LEFT HP = %d, want %d", left.CurrentHP, wantHP)
+		}
+		if alice.RagePercent != 0 {
+			t.Errorf("RagePercent = %d after a troop attacked, want 0", alice.RagePercent)
+		}
+	}
+}
+
+func TestSpellDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		spec models.TroopSpec
+		want string
+	}{
+		{"fireball", fireballSpec, "Deals 300 damage to a tower, ignoring defense (50% splash)"},
+		{"freeze", freezeSpec, "An enemy building (not a tower) skips its next attack"},
+		{"rage", rageSpec, "Your next troop attacks with +50% ATK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spell := NewTroopInstance(&tt.spec, tt.spec.Name, 1)
+			if got := SpellDescription(spell, ComposeRuleSet("test", config.Default().Rules.Simple)); got != tt.want {
+				t.Errorf("SpellDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRageMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage string
+		target string
+		want   string
+	}{
+		{"raged troop", shared.DamageFlat, "bob_LEFT", "Rook dealt 200 damage (RAGE +50%) to bob_LEFT"},
+		{"raged critical hit", shared.DamageCritical, "bob_LEFT", "Rook dealt 260 damage (RAGE +50%) (CRITICAL HIT!) to bob_LEFT"},
+		{"raged troop against a building", shared.DamageFlat, "bob_Wall", "Rook (RAGE +50%) dealt 280 damage to bob_Wall"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newSpellSession(t, tt.damage, 10, rookSpec)
+			gs.GameState.PlayerA.RagePercent = 50
+			wall := wallSpec
+			wall.Protects = "RIGHT"
+			placeTestBuilding(gs.GameState.PlayerB, wall, 300, 3)
+
+			msg, ok := gs.DeployTroop("alice", "Rook", tt.target)
+			if !ok || !strings.Contains(msg, tt.want) {
+				t.Errorf("DeployTroop() = %q (ok %v), want it to contain %q", msg, ok, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package game
 
-import "tcr/internal/shared" // Added import for shared constants
+import (
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
 
 // GameState holds all information for a single game
 type GameState struct {
@@ -8,12 +12,27 @@ type GameState struct {
 	PlayerA *Player
 	PlayerB *Player
 
+	// Teammates of PlayerA and PlayerB in a 2v2 match, nil in 1v1. A partner has their own hand
+	// and mana but shares the towers of their side, which belong to PlayerA or PlayerB.
+	PartnerA *Player
+	PartnerB *Player
+
+	// Players in the order they take turns: A, B in 1v1 and A, B, partner A, partner B in 2v2
+	TurnOrder []*Player
+
 	// Current turn - stores the Username of the player whose turn it is
 	CurrentTurn string
 
+	// TurnNumber increases every time the turn passes to the next player
+	TurnNumber int
+
 	// Game status
 	IsGameOver bool
-	Winner     string // Empty if no winner yet, or PlayerA/PlayerB's username if there's a winner
+	Winner     string // Empty if no winner yet, or PlayerA/PlayerB's username if there's a winner (for their whole side in 2v2)
+	EndReason  string // Why the game ended (e.g. "King Tower destroyed"), empty while it is running
+
+	// Username of the player with a pending draw offer (made for their whole side in 2v2), empty if none
+	DrawOfferedBy string
 
 	// Track the last target destroyed (for the "Continue Attacking" rule)
 	LastDestroyedTowerID string
@@ -21,14 +40,35 @@ type GameState struct {
 
 	// Log of the last action taken for client display
 	LastActionLog string
+
+	// Towers hit by the last troop deployed, target first; nil once another action follows
+	LastHits []Hit
+
+	// Card copies awarded to the players when the match ended
+	CardRewards []models.CardReward
+
+	// Phase of a match played against the clock (REGULAR or OVERTIME), and when it runs out.
+	// PhaseEndsAt is set by whoever runs the clock; it stays zero if the match has no clock.
+	Phase       string
+	PhaseEndsAt time.Time
+
+	// Bonus the lower-level player started with; nil if the match has no handicap or levels are equal
+	Handicap *Handicap
+
+	// Rule set the match is played with (targeting, damage, mana, turns, win condition, EXP)
+	Rules RuleSet
 }
 
-// NewGameState creates a new game state with the given players
-func NewGameState(playerA, playerB *Player) *GameState {
+// NewGameState creates a new game state with the given players and rule set
+func NewGameState(playerA, playerB *Player, rules RuleSet) *GameState {
 	return &GameState{
 		PlayerA:              playerA,
 		PlayerB:              playerB,
+		TurnOrder:            []*Player{playerA, playerB},
+		Rules:                rules,
+		Phase:                shared.PhaseRegular,
 		CurrentTurn:          playerA.Username, // PlayerA starts by default
+		TurnNumber:           1,
 		IsGameOver:           false,
 		Winner:               "",
 		LastDestroyedTowerID: "",
@@ -37,47 +77,147 @@ func NewGameState(playerA, playerB *Player) *GameState {
 	}
 }
 
+// NewTeamGameState creates a new game state for a 2v2 match. The partners share the towers of
+// playerA and playerB, and turns rotate A, B, partner A, partner B.
+func NewTeamGameState(playerA, partnerA, playerB, partnerB *Player, rules RuleSet) *GameState {
+	gs := NewGameState(playerA, playerB, rules)
+	gs.PartnerA = partnerA
+	gs.PartnerB = partnerB
+	gs.TurnOrder = []*Player{playerA, playerB, partnerA, partnerB}
+	return gs
+}
+
+// IsTeamMatch checks if the match is played 2v2
+func (gs *GameState) IsTeamMatch() bool {
+	return gs.PartnerA != nil
+}
+
+// Player returns the player with the given username, or nil if they are not in this match
+func (gs *GameState) Player(username string) *Player {
+	for _, player := range gs.TurnOrder {
+		if player.Username == username {
+			return player
+		}
+	}
+	return nil
+}
+
+// Side returns the player owning the towers of the given player's side (PlayerA or PlayerB),
+// or nil if the username is not in this match
+func (gs *GameState) Side(username string) *Player {
+	switch {
+	case username == gs.PlayerA.Username || (gs.PartnerA != nil && username == gs.PartnerA.Username):
+		return gs.PlayerA
+	case username == gs.PlayerB.Username || (gs.PartnerB != nil && username == gs.PartnerB.Username):
+		return gs.PlayerB
+	}
+	return nil
+}
+
+// OpposingSide returns the player owning the towers of the other side than the given player's,
+// or nil if the username is not in this match
+func (gs *GameState) OpposingSide(username string) *Player {
+	switch gs.Side(username) {
+	case gs.PlayerA:
+		return gs.PlayerB
+	case gs.PlayerB:
+		return gs.PlayerA
+	}
+	return nil
+}
+
+// Team returns the players of a side (PlayerA or PlayerB, and their partner in 2v2)
+func (gs *GameState) Team(side *Player) []*Player {
+	if side == gs.PlayerA && gs.PartnerA != nil {
+		return []*Player{gs.PlayerA, gs.PartnerA}
+	}
+	if side == gs.PlayerB && gs.PartnerB != nil {
+		return []*Player{gs.PlayerB, gs.PartnerB}
+	}
+	return []*Player{side}
+}
+
+// TeamName returns how a side is named in messages: its player's username in 1v1, and both
+// usernames joined with "&" in 2v2
+func (gs *GameState) TeamName(side *Player) string {
+	team := gs.Team(side)
+	if len(team) == 1 {
+		return side.Username
+	}
+	return team[0].Username + " & " + team[1].Username
+}
+
+// SameSide checks if two players of the match play on the same side
+func (gs *GameState) SameSide(usernameA, usernameB string) bool {
+	side := gs.Side(usernameA)
+	return side != nil && side == gs.Side(usernameB)
+}
+
 // GetCurrentPlayer returns the player whose turn it is now
 func (gs *GameState) GetCurrentPlayer() *Player {
-	if gs.CurrentTurn == gs.PlayerA.Username {
-		return gs.PlayerA
+	if player := gs.Player(gs.CurrentTurn); player != nil {
+		return player
 	}
-	return gs.PlayerB
+	return gs.PlayerA
 }
 
-// GetOpponentPlayer returns the player who is not currently taking their turn
+// GetOpponentPlayer returns the player owning the towers the current player attacks
 func (gs *GameState) GetOpponentPlayer() *Player {
-	if gs.CurrentTurn == gs.PlayerA.Username {
+	if gs.Side(gs.CurrentTurn) == gs.PlayerA {
 		return gs.PlayerB
 	}
 	return gs.PlayerA
 }
 
-// SwitchTurn changes the turn to the other player and regenerates mana for the new current player.
+// nextInTurnOrder returns the player who plays after the given player
+func (gs *GameState) nextInTurnOrder(username string) *Player {
+	for i, player := range gs.TurnOrder {
+		if player.Username == username {
+			return gs.TurnOrder[(i+1)%len(gs.TurnOrder)]
+		}
+	}
+	return gs.TurnOrder[0]
+}
+
+// NextOpponent returns the first player of the other side to play after the given player
+func (gs *GameState) NextOpponent(username string) *Player {
+	for player := gs.nextInTurnOrder(username); player.Username != username; player = gs.nextInTurnOrder(player.Username) {
+		if !gs.SameSide(username, player.Username) {
+			return player
+		}
+	}
+	return gs.OpposingSide(username)
+}
+
+// SwitchTurn passes the turn to the next player in turn order and regenerates mana for them
+// if the rule set uses mana
 func (gs *GameState) SwitchTurn() {
 	// Determine the player whose turn it will become
-	var nextPlayer *Player
-	if gs.CurrentTurn == gs.PlayerA.Username {
-		gs.CurrentTurn = gs.PlayerB.Username
-		nextPlayer = gs.PlayerB
-	} else {
-		gs.CurrentTurn = gs.PlayerA.Username
-		nextPlayer = gs.PlayerA
-	}
+	nextPlayer := gs.nextInTurnOrder(gs.CurrentTurn)
+	gs.CurrentTurn = nextPlayer.Username
+	gs.TurnNumber++
 
 	// Regenerate mana for the player whose turn it now is
-	if nextPlayer != nil {
-		nextPlayer.CurrentMana += shared.ManaRegenRate
-		if nextPlayer.CurrentMana > shared.MaxMana {
-			nextPlayer.CurrentMana = shared.MaxMana
-		}
+	if nextPlayer != nil && gs.Rules.UsesMana() {
+		nextPlayer.GainMana(gs.Rules.TurnRegen(gs.InOvertime()), gs.Rules.MaxMana())
 	}
 
 	gs.CanContinueAttacking = false
+
+	// A draw offer lapses once the offering player's next turn begins
+	if gs.DrawOfferedBy == gs.CurrentTurn {
+		gs.DrawOfferedBy = ""
+	}
+}
+
+// InOvertime checks if the match is in sudden-death overtime
+func (gs *GameState) InOvertime() bool {
+	return gs.Phase == shared.PhaseOvertime
 }
 
 // SetWinner sets the winner of the game and marks the game as over
 func (gs *GameState) SetWinner(winnerUsername string) {
 	gs.IsGameOver = true
 	gs.Winner = winnerUsername
+	gs.LastHits = nil
 }
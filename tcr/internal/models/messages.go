@@ -17,6 +17,68 @@ const (
 	MsgTypeTurnNotification      = "TURN_NOTIFICATION"
 	MsgTypeGameOverNotification  = "GAME_OVER_NOTIFICATION"
 	MsgTypeSkipTurnCommand       = "SKIP_TURN_COMMAND"
+
+	// Match resolution messages
+	MsgTypeSurrender      = "SURRENDER"
+	MsgTypeOfferDraw      = "OFFER_DRAW"
+	MsgTypeAcceptDraw     = "ACCEPT_DRAW"
+	MsgTypeDeclineDraw    = "DECLINE_DRAW"
+	MsgTypeDrawOffered    = "DRAW_OFFERED"
+	MsgTypeRequestRematch = "REQUEST_REMATCH"
+	MsgTypeRematchOffered = "REMATCH_OFFERED"
+
+	// Matchmaking messages
+	MsgTypeJoinQueue   = "JOIN_QUEUE"
+	MsgTypeLeaveQueue  = "LEAVE_QUEUE"
+	MsgTypeQueueStatus = "QUEUE_STATUS"
+
+	// Private room and challenge messages
+	MsgTypeCreateRoom        = "CREATE_ROOM"
+	MsgTypeJoinRoom          = "JOIN_ROOM"
+	MsgTypeLeaveRoom         = "LEAVE_ROOM"
+	MsgTypeRoomReady         = "ROOM_READY"
+	MsgTypeRoomUpdate        = "ROOM_UPDATE"
+	MsgTypeChallengeRequest  = "CHALLENGE_REQUEST"
+	MsgTypeChallengeNotify   = "CHALLENGE_NOTIFICATION"
+	MsgTypeChallengeResponse = "CHALLENGE_RESPONSE"
+	MsgTypeChallengeResult   = "CHALLENGE_RESULT"
+
+	// Lobby presence messages
+	MsgTypeListPlayers    = "LIST_PLAYERS"
+	MsgTypePlayerList     = "PLAYER_LIST"
+	MsgTypePresenceUpdate = "PRESENCE_UPDATE"
+
+	// Spectator messages
+	MsgTypeListGames      = "LIST_GAMES"
+	MsgTypeGameList       = "GAME_LIST"
+	MsgTypeSpectateGame   = "SPECTATE_GAME"
+	MsgTypeStopSpectating = "STOP_SPECTATING"
+	MsgTypeSpectateStart  = "SPECTATE_START"
+	MsgTypeSpectateEnd    = "SPECTATE_END"
+	MsgTypeSpectatorCount = "SPECTATOR_COUNT"
+
+	// Chat messages
+	MsgTypeChatMessage  = "CHAT_MESSAGE"
+	MsgTypeMutePlayer   = "MUTE_PLAYER"
+	MsgTypeSystemNotice = "SYSTEM_NOTICE"
+
+	// Server messages
+	MsgTypeServerShutdown = "SERVER_SHUTDOWN"
+
+	// Card collection messages
+	MsgTypeGetCollection = "GET_COLLECTION"
+	MsgTypeSetDeck       = "SET_DECK"
+	MsgTypeCollection    = "COLLECTION"
+)
+
+// Presence statuses
+const (
+	PresenceIdle       = "IDLE"
+	PresenceInQueue    = "IN_QUEUE"
+	PresenceInRoom     = "IN_ROOM"
+	PresenceInGame     = "IN_GAME"
+	PresenceSpectating = "SPECTATING"
+	PresenceOffline    = "OFFLINE"
 )
 
 // GenericMessage is the wrapper for all network messages
@@ -55,6 +117,16 @@ type ErrorNotificationPayload struct {
 	ErrorMessage string `json:"errorMessage"` // Error message
 }
 
+// SystemNoticePayload is sent by server for informational notices that are not errors
+type SystemNoticePayload struct {
+	Message string `json:"message"` // Notice text
+}
+
+// ServerShutdownPayload is sent by server to every client before it shuts down
+type ServerShutdownPayload struct {
+	Reason string `json:"reason"` // Why the server is going down
+}
+
 // Phase 3 message payloads
 
 // DeployTroopCommandPayload is sent by client to deploy a troop
@@ -65,13 +137,31 @@ type DeployTroopCommandPayload struct {
 
 // TowerState represents the current state of a tower
 type TowerState struct {
-	ID        string `json:"id"`        // Tower ID
-	Type      string `json:"type"`      // Tower type (KING, GUARD1, GUARD2)
-	CurrentHP int    `json:"currentHP"` // Current health points
-	MaxHP     int    `json:"maxHP"`     // Maximum health points
-	Attack    int    `json:"attack"`    // Attack value
-	Defense   int    `json:"defense"`   // Defense value
-	Destroyed bool   `json:"destroyed"` // Whether the tower is destroyed
+	ID         string   `json:"id"`                 // Tower ID
+	Name       string   `json:"name"`               // Display name (e.g. "King Tower")
+	Type       string   `json:"type"`               // Tower type from towers.json (e.g. KING, GUARD1, GUARD2)
+	Role       string   `json:"role"`               // KING (destroying it wins the match) or GUARD
+	Requires   []string `json:"requires,omitempty"` // Types of the towers that must be destroyed before this one can be attacked
+	CurrentHP  int      `json:"currentHP"`          // Current health points
+	MaxHP      int      `json:"maxHP"`              // Maximum health points
+	Attack     int      `json:"attack"`             // Attack value
+	Defense    int      `json:"defense"`            // Defense value
+	Destroyed  bool     `json:"destroyed"`          // Whether the tower is destroyed
+	Targetable bool     `json:"targetable"`         // Whether the tower can be attacked right now
+}
+
+// BuildingState represents a building standing on a player's side
+type BuildingState struct {
+	ID          string `json:"id"`                    // Building ID, which troops may name as their target
+	Name        string `json:"name"`                  // Card name
+	Owner       string `json:"owner"`                 // Player who placed it
+	CurrentHP   int    `json:"currentHP"`             // Current health points
+	MaxHP       int    `json:"maxHP"`                 // Maximum health points
+	Attack      int    `json:"attack"`                // Damage dealt to an enemy building every turn of its side
+	Defense     int    `json:"defense"`               // Defense value
+	Protects    string `json:"protects,omitempty"`    // Tower type whose attacks it intercepts (omitted: every tower)
+	TurnsLeft   int    `json:"turnsLeft"`             // Turns of its side before it expires
+	FrozenTurns int    `json:"frozenTurns,omitempty"` // Attacks it will skip (FREEZE)
 }
 
 // TroopState represents the current state of a troop
@@ -81,27 +171,62 @@ type TroopState struct {
 	Attack   int    `json:"attack"`   // Attack value
 	Defense  int    `json:"defense"`  // Defense value
 	ManaCost int    `json:"manaCost"` // Mana cost of the troop (from TroopSpec)
+	CardType string `json:"cardType"` // TROOP, SPELL or BUILDING
+
+	Description string `json:"description,omitempty"` // What a spell does when cast
+
+	CritChancePercent       float64 `json:"critChancePercent,omitempty"`       // Chance of a critical hit in this match
+	CritMultiplier          float64 `json:"critMultiplier,omitempty"`          // Attack multiplier of a critical hit (omitted without critical hits)
+	ArmorPenetrationPercent int     `json:"armorPenetrationPercent,omitempty"` // Share of the target's defense ignored
+	PreferredTarget         string  `json:"preferredTarget,omitempty"`         // What the troop attacks when no target is named
+	SplashPercent           int     `json:"splashPercent,omitempty"`           // Share of the damage dealt to towers next to the target
+	ChainTargets            int     `json:"chainTargets,omitempty"`            // Further towers the attack jumps to
+	ChainFalloffPercent     int     `json:"chainFalloffPercent,omitempty"`     // Share of the damage lost on every jump
+	Lifetime                int     `json:"lifetime,omitempty"`                // Turns a building stands
+	Protects                string  `json:"protects,omitempty"`                // Tower type a building protects (omitted: every tower)
 }
 
 // PlayerState represents the current state of a player
 type PlayerState struct {
-	Username                string       `json:"username"`                // Player's username
-	KingTower               TowerState   `json:"kingTower"`               // King tower state
-	GuardTower1             TowerState   `json:"guardTower1"`             // Guard tower 1 state
-	GuardTower2             TowerState   `json:"guardTower2"`             // Guard tower 2 state
-	Troops                  []TroopState `json:"troops"`                  // Available troops
-	Level                   int          `json:"level"`                   // Player's current level
-	CurrentEXP              int          `json:"currentEXP"`              // Player's current EXP
-	RequiredEXPForNextLevel int          `json:"requiredEXPForNextLevel"` // EXP needed for next level
-	CurrentMana             int          `json:"currentMana"`             // Player's current mana
-	MaxMana                 int          `json:"maxMana"`                 // Player's maximum mana (e.g., 10)
+	Username                string          `json:"username"`                // Player's username
+	Towers                  []TowerState    `json:"towers"`                  // The player's towers, in towers.json order
+	Troops                  []TroopState    `json:"troops"`                  // Available troops (empty when the hand is hidden from the recipient)
+	Buildings               []BuildingState `json:"buildings,omitempty"`     // Buildings standing on the player's side
+	HandSize                int             `json:"handSize"`                // Number of troops in hand, always visible
+	Level                   int             `json:"level"`                   // Player's current level
+	CurrentEXP              int             `json:"currentEXP"`              // Player's current EXP
+	RequiredEXPForNextLevel int             `json:"requiredEXPForNextLevel"` // EXP needed for next level
+	CurrentMana             int             `json:"currentMana"`             // Player's current mana
+	MaxMana                 int             `json:"maxMana"`                 // Player's maximum mana (e.g., 10)
+	HandHidden              bool            `json:"handHidden,omitempty"`    // Troops were withheld from the recipient
+	ManaHidden              bool            `json:"manaHidden,omitempty"`    // CurrentMana was withheld from the recipient
+	RagePercent             int             `json:"ragePercent,omitempty"`   // ATK bonus of the player's next troop (RAGE)
 }
 
 // GameStartNotificationPayload is sent by server to notify clients that a game is starting
 type GameStartNotificationPayload struct {
-	OpponentUsername string      `json:"opponentUsername"` // Opponent's username
-	YourPlayerInfo   PlayerState `json:"yourPlayerInfo"`   // Your player info
-	GameMode         string      `json:"gameMode"`         // Game mode (SIMPLE or ENHANCED)
+	OpponentUsername string        `json:"opponentUsername"` // Opponent's username
+	YourPlayerInfo   PlayerState   `json:"yourPlayerInfo"`   // Your player info
+	GameMode         string        `json:"gameMode"`         // Game mode (SIMPLE or ENHANCED)
+	Settings         MatchSettings `json:"settings"`         // Match settings in effect
+	SpecVersion      int           `json:"specVersion"`      // Version of the troop and tower specs the match is played with
+	Targeting        string        `json:"targeting"`        // Targeting rule of the game mode (CLASSIC or LANES)
+
+	YourEffectiveLevel     int              `json:"yourEffectiveLevel"`     // Level your towers are scaled to (cards use their card level)
+	OpponentEffectiveLevel int              `json:"opponentEffectiveLevel"` // Level the opponent's towers are scaled to
+	Handicap               *HandicapPayload `json:"handicap,omitempty"`     // Bonus the lower-level player started with, if any
+
+	// Team (2v2) matches only
+	Teammate  string   `json:"teammate,omitempty"`  // Your teammate, who shares your towers
+	Opponents []string `json:"opponents,omitempty"` // Both opponents; OpponentUsername owns their towers
+	TurnOrder []string `json:"turnOrder,omitempty"` // Usernames in the order turns rotate
+}
+
+// HandicapPayload describes the handicap bonus a player started a match with
+type HandicapPayload struct {
+	Username string `json:"username"` // Player who received the bonus
+	Kind     string `json:"kind"`     // MANA or TOWER_HP
+	Amount   int    `json:"amount"`   // Bonus starting mana, or bonus tower HP in percent
 }
 
 // GameStateUpdatePayload is sent by server to update clients on the current game state
@@ -110,22 +235,272 @@ type GameStateUpdatePayload struct {
 	PlayerB       PlayerState `json:"playerB"`       // Player B state
 	CurrentTurn   string      `json:"currentTurn"`   // Username of player whose turn it is
 	LastActionLog string      `json:"lastActionLog"` // Optional description of last action
+
+	// Match clock, for matches played against the clock
+	Phase            string `json:"phase,omitempty"`            // REGULAR or OVERTIME (sudden death: the first tower destroyed wins)
+	PhaseSecondsLeft int    `json:"phaseSecondsLeft,omitempty"` // Seconds until the current phase runs out
+	ManaRegen        int    `json:"manaRegen"`                  // Mana each player gains per turn right now (raised during overtime)
+
+	// Towers hit by the troop deployed in the action this update follows, target first; omitted after other actions
+	Hits []HitResult `json:"hits,omitempty"`
+
+	// Teammates of player A and player B in a 2v2 match; their towers are those of the player they team up with
+	PartnerA *PlayerState `json:"partnerA,omitempty"`
+	PartnerB *PlayerState `json:"partnerB,omitempty"`
 }
 
 // ActionResultPayload is sent by server to notify client of the result of their action
 type ActionResultPayload struct {
-	Success bool   `json:"success"` // Whether the action was successful
-	Action  string `json:"action"`  // Description of the action
-	Message string `json:"message"` // Result message
+	Success bool        `json:"success"`        // Whether the action was successful
+	Action  string      `json:"action"`         // Description of the action
+	Message string      `json:"message"`        // Result message
+	Hits    []HitResult `json:"hits,omitempty"` // Towers hit by a deployed troop, target first
+}
+
+// HitResult is the result of an attack on one tower
+type HitResult struct {
+	TowerID     string `json:"towerId"`             // Tower that was hit, or the building if building is set
+	Kind        string `json:"kind"`                // PRIMARY (the target), SPLASH, CHAIN or BUILDING
+	Building    bool   `json:"building,omitempty"`  // Whether a building was hit
+	Damage      int    `json:"damage"`              // Damage dealt
+	Critical    bool   `json:"critical,omitempty"`  // Whether the attack was a critical hit
+	Destroyed   bool   `json:"destroyed,omitempty"` // Whether the hit destroyed the tower
+	HPRemaining int    `json:"hpRemaining"`         // HP of the tower after the hit
 }
 
 // TurnNotificationPayload is sent by server to notify client that it's their turn
 type TurnNotificationPayload struct {
-	CurrentTurnUsername string `json:"currentTurnUsername"` // Username of player whose turn it is
+	CurrentTurnUsername  string `json:"currentTurnUsername"`            // Username of player whose turn it is
+	TurnTimeLimitSeconds int    `json:"turnTimeLimitSeconds,omitempty"` // Seconds before the turn is skipped automatically (0 = no limit)
 }
 
 // GameOverNotificationPayload is sent by server to notify clients that the game is over
 type GameOverNotificationPayload struct {
-	WinnerUsername string `json:"winnerUsername"` // Username of the winner
-	Reason         string `json:"reason"`         // Reason for game end
+	WinnerUsername string       `json:"winnerUsername"`        // Username of the winner
+	Reason         string       `json:"reason"`                // Reason for game end
+	WinningTeam    []string     `json:"winningTeam,omitempty"` // Both winners of a 2v2 match
+	CardRewards    []CardReward `json:"cardRewards,omitempty"` // Card copies each player received
+}
+
+// CardReward is a number of copies of one card a player received at the end of a match
+type CardReward struct {
+	Username string `json:"username"`           // Player who received the copies
+	Card     string `json:"card"`               // Card name
+	Copies   int    `json:"copies"`             // Copies received
+	Level    int    `json:"level"`              // Card level afterwards
+	Upgraded bool   `json:"upgraded,omitempty"` // Whether the copies upgraded the card
+}
+
+// SetDeckPayload is sent by client to choose the cards they play with
+type SetDeckPayload struct {
+	Cards []string `json:"cards"` // Card names; empty plays with every unlocked card
+}
+
+// CollectionPayload is sent by server with a player's card collection, in reply to GET_COLLECTION and SET_DECK
+type CollectionPayload struct {
+	Level int              `json:"level"` // Player level, which unlocks cards
+	Cards []CollectionCard `json:"cards"` // Every card, in troops.json order
+	Deck  []string         `json:"deck"`  // Cards the player plays with (empty: every unlocked card)
+}
+
+// CollectionCard is one card as it stands in a player's collection
+type CollectionCard struct {
+	Name               string `json:"name"`                         // Card name
+	CardType           string `json:"cardType"`                     // TROOP, SPELL or BUILDING
+	ManaCost           int    `json:"manaCost"`                     // Mana cost of the card
+	UnlockLevel        int    `json:"unlockLevel"`                  // Player level that unlocks the card
+	Unlocked           bool   `json:"unlocked"`                     // Whether the card is in the collection
+	Level              int    `json:"level,omitempty"`              // Card level (unlocked cards only)
+	Copies             int    `json:"copies,omitempty"`             // Copies collected towards the next level
+	CopiesForNextLevel int    `json:"copiesForNextLevel,omitempty"` // Copies needed for the next level (omitted at the maximum)
+	InDeck             bool   `json:"inDeck,omitempty"`             // Whether the card is in the player's deck
+}
+
+// DrawOfferedPayload is sent by server to tell a player their opponent offers a draw
+type DrawOfferedPayload struct {
+	FromUsername string `json:"fromUsername"` // Player offering the draw
+}
+
+// RequestRematchPayload is sent by client after a game to play the same opponent again
+type RequestRematchPayload struct {
+	SwapSides bool `json:"swapSides"` // Let the player who moved second move first (only used by the first request)
+}
+
+// RematchOfferedPayload is sent by server to tell a player their last opponent wants a rematch
+type RematchOfferedPayload struct {
+	FromUsername     string `json:"fromUsername"`     // Player asking for the rematch
+	SwapSides        bool   `json:"swapSides"`        // Whether sides will be swapped
+	ExpiresInSeconds int    `json:"expiresInSeconds"` // Time left to answer with REQUEST_REMATCH
+}
+
+// Matchmaking message payloads
+
+// JoinQueuePayload is sent by client to join the matchmaking queue
+type JoinQueuePayload struct {
+	Modes    []string `json:"modes"`              // Acceptable game modes in order of preference (empty or ANY means any mode)
+	TeamSize int      `json:"teamSize,omitempty"` // 2 to queue for a 2v2 team match; 0 or 1 for 1v1
+}
+
+// QueueStatusPayload is sent by server to inform a client about its place in the matchmaking queue
+type QueueStatusPayload struct {
+	InQueue              bool     `json:"inQueue"`              // Whether the client is currently queued
+	Position             int      `json:"position"`             // 1-based position in the queue
+	QueueSize            int      `json:"queueSize"`            // Number of players in the queue
+	Modes                []string `json:"modes"`                // Modes the client is queued for
+	EstimatedWaitSeconds int      `json:"estimatedWaitSeconds"` // Estimated remaining wait time
+	Message              string   `json:"message"`              // Human-readable status message
+	TeamSize             int      `json:"teamSize,omitempty"`   // 2 if the client is queued for a 2v2 team match
+}
+
+// Private room and challenge payloads
+
+// MatchSettings holds the configurable settings of a match
+type MatchSettings struct {
+	Mode               string `json:"mode"`               // Game mode (SIMPLE or ENHANCED)
+	TurnTimerSeconds   int    `json:"turnTimerSeconds"`   // Seconds per turn before it is skipped automatically (0 = no timer)
+	StartingMana       int    `json:"startingMana"`       // Mana each player starts the match with
+	RevealOpponentMana bool   `json:"revealOpponentMana"` // Players and spectators may see each player's current mana
+	RuleSet            string `json:"ruleSet,omitempty"`  // Custom rule set from the server config; empty plays the mode's rules
+	LevelCap           int    `json:"levelCap,omitempty"` // Tournament-standard level: stats are scaled as if no player were above it (0 = no cap)
+	Handicap           string `json:"handicap,omitempty"` // Bonus for the lower-level player: MANA or TOWER_HP (empty = none)
+	TeamSize           int    `json:"teamSize,omitempty"` // Players per side: 2 for a 2v2 team match, 0 or 1 for 1v1
+}
+
+// MatchSettingsRequest holds the match settings a client asks for. Its StartingMana replaces the
+// one of MatchSettings so that leaving it out keeps the default of the mode instead of asking for 0.
+type MatchSettingsRequest struct {
+	MatchSettings
+	StartingMana *int `json:"startingMana,omitempty"` // Mana each player starts the match with (nil = default)
+}
+
+// CreateRoomPayload is sent by client to create a private room
+type CreateRoomPayload struct {
+	Settings MatchSettingsRequest `json:"settings"` // Match settings for the room
+}
+
+// JoinRoomPayload is sent by client to join a private room by its code
+type JoinRoomPayload struct {
+	RoomCode string `json:"roomCode"` // Shareable room code
+}
+
+// RoomReadyPayload is sent by client to mark themselves ready (or not) in their room
+type RoomReadyPayload struct {
+	Ready bool `json:"ready"` // Whether the player is ready to start
+}
+
+// RoomUpdatePayload is sent by server to room members whenever the room changes
+type RoomUpdatePayload struct {
+	RoomCode   string        `json:"roomCode"`   // Shareable room code (empty if the room was closed)
+	Host       string        `json:"host"`       // Username of the room creator
+	Guest      string        `json:"guest"`      // Username of the second player (empty if none)
+	HostReady  bool          `json:"hostReady"`  // Whether the host is ready
+	GuestReady bool          `json:"guestReady"` // Whether the guest is ready
+	Settings   MatchSettings `json:"settings"`   // Match settings for the room
+	Message    string        `json:"message"`    // Human-readable description of the change
+}
+
+// ChallengeRequestPayload is sent by client to challenge an online player directly
+type ChallengeRequestPayload struct {
+	TargetUsername string               `json:"targetUsername"` // Player being challenged
+	Settings       MatchSettingsRequest `json:"settings"`       // Match settings for the challenge
+}
+
+// ChallengeNotificationPayload is sent by server to the challenged player
+type ChallengeNotificationPayload struct {
+	ChallengerUsername string        `json:"challengerUsername"` // Player who sent the challenge
+	Settings           MatchSettings `json:"settings"`           // Match settings for the challenge
+	ExpiresInSeconds   int           `json:"expiresInSeconds"`   // Seconds until the challenge expires
+}
+
+// ChallengeResponsePayload is sent by the challenged client to accept or decline
+type ChallengeResponsePayload struct {
+	ChallengerUsername string `json:"challengerUsername"` // Player who sent the challenge
+	Accept             bool   `json:"accept"`             // Whether the challenge is accepted
+}
+
+// ChallengeResultPayload is sent by server to the challenger when the challenge is answered
+type ChallengeResultPayload struct {
+	TargetUsername string `json:"targetUsername"` // Player who was challenged
+	Accepted       bool   `json:"accepted"`       // Whether the challenge was accepted
+	Message        string `json:"message"`        // Human-readable result
+}
+
+// Lobby presence payloads
+
+// PlayerPresence describes an online player as shown in the lobby
+type PlayerPresence struct {
+	Username string `json:"username"` // Player's username
+	Status   string `json:"status"`   // IDLE, IN_QUEUE, IN_ROOM, IN_GAME or OFFLINE
+	Level    int    `json:"level"`    // Player's current level
+	Rating   int    `json:"rating"`   // Player's skill rating
+}
+
+// PlayerListPayload is sent by server in response to a LIST_PLAYERS request (and after login)
+type PlayerListPayload struct {
+	Players []PlayerPresence `json:"players"` // All online players
+}
+
+// PresenceUpdatePayload is pushed by server to logged-in clients when a player's presence changes
+type PresenceUpdatePayload struct {
+	Player PlayerPresence `json:"player"` // Updated presence (Status is OFFLINE when the player logged out)
+}
+
+// GameSummary describes a live match that can be spectated
+type GameSummary struct {
+	SessionID      string        `json:"sessionId"`          // ID used to spectate the match
+	PlayerA        string        `json:"playerA"`            // Username of player A
+	PlayerB        string        `json:"playerB"`            // Username of player B
+	PartnerA       string        `json:"partnerA,omitempty"` // Teammate of player A in a 2v2 match
+	PartnerB       string        `json:"partnerB,omitempty"` // Teammate of player B in a 2v2 match
+	Settings       MatchSettings `json:"settings"`           // Settings the match is played with
+	TurnNumber     int           `json:"turnNumber"`         // Number of the turn being played
+	SpectatorCount int           `json:"spectatorCount"`     // Number of players watching
+	SpecVersion    int           `json:"specVersion"`        // Version of the troop and tower specs the match is played with
+}
+
+// GameListPayload is sent by server in response to a list games request
+type GameListPayload struct {
+	Games []GameSummary `json:"games"` // All live matches
+}
+
+// SpectateGamePayload is sent by client to start watching a live match
+type SpectateGamePayload struct {
+	SessionID    string `json:"sessionId"`    // ID of the match to watch
+	DelaySeconds int    `json:"delaySeconds"` // Optional delay applied to everything the spectator receives
+}
+
+// SpectateStartPayload is sent by server when a client starts watching a match
+type SpectateStartPayload struct {
+	SessionID    string        `json:"sessionId"`          // ID of the match being watched
+	PlayerA      string        `json:"playerA"`            // Username of player A
+	PlayerB      string        `json:"playerB"`            // Username of player B
+	PartnerA     string        `json:"partnerA,omitempty"` // Teammate of player A in a 2v2 match
+	PartnerB     string        `json:"partnerB,omitempty"` // Teammate of player B in a 2v2 match
+	Settings     MatchSettings `json:"settings"`           // Settings the match is played with
+	DelaySeconds int           `json:"delaySeconds"`       // Delay applied to the spectator's updates
+}
+
+// SpectateEndPayload is sent by server when a client stops watching a match
+type SpectateEndPayload struct {
+	SessionID string `json:"sessionId"` // ID of the match that was watched
+	Message   string `json:"message"`   // Why spectating ended
+}
+
+// SpectatorCountPayload is sent by server to both players when their audience changes
+type SpectatorCountPayload struct {
+	Count int `json:"count"` // Number of players watching the match
+}
+
+// ChatMessagePayload is sent by client to chat, and relayed by server to everyone in the scope
+type ChatMessagePayload struct {
+	Scope  string `json:"scope"`            // LOBBY, ROOM, MATCH or TEAM
+	Sender string `json:"sender,omitempty"` // Username of the sender (set by server)
+	Text   string `json:"text,omitempty"`   // Message text
+	Emote  string `json:"emote,omitempty"`  // Quick emote name, only in MATCH or TEAM scope (replaces Text)
+}
+
+// MutePlayerPayload is sent by client to stop or resume receiving another player's chat
+type MutePlayerPayload struct {
+	Username string `json:"username"` // Player to mute or unmute
+	Mute     bool   `json:"mute"`     // True to mute, false to unmute
 }
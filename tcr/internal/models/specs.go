@@ -1,10 +1,19 @@
 package models
 
-// TroopSpec defines the specifications for a troop type
+import (
+	"tcr/internal/shared"
+	"time"
+)
+
+// TroopSpec defines the specifications for a card: a troop or a spell
 type TroopSpec struct {
 	// Name is the unique identifier for the troop
 	Name string `json:"Name"`
 
+	// CardType is "TROOP" (the default when empty), "SPELL" or "BUILDING". Spells act through their
+	// SpecialAbility without a troop body; buildings stand on their owner's side.
+	CardType string `json:"CardType"`
+
 	// BaseHP is the base hit points of the troop (at level 1)
 	BaseHP int `json:"BaseHP"`
 
@@ -23,9 +32,59 @@ type TroopSpec struct {
 	// SpecialAbility defines any special abilities the troop has (e.g., "HEAL_LOWEST_HP_TOWER_300" for Queen)
 	SpecialAbility string `json:"SpecialAbility"`
 
-	// IsSpecialOnly indicates if the troop only performs special abilities and doesn't engage in normal combat
-	// For example, Queen is marked as true since she only heals and doesn't attack/defend
+	// IsSpecialOnly indicates if the troop only performs special abilities and doesn't engage in normal combat.
+	// Older spec files mark spells this way instead of with CardType "SPELL".
 	IsSpecialOnly bool `json:"IsSpecialOnly"`
+
+	// SpellPower is the strength of a spell's effect: the ATK bonus percent of RAGE and the number of
+	// attacks FREEZE makes an enemy building skip. FREEZE only acts on buildings; towers cannot be
+	// frozen. FIREBALL deals the card's ATK instead.
+	SpellPower int `json:"SpellPower"`
+
+	// CritChancePercent is the troop's chance of a critical hit in modes with critical hits.
+	// 0 uses the mode's troop crit chance.
+	CritChancePercent float64 `json:"CritChancePercent"`
+
+	// CritMultiplier multiplies the troop's attack on a critical hit. 0 uses the mode's multiplier.
+	CritMultiplier float64 `json:"CritMultiplier"`
+
+	// ArmorPenetrationPercent is the share of the target's defense the troop ignores (0 to 100)
+	ArmorPenetrationPercent int `json:"ArmorPenetrationPercent"`
+
+	// PreferredTarget decides what the troop attacks when the player names no target:
+	// "TOWERS" (default), "TROOPS" or "LOWEST_HP"
+	PreferredTarget string `json:"PreferredTarget"`
+
+	// SplashPercent is the share of the damage also dealt to each open tower next to the target
+	// (a tower it requires or that requires it)
+	SplashPercent int `json:"SplashPercent"`
+
+	// ChainTargets is how many further open towers the attack jumps to after its target
+	ChainTargets int `json:"ChainTargets"`
+
+	// ChainFalloffPercent is the share of the damage lost on every jump of the chain
+	ChainFalloffPercent int `json:"ChainFalloffPercent"`
+
+	// Lifetime is the number of its side's turns a building stands before it expires
+	Lifetime int `json:"Lifetime"`
+
+	// Protects is the tower type whose attacks a building intercepts; empty protects every tower
+	Protects string `json:"Protects"`
+
+	// UnlockLevel is the player level at which the card joins the player's collection (0 or 1: from the start)
+	UnlockLevel int `json:"UnlockLevel"`
+}
+
+// Card returns the card type of the spec: CardType, or "SPELL" for specs only marked IsSpecialOnly
+func (spec *TroopSpec) Card() string {
+	switch {
+	case spec.CardType != "":
+		return spec.CardType
+	case spec.IsSpecialOnly:
+		return shared.CardSpell
+	default:
+		return shared.CardTroop
+	}
 }
 
 // TowerSpec defines the specifications for a tower type
@@ -33,9 +92,17 @@ type TowerSpec struct {
 	// Name is the display name of the tower (e.g., "King Tower", "Guard Tower")
 	Name string `json:"Name"`
 
-	// Type is the identifier for the tower type (e.g., "KING", "GUARD1", "GUARD2")
+	// Type is the unique identifier of the tower within the layout (e.g., "KING", "GUARD1", "GUARD2")
 	Type string `json:"Type"`
 
+	// Role is what the tower means to the game: destroying the "KING" tower wins the match,
+	// "GUARD" towers only protect other towers
+	Role string `json:"Role"`
+
+	// Requires lists the Types of the towers that must be destroyed before this tower can be attacked
+	// (e.g., ["GUARD1"] for the King Tower)
+	Requires []string `json:"Requires"`
+
 	// BaseHP is the base hit points of the tower (at level 1)
 	BaseHP int `json:"BaseHP"`
 
@@ -52,3 +119,22 @@ type TowerSpec struct {
 	// DestroyEXP is the experience points reward for destroying this tower
 	DestroyEXP int `json:"DestroyEXP"`
 }
+
+// SpecSet is one version of the troop and tower specs. Specs can be reloaded while the
+// server runs; every match keeps the set it started with.
+type SpecSet struct {
+	// Version numbers the sets loaded since the server started, beginning at 1
+	Version int `json:"version"`
+
+	// Checksum identifies the contents of the spec files the set was loaded from
+	Checksum string `json:"checksum"`
+
+	// LoadedAt is when the set was loaded
+	LoadedAt time.Time `json:"loadedAt"`
+
+	// Troops are the available troops
+	Troops []TroopSpec `json:"troops"`
+
+	// Towers are the towers every player starts with
+	Towers []TowerSpec `json:"towers"`
+}
@@ -0,0 +1,215 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+	"unicode/utf8"
+)
+
+// allowChat records a chat message from a client and reports whether it is within the rate limit.
+// Only called from the client's own connection goroutine.
+func (c *Client) allowChat(now time.Time) bool {
+	window := time.Duration(shared.ChatRateLimitWindowSeconds) * time.Second
+
+	// Drop messages that have left the window
+	recent := c.chatTimes[:0]
+	for _, sentAt := range c.chatTimes {
+		if now.Sub(sentAt) < window {
+			recent = append(recent, sentAt)
+		}
+	}
+	c.chatTimes = recent
+
+	if len(c.chatTimes) >= shared.ChatRateLimitMessages {
+		return false
+	}
+	c.chatTimes = append(c.chatTimes, now)
+	return true
+}
+
+// handleChatMessage handles a chat message or emote and relays it to everyone in its scope
+func (s *GameServer) handleChatMessage(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before chatting")
+		return
+	}
+
+	chatPayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid chat payload")
+		return
+	}
+
+	scope, _ := chatPayload["scope"].(string)
+	scope = strings.ToUpper(scope)
+	text, _ := chatPayload["text"].(string)
+	text = strings.TrimSpace(text)
+	emote, _ := chatPayload["emote"].(string)
+	emote = strings.ToUpper(emote)
+
+	if emote != "" {
+		if scope != shared.ChatScopeMatch && scope != shared.ChatScopeTeam {
+			sendError(client, "Emotes can only be used during a match")
+			return
+		}
+		if _, exists := shared.Emotes[emote]; !exists {
+			sendError(client, fmt.Sprintf("Unknown emote: %s", emote))
+			return
+		}
+		text = ""
+	} else {
+		if text == "" {
+			sendError(client, "Chat message is empty")
+			return
+		}
+		if utf8.RuneCountInString(text) > shared.MaxChatMessageLength {
+			sendError(client, fmt.Sprintf("Chat messages are limited to %d characters", shared.MaxChatMessageLength))
+			return
+		}
+	}
+
+	if !client.allowChat(time.Now()) {
+		sendError(client, fmt.Sprintf("You are sending messages too quickly (at most %d every %d seconds)",
+			shared.ChatRateLimitMessages, shared.ChatRateLimitWindowSeconds))
+		return
+	}
+
+	s.mutex.Lock()
+	recipients, err := s.chatRecipients(client, scope)
+	if err != nil {
+		s.mutex.Unlock()
+		sendError(client, err.Error())
+		return
+	}
+
+	// Skip recipients who muted the sender
+	unmuted := make([]*Client, 0, len(recipients))
+	for _, recipient := range recipients {
+		if !recipient.Muted[client.Username] {
+			unmuted = append(unmuted, recipient)
+		}
+	}
+	s.mutex.Unlock()
+
+	chatMsg := models.GenericMessage{
+		Type: models.MsgTypeChatMessage,
+		Payload: models.ChatMessagePayload{
+			Scope:  scope,
+			Sender: client.Username,
+			Text:   text,
+			Emote:  emote,
+		},
+	}
+
+	for _, recipient := range unmuted {
+		recipient.Send(chatMsg)
+	}
+}
+
+// chatRecipients returns everyone who should receive a message sent by a client in the given scope,
+// including the sender. The caller must hold s.mutex.
+func (s *GameServer) chatRecipients(client *Client, scope string) ([]*Client, error) {
+	switch scope {
+	case shared.ChatScopeLobby:
+		if client.InGame {
+			return nil, fmt.Errorf("you cannot chat in the lobby during a match")
+		}
+		recipients := make([]*Client, 0, len(s.Clients))
+		for _, online := range s.Clients {
+			if !online.InGame {
+				recipients = append(recipients, online)
+			}
+		}
+		return recipients, nil
+
+	case shared.ChatScopeRoom:
+		room, exists := s.Rooms[client.RoomCode]
+		if client.RoomCode == "" || !exists {
+			return nil, fmt.Errorf("you are not in a room")
+		}
+		recipients := []*Client{room.Host}
+		if room.Guest != nil {
+			recipients = append(recipients, room.Guest)
+		}
+		return recipients, nil
+
+	case shared.ChatScopeMatch:
+		session := s.getSessionForPlayer(client)
+		if session == nil {
+			return nil, fmt.Errorf("you are not in a game")
+		}
+		return session.players(), nil
+
+	case shared.ChatScopeTeam:
+		session := s.getSessionForPlayer(client)
+		if session == nil {
+			return nil, fmt.Errorf("you are not in a game")
+		}
+		teammate := session.teammateOf(client)
+		if teammate == nil {
+			return nil, fmt.Errorf("you have no teammate in this match")
+		}
+		return []*Client{client, teammate}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown chat scope: %s", scope)
+	}
+}
+
+// handleMutePlayer handles a request to mute or unmute another player's chat
+func (s *GameServer) handleMutePlayer(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before muting players")
+		return
+	}
+
+	mutePayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid mute payload")
+		return
+	}
+
+	username, ok := mutePayload["username"].(string)
+	if !ok || username == "" {
+		sendError(client, "Invalid username")
+		return
+	}
+	if username == client.Username {
+		sendError(client, "You cannot mute yourself")
+		return
+	}
+	mute, _ := mutePayload["mute"].(bool)
+
+	s.mutex.Lock()
+	if client.Muted == nil {
+		client.Muted = make(map[string]bool)
+	}
+	if mute {
+		client.Muted[username] = true
+	} else {
+		delete(client.Muted, username)
+	}
+	s.mutex.Unlock()
+
+	if mute {
+		log.Printf("%s muted %s", client.Username, username)
+		sendNotice(client, fmt.Sprintf("You will no longer see messages from %s.", username))
+	} else {
+		sendNotice(client, fmt.Sprintf("You will see messages from %s again.", username))
+	}
+}
+
+// sendNotice sends an informational notice to a client
+func sendNotice(client *Client, message string) {
+	noticeMsg := models.GenericMessage{
+		Type: models.MsgTypeSystemNotice,
+		Payload: models.SystemNoticePayload{
+			Message: message,
+		},
+	}
+	client.Send(noticeMsg)
+}
@@ -0,0 +1,37 @@
+package network
+
+import (
+	"tcr/internal/shared"
+	"testing"
+	"time"
+)
+
+func TestAllowChat(t *testing.T) {
+	window := time.Duration(shared.ChatRateLimitWindowSeconds) * time.Second
+	start := time.Now()
+
+	tests := []struct {
+		name  string
+		sent  int           // Messages already sent at the start of the window
+		after time.Duration // Time since the start of the window
+		want  bool
+	}{
+		{"first message", 0, 0, true},
+		{"below the limit", shared.ChatRateLimitMessages - 1, time.Second, true},
+		{"at the limit", shared.ChatRateLimitMessages, time.Second, false},
+		{"window has passed", shared.ChatRateLimitMessages, window, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{}
+			for i := 0; i < tt.sent; i++ {
+				if !client.allowChat(start) {
+					t.Fatalf("message %d was refused", i+1)
+				}
+			}
+			if got := client.allowChat(start.Add(tt.after)); got != tt.want {
+				t.Errorf("allowChat() after %d messages and %v = %v, want %v", tt.sent, tt.after, got, tt.want)
+			}
+		})
+	}
+}
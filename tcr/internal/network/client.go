@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"tcr/internal/models"
+	"tcr/internal/shared"
 )
 
 // GameClient represents the TCP game client
@@ -15,10 +16,14 @@ type GameClient struct {
 	LoggedIn     bool
 	PlayerID     string
 	InGame       bool
+	InQueue      bool
+	RoomCode     string
+	Spectating   string // ID of the game being watched, if any
 	MyTurn       bool
 	OpponentName string
 	GameMode     string
 	GameOver     bool
+	Spectators   int // Number of spectators watching this client's game
 	MessageCh    chan models.GenericMessage
 	DisconnectCh chan error
 }
@@ -147,6 +152,336 @@ func (c *GameClient) SendSkipTurnCommand() error {
 	return WriteMessage(c.conn, message)
 }
 
+// JoinQueue sends a request to join the matchmaking queue for the given modes
+// An empty list queues for any mode. A team size of 2 queues for 2v2 matches, 0 or 1 for 1v1.
+func (c *GameClient) JoinQueue(modes []string, teamSize int) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeJoinQueue,
+		Payload: models.JoinQueuePayload{
+			Modes:    modes,
+			TeamSize: teamSize,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// LeaveQueue sends a request to leave the matchmaking queue
+func (c *GameClient) LeaveQueue() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeLeaveQueue,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// CreateRoom sends a request to create a private room with the given match settings
+func (c *GameClient) CreateRoom(settings models.MatchSettingsRequest) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeCreateRoom,
+		Payload: models.CreateRoomPayload{
+			Settings: settings,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// JoinRoom sends a request to join a private room by its code
+func (c *GameClient) JoinRoom(roomCode string) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeJoinRoom,
+		Payload: models.JoinRoomPayload{
+			RoomCode: roomCode,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// LeaveRoom sends a request to leave the current private room
+func (c *GameClient) LeaveRoom() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeLeaveRoom,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// SetRoomReady marks this client as ready (or not ready) in its private room
+func (c *GameClient) SetRoomReady(ready bool) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeRoomReady,
+		Payload: models.RoomReadyPayload{
+			Ready: ready,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// Challenge sends a direct challenge to an online player
+func (c *GameClient) Challenge(targetUsername string, settings models.MatchSettingsRequest) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeChallengeRequest,
+		Payload: models.ChallengeRequestPayload{
+			TargetUsername: targetUsername,
+			Settings:       settings,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// RespondToChallenge accepts or declines a challenge from another player
+func (c *GameClient) RespondToChallenge(challengerUsername string, accept bool) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeChallengeResponse,
+		Payload: models.ChallengeResponsePayload{
+			ChallengerUsername: challengerUsername,
+			Accept:             accept,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// ListPlayers requests the list of online players
+func (c *GameClient) ListPlayers() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeListPlayers,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// GetCollection requests the player's card collection and deck
+func (c *GameClient) GetCollection() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeGetCollection,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// SetDeck chooses the cards the player plays with; no cards plays with every unlocked card
+func (c *GameClient) SetDeck(cards []string) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeSetDeck,
+		Payload: models.SetDeckPayload{
+			Cards: cards,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// ListGames requests the list of live games that can be spectated
+func (c *GameClient) ListGames() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeListGames,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// SpectateGame starts watching a live game, optionally delayed by the given number of seconds
+func (c *GameClient) SpectateGame(sessionID string, delaySeconds int) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeSpectateGame,
+		Payload: models.SpectateGamePayload{
+			SessionID:    sessionID,
+			DelaySeconds: delaySeconds,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// StopSpectating stops watching the current game
+func (c *GameClient) StopSpectating() error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeStopSpectating,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// Surrender concedes the current game
+func (c *GameClient) Surrender() error {
+	if !c.Connected || !c.InGame {
+		return fmt.Errorf("not in a game")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeSurrender,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// OfferDraw offers the opponent a draw
+func (c *GameClient) OfferDraw() error {
+	if !c.Connected || !c.InGame {
+		return fmt.Errorf("not in a game")
+	}
+
+	message := models.GenericMessage{
+		Type:    models.MsgTypeOfferDraw,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// RespondToDraw accepts or declines the opponent's draw offer
+func (c *GameClient) RespondToDraw(accept bool) error {
+	if !c.Connected || !c.InGame {
+		return fmt.Errorf("not in a game")
+	}
+
+	messageType := models.MsgTypeDeclineDraw
+	if accept {
+		messageType = models.MsgTypeAcceptDraw
+	}
+	message := models.GenericMessage{
+		Type:    messageType,
+		Payload: nil,
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// RequestRematch asks to play the last opponent again, optionally with sides swapped
+func (c *GameClient) RequestRematch(swapSides bool) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeRequestRematch,
+		Payload: models.RequestRematchPayload{
+			SwapSides: swapSides,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// SendChat sends a chat message to the given scope (LOBBY, ROOM or MATCH)
+func (c *GameClient) SendChat(scope, text string) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeChatMessage,
+		Payload: models.ChatMessagePayload{
+			Scope: scope,
+			Text:  text,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// SendEmote sends a quick emote to the current match
+func (c *GameClient) SendEmote(emote string) error {
+	if !c.Connected || !c.InGame {
+		return fmt.Errorf("not in a game")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeChatMessage,
+		Payload: models.ChatMessagePayload{
+			Scope: shared.ChatScopeMatch,
+			Emote: emote,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
+// MutePlayer stops (or resumes) showing chat from another player
+func (c *GameClient) MutePlayer(username string, mute bool) error {
+	if !c.Connected || !c.LoggedIn {
+		return fmt.Errorf("not logged in")
+	}
+
+	message := models.GenericMessage{
+		Type: models.MsgTypeMutePlayer,
+		Payload: models.MutePlayerPayload{
+			Username: username,
+			Mute:     mute,
+		},
+	}
+
+	return WriteMessage(c.conn, message)
+}
+
 // listen listens for messages from the server
 func (c *GameClient) listen() {
 	defer func() {
@@ -180,6 +515,16 @@ func (c *GameClient) listen() {
 			// Just forward to channel for display
 		case models.MsgTypeGameOverNotification:
 			c.handleGameOverNotification(message.Payload)
+		case models.MsgTypeQueueStatus:
+			c.handleQueueStatus(message.Payload)
+		case models.MsgTypeRoomUpdate:
+			c.handleRoomUpdate(message.Payload)
+		case models.MsgTypeSpectateStart:
+			c.handleSpectateStart(message.Payload)
+		case models.MsgTypeSpectateEnd:
+			c.Spectating = ""
+		case models.MsgTypeSpectatorCount:
+			c.handleSpectatorCount(message.Payload)
 		}
 
 		// Forward all messages to channel for processing by the main client
@@ -235,6 +580,11 @@ func (c *GameClient) handleGameStartNotification(payload interface{}) {
 
 	// Set in-game flag
 	c.InGame = true
+	c.InQueue = false
+	c.RoomCode = ""
+	c.Spectating = ""
+	c.Spectators = 0
+	c.GameOver = false
 }
 
 // handleGameStateUpdate handles a game state update from the server
@@ -267,3 +617,50 @@ func (c *GameClient) handleGameOverNotification(payload interface{}) {
 	c.MyTurn = false
 	c.GameOver = true
 }
+
+// handleQueueStatus handles a queue status update from the server
+func (c *GameClient) handleQueueStatus(payload interface{}) {
+	statusMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if inQueue, ok := statusMap["inQueue"].(bool); ok {
+		c.InQueue = inQueue
+	}
+}
+
+// handleRoomUpdate handles a room update from the server
+func (c *GameClient) handleRoomUpdate(payload interface{}) {
+	updateMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	// An empty room code means the client is no longer in a room
+	roomCode, _ := updateMap["roomCode"].(string)
+	c.RoomCode = roomCode
+}
+
+// handleSpectateStart handles the server confirming that the client is now spectating
+func (c *GameClient) handleSpectateStart(payload interface{}) {
+	startMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	sessionID, _ := startMap["sessionId"].(string)
+	c.Spectating = sessionID
+}
+
+// handleSpectatorCount handles a change in the number of spectators watching the client's game
+func (c *GameClient) handleSpectatorCount(payload interface{}) {
+	countMap, ok := payload.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if count, ok := countMap["count"].(float64); ok {
+		c.Spectators = int(count)
+	}
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"log"
+	"tcr/internal/game"
+	"tcr/internal/models"
+)
+
+// handleGetCollection sends a player their card collection
+func (s *GameServer) handleGetCollection(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before viewing your collection")
+		return
+	}
+	s.sendCollection(client)
+}
+
+// handleSetDeck handles a player choosing the cards they play with. The deck must only hold
+// unlocked cards; an empty deck plays with every unlocked card.
+func (s *GameServer) handleSetDeck(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before choosing a deck")
+		return
+	}
+
+	deckPayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid deck payload")
+		return
+	}
+	cardList, _ := deckPayload["cards"].([]interface{})
+	deck := make([]string, 0, len(cardList))
+	for _, card := range cardList {
+		name, ok := card.(string)
+		if !ok {
+			sendError(client, "Invalid card name")
+			return
+		}
+		deck = append(deck, name)
+	}
+
+	// The match saves the deck it started with, so it cannot change during a match
+	s.mutex.Lock()
+	inGame := client.InGame
+	specs := s.Specs
+	s.mutex.Unlock()
+	if inGame {
+		sendError(client, "You cannot change your deck during a match")
+		return
+	}
+
+	profile, err := s.JSONHandler.LoadPlayerData(client.Username)
+	if err != nil {
+		log.Printf("Error loading player data for %s: %v", client.Username, err)
+		sendError(client, "Could not load your collection")
+		return
+	}
+	if len(deck) > 0 {
+		if err := game.ValidateDeck(specs.Troops, profile.Level, deck); err != nil {
+			sendError(client, "Invalid deck: "+err.Error())
+			return
+		}
+	}
+
+	profile.Deck = deck
+	if err := s.JSONHandler.SavePlayerData(profile); err != nil {
+		log.Printf("Error saving deck of %s: %v", client.Username, err)
+		sendError(client, "Could not save your deck")
+		return
+	}
+	s.sendCollection(client)
+}
+
+// sendCollection sends a player's card collection as of the current specs
+func (s *GameServer) sendCollection(client *Client) {
+	profile, err := s.JSONHandler.LoadPlayerData(client.Username)
+	if err != nil {
+		log.Printf("Error loading player data for %s: %v", client.Username, err)
+		sendError(client, "Could not load your collection")
+		return
+	}
+
+	s.mutex.Lock()
+	specs := s.Specs
+	s.mutex.Unlock()
+
+	deck := profile.Deck
+	if deck == nil {
+		deck = []string{}
+	}
+	client.Send(models.GenericMessage{
+		Type: models.MsgTypeCollection,
+		Payload: models.CollectionPayload{
+			Level: profile.Level,
+			Cards: game.CollectionCards(specs.Troops, profile),
+			Deck:  deck,
+		},
+	})
+}
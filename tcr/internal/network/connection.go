@@ -0,0 +1,106 @@
+package network
+
+import (
+	"errors"
+	"log"
+	"net"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
+
+var (
+	// ErrClientClosed is returned when sending to a client whose connection has been shut down
+	ErrClientClosed = errors.New("client connection closed")
+	// ErrSendQueueFull is returned when a client does not read its messages fast enough
+	ErrSendQueueFull = errors.New("client send queue full")
+)
+
+// newClient creates a server-side client for a connection and starts its writer goroutine
+func newClient(conn net.Conn, writeTimeout time.Duration) *Client {
+	client := &Client{
+		Conn:         conn,
+		InGame:       false,
+		writeTimeout: writeTimeout,
+		outbound:     make(chan models.GenericMessage, shared.ClientSendBufferSize),
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go client.writeLoop()
+	return client
+}
+
+// Send queues a message for the client. It never blocks: a client whose queue is full
+// is too slow to keep up and is disconnected.
+func (c *Client) Send(message models.GenericMessage) error {
+	select {
+	case <-c.done:
+		return ErrClientClosed
+	default:
+	}
+
+	select {
+	case c.outbound <- message:
+		return nil
+	case <-c.done:
+		return ErrClientClosed
+	default:
+		log.Printf("Send queue of %s (%s) is full, disconnecting slow client", c.Username, c.Conn.RemoteAddr())
+		c.Close()
+		return ErrSendQueueFull
+	}
+}
+
+// Close shuts down the client's connection. Messages still queued are dropped.
+// Safe to call more than once and from any goroutine.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.Conn.Close()
+	})
+}
+
+// closeAfterFlush closes the client's connection once the messages already queued have been written
+func (c *Client) closeAfterFlush() {
+	c.closingOnce.Do(func() {
+		close(c.closing)
+	})
+}
+
+// writeLoop is the only goroutine that writes to the client's connection, so frames never interleave
+func (c *Client) writeLoop() {
+	for {
+		select {
+		case message := <-c.outbound:
+			if !c.write(message) {
+				return
+			}
+		case <-c.closing:
+			// Write out what is left, then hang up
+			for {
+				select {
+				case message := <-c.outbound:
+					if !c.write(message) {
+						return
+					}
+				default:
+					c.Close()
+					return
+				}
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write sends one message to the client, closing the connection if that fails
+func (c *Client) write(message models.GenericMessage) bool {
+	c.Conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if err := WriteMessage(c.Conn, message); err != nil {
+		log.Printf("Error writing %s to %s: %v", message.Type, c.Conn.RemoteAddr(), err)
+		c.Close()
+		return false
+	}
+	return true
+}
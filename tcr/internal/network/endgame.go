@@ -0,0 +1,268 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"time"
+)
+
+// Rematch is a pending offer for the two players of a finished game to play again
+type Rematch struct {
+	PlayerA     *Client // Player A (who moved first) in the finished game
+	PlayerB     *Client
+	Settings    models.MatchSettings
+	RequestedBy *Client // First player to ask for the rematch, nil until then
+	SwapSides   bool    // Proposed by RequestedBy: player B of the finished game moves first
+	ExpiresAt   time.Time
+}
+
+// other returns the player of the rematch who is not the given client
+func (r *Rematch) other(client *Client) *Client {
+	if client == r.PlayerA {
+		return r.PlayerB
+	}
+	return r.PlayerA
+}
+
+// sessionForAction returns the game session a client may act in, sending an error if there is none
+func (s *GameServer) sessionForAction(client *Client) *GameSession {
+	s.mutex.Lock()
+	session := s.getSessionForPlayer(client)
+	spectating := client.Spectating != ""
+	s.mutex.Unlock()
+
+	if spectating {
+		sendError(client, "Spectators cannot act in a game")
+		return nil
+	}
+	if session == nil {
+		sendError(client, "You are not in a game")
+		return nil
+	}
+	return session
+}
+
+// submitAction queues a game action of a client on the event loop of the session it plays in
+func (s *GameServer) submitAction(client *Client, action func(session *GameSession)) {
+	session := s.sessionForAction(client)
+	if session == nil {
+		return
+	}
+
+	if !session.post(func() { action(session) }) {
+		sendError(client, "The game is already over")
+	}
+}
+
+// handleSurrender handles a player conceding the game
+func (s *GameServer) handleSurrender(client *Client, payload interface{}) {
+	s.submitAction(client, func(session *GameSession) {
+		s.surrender(session, client)
+	})
+}
+
+// surrender ends the game in the opponent's favour. Runs on the session's event loop.
+func (s *GameServer) surrender(session *GameSession, client *Client) {
+	actionResultMsg, success := session.GameEngine.Surrender(client.Username)
+	if !success {
+		sendError(client, actionResultMsg)
+		return
+	}
+
+	s.broadcastGameState(session, actionResultMsg)
+	s.handleGameOver(session)
+}
+
+// handleOfferDraw handles a player offering a draw to their opponent
+func (s *GameServer) handleOfferDraw(client *Client, payload interface{}) {
+	s.submitAction(client, func(session *GameSession) {
+		s.offerDraw(session, client)
+	})
+}
+
+// offerDraw records a draw offer and passes it on to the opponent (in 2v2, to both opponents)
+func (s *GameServer) offerDraw(session *GameSession, client *Client) {
+	actionResultMsg, success := session.GameEngine.OfferDraw(client.Username)
+	if !success {
+		sendError(client, actionResultMsg)
+		return
+	}
+
+	gameState := session.GameEngine.GameState
+	sendNotice(client, fmt.Sprintf("You offered a draw to %s.", gameState.TeamName(gameState.OpposingSide(client.Username))))
+	if teammate := session.teammateOf(client); teammate != nil {
+		sendNotice(teammate, fmt.Sprintf("%s offered a draw for your team.", client.Username))
+	}
+	for _, opponent := range session.opponentsOf(client) {
+		opponent.Send(models.GenericMessage{
+			Type: models.MsgTypeDrawOffered,
+			Payload: models.DrawOfferedPayload{
+				FromUsername: client.Username,
+			},
+		})
+	}
+}
+
+// handleAcceptDraw handles a player accepting their opponent's draw offer
+func (s *GameServer) handleAcceptDraw(client *Client, payload interface{}) {
+	s.submitAction(client, func(session *GameSession) {
+		s.acceptDraw(session, client)
+	})
+}
+
+// acceptDraw ends the game as a draw if the opponent offered one
+func (s *GameServer) acceptDraw(session *GameSession, client *Client) {
+	actionResultMsg, success := session.GameEngine.AcceptDraw(client.Username)
+	if !success {
+		sendError(client, actionResultMsg)
+		return
+	}
+
+	s.broadcastGameState(session, actionResultMsg)
+	s.handleGameOver(session)
+}
+
+// handleDeclineDraw handles a player declining their opponent's draw offer
+func (s *GameServer) handleDeclineDraw(client *Client, payload interface{}) {
+	s.submitAction(client, func(session *GameSession) {
+		s.declineDraw(session, client)
+	})
+}
+
+// declineDraw turns down the pending draw offer
+func (s *GameServer) declineDraw(session *GameSession, client *Client) {
+	actionResultMsg, success := session.GameEngine.DeclineDraw(client.Username)
+	if !success {
+		sendError(client, actionResultMsg)
+		return
+	}
+
+	sendNotice(client, "You declined the draw offer.")
+	if teammate := session.teammateOf(client); teammate != nil {
+		sendNotice(teammate, fmt.Sprintf("%s declined the draw offer.", client.Username))
+	}
+	for _, opponent := range session.opponentsOf(client) {
+		sendNotice(opponent, fmt.Sprintf("%s declined your draw offer.", client.Username))
+	}
+}
+
+// offerRematch lets the players of a finished session ask for a rematch for a limited time.
+// The caller must hold s.mutex.
+func (s *GameServer) offerRematch(session *GameSession) {
+	playerA, playerB := session.PlayerA, session.PlayerB
+	if s.Clients[playerA.Username] != playerA || s.Clients[playerB.Username] != playerB {
+		return // A rematch needs both players online
+	}
+
+	rematch := &Rematch{
+		PlayerA:   playerA,
+		PlayerB:   playerB,
+		Settings:  session.Settings,
+		ExpiresAt: time.Now().Add(config.Seconds(s.Config.Timeouts.RematchSeconds)),
+	}
+	s.Rematches[playerA.Username] = rematch
+	s.Rematches[playerB.Username] = rematch
+
+	time.AfterFunc(config.Seconds(s.Config.Timeouts.RematchSeconds), func() {
+		s.expireRematch(rematch)
+	})
+}
+
+// handleRequestRematch handles a request to play the last opponent again.
+// The first request is offered to the opponent; the game starts once both have asked.
+func (s *GameServer) handleRequestRematch(client *Client, payload interface{}) {
+	swapSides := false
+	if rematchPayload, ok := payload.(map[string]interface{}); ok {
+		swapSides, _ = rematchPayload["swapSides"].(bool)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rematch, exists := s.Rematches[client.Username]
+	if !exists || time.Now().After(rematch.ExpiresAt) {
+		sendError(client, "No rematch is available")
+		return
+	}
+	if client.InGame {
+		sendError(client, "You are already in a game")
+		return
+	}
+
+	opponent := rematch.other(client)
+	if s.Clients[opponent.Username] != opponent || opponent.InGame {
+		s.clearRematch(client, "")
+		sendError(client, fmt.Sprintf("%s is no longer available for a rematch", opponent.Username))
+		return
+	}
+
+	if rematch.RequestedBy == nil || rematch.RequestedBy == client {
+		rematch.RequestedBy = client
+		rematch.SwapSides = swapSides
+		log.Printf("Player %s requested a rematch against %s", client.Username, opponent.Username)
+
+		sendNotice(client, fmt.Sprintf("Rematch requested. Waiting for %s...", opponent.Username))
+		opponent.Send(models.GenericMessage{
+			Type: models.MsgTypeRematchOffered,
+			Payload: models.RematchOfferedPayload{
+				FromUsername:     client.Username,
+				SwapSides:        swapSides,
+				ExpiresInSeconds: int(time.Until(rematch.ExpiresAt).Seconds()),
+			},
+		})
+		return
+	}
+
+	// Both players asked: start the new game right away
+	delete(s.Rematches, rematch.PlayerA.Username)
+	delete(s.Rematches, rematch.PlayerB.Username)
+
+	for _, player := range []*Client{rematch.PlayerA, rematch.PlayerB} {
+		s.MatchQueue.Remove(player.Username)
+		if player.RoomCode != "" {
+			s.leaveRoom(player, fmt.Sprintf("%s started a rematch.", player.Username))
+		}
+	}
+
+	log.Printf("Starting rematch between %s and %s (sides swapped: %t)", rematch.PlayerA.Username, rematch.PlayerB.Username, rematch.SwapSides)
+	if rematch.SwapSides {
+		s.createGameSession(rematch.PlayerB, rematch.PlayerA, rematch.Settings)
+	} else {
+		s.createGameSession(rematch.PlayerA, rematch.PlayerB, rematch.Settings)
+	}
+}
+
+// expireRematch removes a rematch offer that was not taken up in time
+func (s *GameServer) expireRematch(rematch *Rematch) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The rematch may already have started or been cleared
+	if s.Rematches[rematch.PlayerA.Username] != rematch {
+		return
+	}
+	delete(s.Rematches, rematch.PlayerA.Username)
+	delete(s.Rematches, rematch.PlayerB.Username)
+
+	if rematch.RequestedBy != nil {
+		sendNotice(rematch.RequestedBy, "Your rematch request expired.")
+	}
+}
+
+// clearRematch removes the rematch offer involving a client. If the offer had been requested,
+// the other player is told why it is no longer available (unless reason is empty).
+// The caller must hold s.mutex.
+func (s *GameServer) clearRematch(client *Client, reason string) {
+	rematch, exists := s.Rematches[client.Username]
+	if !exists {
+		return
+	}
+	delete(s.Rematches, rematch.PlayerA.Username)
+	delete(s.Rematches, rematch.PlayerB.Username)
+
+	if rematch.RequestedBy != nil && reason != "" {
+		sendNotice(rematch.other(client), reason)
+	}
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"log"
+	"sort"
+	"tcr/internal/models"
+)
+
+// presenceStatus returns the lobby status of a client.
+// The caller must hold s.mutex.
+func (s *GameServer) presenceStatus(client *Client) string {
+	if online, exists := s.Clients[client.Username]; !exists || online != client {
+		return models.PresenceOffline
+	}
+	if client.InGame {
+		return models.PresenceInGame
+	}
+	if client.RoomCode != "" {
+		return models.PresenceInRoom
+	}
+	if s.MatchQueue.Contains(client.Username) {
+		return models.PresenceInQueue
+	}
+	if client.Spectating != "" {
+		return models.PresenceSpectating
+	}
+	return models.PresenceIdle
+}
+
+// playerPresence builds the presence entry of a client.
+// The caller must hold s.mutex.
+func (s *GameServer) playerPresence(client *Client) models.PlayerPresence {
+	return models.PlayerPresence{
+		Username: client.Username,
+		Status:   s.presenceStatus(client),
+		Level:    client.Level,
+		Rating:   client.Rating,
+	}
+}
+
+// handleListPlayers handles a request for the list of online players
+func (s *GameServer) handleListPlayers(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before listing players")
+		return
+	}
+	s.sendPlayerList(client)
+}
+
+// sendPlayerList sends the list of all online players to a client
+func (s *GameServer) sendPlayerList(client *Client) {
+	s.mutex.Lock()
+	players := make([]models.PlayerPresence, 0, len(s.Clients))
+	for _, online := range s.Clients {
+		players = append(players, s.playerPresence(online))
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Username < players[j].Username
+	})
+
+	listMsg := models.GenericMessage{
+		Type: models.MsgTypePlayerList,
+		Payload: models.PlayerListPayload{
+			Players: players,
+		},
+	}
+	client.Send(listMsg)
+}
+
+// notifyPresence queues a presence update for a client. The update is computed and
+// broadcast asynchronously, so this is safe to call with or without s.mutex held.
+func (s *GameServer) notifyPresence(client *Client) {
+	if client == nil || client.Username == "" {
+		return
+	}
+
+	select {
+	case s.presenceUpdates <- client:
+	default:
+		log.Printf("Presence update queue full, dropping update for %s", client.Username)
+	}
+}
+
+// runPresenceBroadcaster sends queued presence updates to every logged-in client
+func (s *GameServer) runPresenceBroadcaster() {
+	for client := range s.presenceUpdates {
+		s.mutex.Lock()
+		updateMsg := models.GenericMessage{
+			Type: models.MsgTypePresenceUpdate,
+			Payload: models.PresenceUpdatePayload{
+				Player: s.playerPresence(client),
+			},
+		}
+		recipients := make([]*Client, 0, len(s.Clients))
+		for _, online := range s.Clients {
+			recipients = append(recipients, online)
+		}
+		s.mutex.Unlock()
+
+		for _, recipient := range recipients {
+			recipient.Send(updateMsg)
+		}
+	}
+}
+
+// updateClientProfiles copies the post-match level and rating of every player back to their clients
+func updateClientProfiles(session *GameSession) {
+	gameState := session.GameEngine.GameState
+	for _, client := range session.players() {
+		if player := gameState.Player(client.Username); player != nil {
+			client.Level = player.Level
+			client.Rating = player.Rating
+		}
+	}
+}
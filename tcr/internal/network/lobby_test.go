@@ -0,0 +1,36 @@
+package network
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"testing"
+)
+
+func TestPresenceStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(s *GameServer, client *Client)
+		online bool
+		want   string
+	}{
+		{"not logged in", func(s *GameServer, client *Client) {}, false, models.PresenceOffline},
+		{"idle", func(s *GameServer, client *Client) {}, true, models.PresenceIdle},
+		{"in queue", func(s *GameServer, client *Client) { s.MatchQueue.Add(client, 1200, nil, 1) }, true, models.PresenceInQueue},
+		{"in room", func(s *GameServer, client *Client) { client.RoomCode = "ABCD" }, true, models.PresenceInRoom},
+		{"in game beats room", func(s *GameServer, client *Client) { client.RoomCode = "ABCD"; client.InGame = true }, true, models.PresenceInGame},
+		{"replaced by a newer login", func(s *GameServer, client *Client) { s.Clients[client.Username] = &Client{Username: client.Username} }, true, models.PresenceOffline},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(config.Default(), nil)
+			client := &Client{Username: "alice"}
+			if tt.online {
+				server.Clients[client.Username] = client
+			}
+			tt.setup(server, client)
+			if got := server.presenceStatus(client); got != tt.want {
+				t.Errorf("presenceStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
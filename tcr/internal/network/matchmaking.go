@@ -0,0 +1,459 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"tcr/internal/config"
+	"tcr/internal/game"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
+
+// MatchQueueEntry represents a player waiting in the matchmaking queue
+type MatchQueueEntry struct {
+	Client   *Client
+	Rating   int       // Player's skill rating when they joined the queue
+	Modes    []string  // Acceptable game modes in order of preference
+	TeamSize int       // shared.TeamMatchSize for players waiting for a 2v2 match, 1 otherwise
+	JoinedAt time.Time // Used to widen the accepted rating gap over time
+}
+
+// MatchQueue holds the players waiting for a rated match, oldest first
+type MatchQueue struct {
+	Entries     []*MatchQueueEntry
+	Settings    config.MatchmakingConfig // Rating gaps, wait estimates and timing of the queue
+	recentWaits []time.Duration          // Wait times of the most recent matches, used for estimates
+}
+
+// NewMatchQueue creates an empty matchmaking queue
+func NewMatchQueue(settings config.MatchmakingConfig) *MatchQueue {
+	return &MatchQueue{
+		Entries:     make([]*MatchQueueEntry, 0),
+		Settings:    settings,
+		recentWaits: make([]time.Duration, 0, settings.WaitSampleSize),
+	}
+}
+
+// Add puts a client at the back of the queue, waiting for a match with the given team size
+func (q *MatchQueue) Add(client *Client, rating int, modes []string, teamSize int) {
+	q.Entries = append(q.Entries, &MatchQueueEntry{
+		Client:   client,
+		Rating:   rating,
+		Modes:    modes,
+		TeamSize: teamSize,
+		JoinedAt: time.Now(),
+	})
+}
+
+// Contains checks if a player with the given username is already queued
+func (q *MatchQueue) Contains(username string) bool {
+	return q.indexOf(username) != -1
+}
+
+// Remove takes a player out of the queue. Returns false if the player was not queued.
+func (q *MatchQueue) Remove(username string) bool {
+	index := q.indexOf(username)
+	if index == -1 {
+		return false
+	}
+	q.Entries = append(q.Entries[:index], q.Entries[index+1:]...)
+	return true
+}
+
+// indexOf returns the position of a player in the queue, or -1 if not found
+func (q *MatchQueue) indexOf(username string) int {
+	for i, entry := range q.Entries {
+		if entry.Client.Username == username {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindMatch looks for the best pair of queued 1v1 players and the mode they will play.
+// Players are considered oldest first; each is paired with the closest-rated opponent that shares
+// a game mode and whose rating gap is within what the longer-waiting of the two accepts.
+// Returns nil, nil, "" if no pair qualifies.
+func (q *MatchQueue) FindMatch(now time.Time) (*MatchQueueEntry, *MatchQueueEntry, string) {
+	for i, entry := range q.Entries {
+		if entry.TeamSize == shared.TeamMatchSize {
+			continue
+		}
+
+		var bestOpponent *MatchQueueEntry
+		bestGap := -1
+		bestMode := ""
+
+		for j, candidate := range q.Entries {
+			if i == j || candidate.TeamSize == shared.TeamMatchSize {
+				continue
+			}
+
+			mode := commonMode(entry.Modes, candidate.Modes)
+			if mode == "" {
+				continue
+			}
+
+			gap := absInt(entry.Rating - candidate.Rating)
+			if gap > q.allowedGap(now, entry, candidate) {
+				continue
+			}
+			if bestOpponent == nil || gap < bestGap {
+				bestOpponent = candidate
+				bestGap = gap
+				bestMode = mode
+			}
+		}
+
+		if bestOpponent != nil {
+			return entry, bestOpponent, bestMode
+		}
+	}
+	return nil, nil, ""
+}
+
+// FindTeamMatch looks for four queued 2v2 players who share a game mode, and splits them into two
+// teams of equal strength: the highest and lowest rated against the two in between. Players are
+// considered oldest first; each is grouped with the three closest-rated players that accept one of
+// their modes (in order of preference) and whose rating gap to them is within what the longer-waiting
+// of the two accepts. The first player of each team owns its towers.
+// Returns an empty mode if no group qualifies.
+func (q *MatchQueue) FindTeamMatch(now time.Time) ([2]*MatchQueueEntry, [2]*MatchQueueEntry, string) {
+	for i, entry := range q.Entries {
+		if entry.TeamSize != shared.TeamMatchSize {
+			continue
+		}
+
+		for _, mode := range entry.Modes {
+			candidates := make([]*MatchQueueEntry, 0)
+			for j, candidate := range q.Entries {
+				if i == j || candidate.TeamSize != shared.TeamMatchSize || commonMode(candidate.Modes, []string{mode}) == "" {
+					continue
+				}
+				if absInt(entry.Rating-candidate.Rating) <= q.allowedGap(now, entry, candidate) {
+					candidates = append(candidates, candidate)
+				}
+			}
+			if len(candidates) < 2*shared.TeamMatchSize-1 {
+				continue
+			}
+
+			// Keep the closest-rated candidates, then balance the teams by rating
+			sort.SliceStable(candidates, func(a, b int) bool {
+				return absInt(entry.Rating-candidates[a].Rating) < absInt(entry.Rating-candidates[b].Rating)
+			})
+			group := append([]*MatchQueueEntry{entry}, candidates[:2*shared.TeamMatchSize-1]...)
+			sort.SliceStable(group, func(a, b int) bool {
+				return group[a].Rating > group[b].Rating
+			})
+			return [2]*MatchQueueEntry{group[0], group[3]}, [2]*MatchQueueEntry{group[1], group[2]}, mode
+		}
+	}
+	return [2]*MatchQueueEntry{}, [2]*MatchQueueEntry{}, ""
+}
+
+// allowedGap returns the rating gap two queued players may be matched across:
+// the player who has waited longer decides how wide the gap may be
+func (q *MatchQueue) allowedGap(now time.Time, entryA, entryB *MatchQueueEntry) int {
+	allowedGap := q.Settings.AllowedRatingGap(now.Sub(entryA.JoinedAt))
+	if gapB := q.Settings.AllowedRatingGap(now.Sub(entryB.JoinedAt)); gapB > allowedGap {
+		allowedGap = gapB
+	}
+	return allowedGap
+}
+
+// RecordWait stores how long a matched player waited, keeping only the most recent samples
+func (q *MatchQueue) RecordWait(waited time.Duration) {
+	q.recentWaits = append(q.recentWaits, waited)
+	if len(q.recentWaits) > q.Settings.WaitSampleSize {
+		q.recentWaits = q.recentWaits[1:]
+	}
+}
+
+// EstimateWait returns the estimated remaining wait for a player who has already waited for the given duration
+func (q *MatchQueue) EstimateWait(waited time.Duration) time.Duration {
+	average := config.Seconds(q.Settings.DefaultWaitSeconds)
+	if len(q.recentWaits) > 0 {
+		var total time.Duration
+		for _, wait := range q.recentWaits {
+			total += wait
+		}
+		average = total / time.Duration(len(q.recentWaits))
+	}
+
+	if waited >= average {
+		return 0
+	}
+	return average - waited
+}
+
+// commonMode returns the first mode in a's preference order that b also accepts, or "" if none
+func commonMode(modesA, modesB []string) string {
+	for _, modeA := range modesA {
+		for _, modeB := range modesB {
+			if modeA == modeB {
+				return modeA
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeModes validates the requested queue modes.
+// An empty list or ANY expands to every supported mode.
+func normalizeModes(requested []string) ([]string, error) {
+	modes := make([]string, 0, len(shared.SupportedGameModes))
+	for _, mode := range requested {
+		mode = strings.ToUpper(strings.TrimSpace(mode))
+		if mode == shared.GameModeAny {
+			return shared.SupportedGameModes, nil
+		}
+		if !isSupportedMode(mode) {
+			return nil, fmt.Errorf("unknown game mode: %s", mode)
+		}
+		if commonMode(modes, []string{mode}) == "" { // Skip duplicates
+			modes = append(modes, mode)
+		}
+	}
+
+	if len(modes) == 0 {
+		return shared.SupportedGameModes, nil
+	}
+	return modes, nil
+}
+
+// isSupportedMode checks if a game mode can be queued for
+func isSupportedMode(mode string) bool {
+	for _, supported := range shared.SupportedGameModes {
+		if supported == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// absInt returns the absolute value of an integer
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// handleJoinQueue handles a join queue request
+func (s *GameServer) handleJoinQueue(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before joining the queue")
+		return
+	}
+
+	// Extract mode preferences and team size (optional)
+	requestedModes := make([]string, 0)
+	teamSize := 1
+	if joinPayload, ok := payload.(map[string]interface{}); ok {
+		if modesList, ok := joinPayload["modes"].([]interface{}); ok {
+			for _, m := range modesList {
+				if mode, ok := m.(string); ok {
+					requestedModes = append(requestedModes, mode)
+				}
+			}
+		}
+		if size, ok := joinPayload["teamSize"].(float64); ok && size > 0 {
+			teamSize = int(size)
+		}
+	}
+
+	modes, err := normalizeModes(requestedModes)
+	if err != nil {
+		sendError(client, err.Error())
+		return
+	}
+	if teamSize != 1 && teamSize != shared.TeamMatchSize {
+		sendError(client, fmt.Sprintf("Team size must be 1 (1v1) or %d (%dv%d)", shared.TeamMatchSize, shared.TeamMatchSize, shared.TeamMatchSize))
+		return
+	}
+
+	s.enqueuePlayer(client, modes, teamSize)
+}
+
+// handleLeaveQueue handles a leave queue request
+func (s *GameServer) handleLeaveQueue(client *Client, payload interface{}) {
+	s.mutex.Lock()
+	removed := s.MatchQueue.Remove(client.Username)
+	s.mutex.Unlock()
+
+	if !removed {
+		sendError(client, "You are not in the matchmaking queue")
+		return
+	}
+
+	log.Printf("Player %s left the matchmaking queue", client.Username)
+	sendQueueLeft(client, "You left the matchmaking queue.")
+	s.notifyPresence(client)
+
+	// Positions of the remaining players have changed
+	s.broadcastQueueStatus()
+}
+
+// sendQueueLeft tells a client they are no longer in the matchmaking queue
+func sendQueueLeft(client *Client, message string) {
+	statusMsg := models.GenericMessage{
+		Type: models.MsgTypeQueueStatus,
+		Payload: models.QueueStatusPayload{
+			InQueue: false,
+			Message: message,
+		},
+	}
+	client.Send(statusMsg)
+}
+
+// enqueuePlayer adds a logged-in player to the matchmaking queue and tries to find a match
+// with the given team size. Players in a game or a room are turned away.
+func (s *GameServer) enqueuePlayer(client *Client, modes []string, teamSize int) {
+	// Load the player's current rating
+	profile, err := s.JSONHandler.LoadPlayerData(client.Username)
+	if err != nil {
+		log.Printf("Error loading player data for %s: %v. Using default rating.", client.Username, err)
+		profile.Rating = shared.DefaultRating
+	}
+
+	// Checked under the same lock as the enqueue, so a game or room cannot start in between
+	s.mutex.Lock()
+	if client.InGame {
+		s.mutex.Unlock()
+		sendError(client, "You are already in a game")
+		return
+	}
+	if client.RoomCode != "" {
+		s.mutex.Unlock()
+		sendError(client, "Leave your room before joining the queue")
+		return
+	}
+	if s.MatchQueue.Contains(client.Username) {
+		// Re-joining updates the mode preferences and team size but keeps the original place in the queue
+		entry := s.MatchQueue.Entries[s.MatchQueue.indexOf(client.Username)]
+		entry.Modes = modes
+		entry.TeamSize = teamSize
+		log.Printf("Player %s updated queue modes to %v (%dv%d)", client.Username, modes, teamSize, teamSize)
+	} else {
+		s.MatchQueue.Add(client, profile.Rating, modes, teamSize)
+		log.Printf("Player %s (rating %d) joined the matchmaking queue for %v (%dv%d)", client.Username, profile.Rating, modes, teamSize, teamSize)
+	}
+	s.mutex.Unlock()
+	s.notifyPresence(client)
+
+	s.processMatchQueue()
+	s.broadcastQueueStatus()
+}
+
+// removeFromQueue takes a disconnected client out of the matchmaking queue.
+// The caller must hold s.mutex.
+func (s *GameServer) removeFromQueue(client *Client) {
+	if s.MatchQueue.Remove(client.Username) {
+		log.Printf("Removed disconnected player %s from the matchmaking queue", client.Username)
+	}
+}
+
+// processMatchQueue pairs queued players, then groups queued 2v2 players into teams,
+// until no acceptable match remains
+func (s *GameServer) processMatchQueue() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		now := time.Now()
+		entryA, entryB, mode := s.MatchQueue.FindMatch(now)
+		if entryA == nil || entryB == nil {
+			break
+		}
+
+		s.MatchQueue.Remove(entryA.Client.Username)
+		s.MatchQueue.Remove(entryB.Client.Username)
+		s.MatchQueue.RecordWait(now.Sub(entryA.JoinedAt))
+		s.MatchQueue.RecordWait(now.Sub(entryB.JoinedAt))
+
+		log.Printf("Matched players %s (%d) and %s (%d) for %s",
+			entryA.Client.Username, entryA.Rating, entryB.Client.Username, entryB.Rating, mode)
+
+		// Create a new game session
+		s.createGameSession(entryA.Client, entryB.Client, game.DefaultMatchSettings(s.Config, mode))
+	}
+
+	for {
+		now := time.Now()
+		teamA, teamB, mode := s.MatchQueue.FindTeamMatch(now)
+		if mode == "" {
+			return
+		}
+
+		for _, entry := range append(teamA[:], teamB[:]...) {
+			s.MatchQueue.Remove(entry.Client.Username)
+			s.MatchQueue.RecordWait(now.Sub(entry.JoinedAt))
+		}
+
+		log.Printf("Matched teams %s (%d) + %s (%d) and %s (%d) + %s (%d) for %s",
+			teamA[0].Client.Username, teamA[0].Rating, teamA[1].Client.Username, teamA[1].Rating,
+			teamB[0].Client.Username, teamB[0].Rating, teamB[1].Client.Username, teamB[1].Rating, mode)
+
+		s.createTeamGameSession([2]*Client{teamA[0].Client, teamA[1].Client}, [2]*Client{teamB[0].Client, teamB[1].Client},
+			game.DefaultMatchSettings(s.Config, mode))
+	}
+}
+
+// broadcastQueueStatus sends every queued player their current position and estimated wait
+func (s *GameServer) broadcastQueueStatus() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	queueSize := len(s.MatchQueue.Entries)
+	for i, entry := range s.MatchQueue.Entries {
+		estimatedWait := s.MatchQueue.EstimateWait(now.Sub(entry.JoinedAt))
+		message, teamSize := "Waiting for another player to join...", 0
+		if entry.TeamSize == shared.TeamMatchSize {
+			message, teamSize = "Waiting for more players to form two teams...", entry.TeamSize
+		}
+
+		statusMsg := models.GenericMessage{
+			Type: models.MsgTypeQueueStatus,
+			Payload: models.QueueStatusPayload{
+				InQueue:              true,
+				Position:             i + 1,
+				QueueSize:            queueSize,
+				Modes:                entry.Modes,
+				EstimatedWaitSeconds: int(estimatedWait.Seconds()),
+				Message:              message,
+				TeamSize:             teamSize,
+			},
+		}
+		entry.Client.Send(statusMsg)
+	}
+}
+
+// runMatchmaker periodically re-evaluates the queue so that waiting players'
+// accepted rating gap widens over time, and keeps waiting players informed.
+// It returns once the server stops accepting players.
+func (s *GameServer) runMatchmaker() {
+	settings := s.Config.Matchmaking
+	ticker := time.NewTicker(config.Seconds(settings.TickSeconds))
+	defer ticker.Stop()
+
+	ticks := 0
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+		s.processMatchQueue()
+
+		ticks++
+		if ticks*settings.TickSeconds >= settings.StatusEverySeconds {
+			ticks = 0
+			s.broadcastQueueStatus()
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package network
+
+import (
+	"slices"
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"tcr/internal/storage"
+	"testing"
+	"time"
+)
+
+// queuedPlayer describes a player in a test queue
+type queuedPlayer struct {
+	username string
+	rating   int
+	modes    []string
+	teamSize int
+	waited   time.Duration
+}
+
+// testMatchmaking are the queue settings used by the tests
+var testMatchmaking = config.MatchmakingConfig{
+	BaseRatingGap:      100,
+	GapWidenPerSecond:  10,
+	MaxRatingGap:       1000,
+	DefaultWaitSeconds: 30,
+	WaitSampleSize:     10,
+}
+
+// newTestQueue builds a queue of the given players as of now
+func newTestQueue(now time.Time, players []queuedPlayer) *MatchQueue {
+	queue := NewMatchQueue(testMatchmaking)
+	for _, player := range players {
+		teamSize := player.teamSize
+		if teamSize == 0 {
+			teamSize = 1
+		}
+		queue.Entries = append(queue.Entries, &MatchQueueEntry{
+			Client:   &Client{Username: player.username},
+			Rating:   player.rating,
+			Modes:    player.modes,
+			TeamSize: teamSize,
+			JoinedAt: now.Add(-player.waited),
+		})
+	}
+	return queue
+}
+
+func TestFindMatch(t *testing.T) {
+	simple := []string{shared.GameModeSimple}
+	enhanced := []string{shared.GameModeEnhanced}
+	both := []string{shared.GameModeEnhanced, shared.GameModeSimple}
+
+	tests := []struct {
+		name     string
+		players  []queuedPlayer
+		wantA    string
+		wantB    string
+		wantMode string
+	}{
+		{
+			name:    "empty queue",
+			players: nil,
+		},
+		{
+			name:    "single player",
+			players: []queuedPlayer{{"a", 1200, simple, 1, time.Minute}},
+		},
+		{
+			name:     "two close players",
+			players:  []queuedPlayer{{"a", 1200, simple, 1, 0}, {"b", 1250, simple, 1, 0}},
+			wantA:    "a",
+			wantB:    "b",
+			wantMode: shared.GameModeSimple,
+		},
+		{
+			name:    "no common mode",
+			players: []queuedPlayer{{"a", 1200, simple, 1, 0}, {"b", 1200, enhanced, 1, 0}},
+		},
+		{
+			name:     "first preferred common mode",
+			players:  []queuedPlayer{{"a", 1200, both, 1, 0}, {"b", 1200, []string{shared.GameModeSimple, shared.GameModeEnhanced}, 1, 0}},
+			wantA:    "a",
+			wantB:    "b",
+			wantMode: shared.GameModeEnhanced,
+		},
+		{
+			name:    "gap too wide for a new player",
+			players: []queuedPlayer{{"a", 1200, simple, 1, 0}, {"b", 1400, simple, 1, 0}},
+		},
+		{
+			name:     "gap widens with the longer wait",
+			players:  []queuedPlayer{{"a", 1200, simple, 1, 15 * time.Second}, {"b", 1400, simple, 1, 0}},
+			wantA:    "a",
+			wantB:    "b",
+			wantMode: shared.GameModeSimple,
+		},
+		{
+			name:     "closest opponent is chosen",
+			players:  []queuedPlayer{{"a", 1200, simple, 1, 0}, {"b", 1290, simple, 1, 0}, {"c", 1210, simple, 1, 0}},
+			wantA:    "a",
+			wantB:    "c",
+			wantMode: shared.GameModeSimple,
+		},
+		{
+			name:    "team players are left out",
+			players: []queuedPlayer{{"a", 1200, simple, shared.TeamMatchSize, 0}, {"b", 1200, simple, 1, 0}},
+		},
+		{
+			name:     "oldest player is matched first",
+			players:  []queuedPlayer{{"a", 1500, simple, 1, 0}, {"b", 1200, simple, 1, 0}, {"c", 1250, simple, 1, 0}},
+			wantA:    "b",
+			wantB:    "c",
+			wantMode: shared.GameModeSimple,
+		},
+	}
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, mode := newTestQueue(now, tt.players).FindMatch(now)
+			if tt.wantA == "" {
+				if a != nil || b != nil || mode != "" {
+					t.Fatalf("FindMatch() = %v, %v, %q; want no match", a, b, mode)
+				}
+				return
+			}
+			if a == nil || b == nil {
+				t.Fatalf("FindMatch() found no match; want %s vs %s", tt.wantA, tt.wantB)
+			}
+			if a.Client.Username != tt.wantA || b.Client.Username != tt.wantB || mode != tt.wantMode {
+				t.Errorf("FindMatch() = %s vs %s in %q; want %s vs %s in %q",
+					a.Client.Username, b.Client.Username, mode, tt.wantA, tt.wantB, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestFindTeamMatch(t *testing.T) {
+	simple := []string{shared.GameModeSimple}
+	enhanced := []string{shared.GameModeEnhanced}
+	team := shared.TeamMatchSize
+
+	tests := []struct {
+		name     string
+		players  []queuedPlayer
+		wantTeam [2][2]string
+		wantMode string
+	}{
+		{
+			name:    "too few team players",
+			players: []queuedPlayer{{"a", 1200, simple, team, 0}, {"b", 1200, simple, team, 0}, {"c", 1200, simple, team, 0}},
+		},
+		{
+			name: "1v1 players are left out",
+			players: []queuedPlayer{
+				{"a", 1200, simple, team, 0}, {"b", 1200, simple, team, 0},
+				{"c", 1200, simple, team, 0}, {"d", 1200, simple, 1, 0},
+			},
+		},
+		{
+			name: "highest and lowest against the middle two",
+			players: []queuedPlayer{
+				{"a", 1200, simple, team, 0}, {"b", 1260, simple, team, 0},
+				{"c", 1230, simple, team, 0}, {"d", 1210, simple, team, 0},
+			},
+			wantTeam: [2][2]string{{"b", "a"}, {"c", "d"}},
+			wantMode: shared.GameModeSimple,
+		},
+		{
+			name: "no shared mode",
+			players: []queuedPlayer{
+				{"a", 1200, simple, team, 0}, {"b", 1200, simple, team, 0},
+				{"c", 1200, simple, team, 0}, {"d", 1200, enhanced, team, 0},
+			},
+		},
+		{
+			name: "gap too wide",
+			players: []queuedPlayer{
+				{"a", 1200, simple, team, 0}, {"b", 1200, simple, team, 0},
+				{"c", 1200, simple, team, 0}, {"d", 1500, simple, team, 0},
+			},
+		},
+		{
+			name: "closest-rated four of five",
+			players: []queuedPlayer{
+				{"a", 1200, simple, team, 0}, {"b", 1290, simple, team, 0},
+				{"c", 1220, simple, team, 0}, {"d", 1240, simple, team, 0},
+				{"e", 1250, simple, team, 0},
+			},
+			wantTeam: [2][2]string{{"e", "a"}, {"d", "c"}},
+			wantMode: shared.GameModeSimple,
+		},
+	}
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamA, teamB, mode := newTestQueue(now, tt.players).FindTeamMatch(now)
+			if tt.wantMode == "" {
+				if mode != "" || teamA[0] != nil || teamB[0] != nil {
+					t.Fatalf("FindTeamMatch() matched in %q; want no match", mode)
+				}
+				return
+			}
+			if mode != tt.wantMode {
+				t.Fatalf("FindTeamMatch() mode = %q, want %q", mode, tt.wantMode)
+			}
+			got := [2][2]string{
+				{teamA[0].Client.Username, teamA[1].Client.Username},
+				{teamB[0].Client.Username, teamB[1].Client.Username},
+			}
+			if got != tt.wantTeam {
+				t.Errorf("FindTeamMatch() teams = %v, want %v", got, tt.wantTeam)
+			}
+		})
+	}
+}
+
+func TestNormalizeModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []string
+		want      []string
+		wantErr   bool
+	}{
+		{"nothing requested", nil, shared.SupportedGameModes, false},
+		{"any mode", []string{"any"}, shared.SupportedGameModes, false},
+		{"case and spaces", []string{" enhanced "}, []string{shared.GameModeEnhanced}, false},
+		{"duplicates are dropped", []string{"SIMPLE", "ENHANCED", "simple"}, []string{shared.GameModeSimple, shared.GameModeEnhanced}, false},
+		{"unknown mode", []string{"SIMPLE", "TURBO"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeModes(tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeModes(%v) error = %v, want error %v", tt.requested, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeModes(%v) = %v, want %v", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	defaultWait := config.Seconds(testMatchmaking.DefaultWaitSeconds)
+	tests := []struct {
+		name   string
+		waits  []time.Duration
+		waited time.Duration
+		want   time.Duration
+	}{
+		{"default before any match", nil, 0, defaultWait},
+		{"default minus the time waited", nil, 10 * time.Second, defaultWait - 10*time.Second},
+		{"average of recent waits", []time.Duration{10 * time.Second, 20 * time.Second}, 5 * time.Second, 10 * time.Second},
+		{"never negative", []time.Duration{10 * time.Second}, time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := NewMatchQueue(testMatchmaking)
+			for _, wait := range tt.waits {
+				queue.RecordWait(wait)
+			}
+			if got := queue.EstimateWait(tt.waited); got != tt.want {
+				t.Errorf("EstimateWait(%v) = %v, want %v", tt.waited, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordWaitKeepsRecentSamples(t *testing.T) {
+	queue := NewMatchQueue(testMatchmaking)
+	for i := 0; i < testMatchmaking.WaitSampleSize; i++ {
+		queue.RecordWait(time.Hour)
+	}
+	for i := 0; i < testMatchmaking.WaitSampleSize; i++ {
+		queue.RecordWait(time.Second)
+	}
+	if got := queue.EstimateWait(0); got != time.Second {
+		t.Errorf("EstimateWait(0) = %v after only recent 1s waits, want 1s", got)
+	}
+}
+
+func TestEnqueuePlayer(t *testing.T) {
+	tests := []struct {
+		name       string
+		inGame     bool
+		roomCode   string
+		wantQueued bool
+	}{
+		{name: "idle player", wantQueued: true},
+		{name: "player in a game", inGame: true},
+		{name: "player in a room", roomCode: "ABCD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(config.Default(), storage.NewJSONHandler("", t.TempDir()))
+			client := &Client{Username: "alice", InGame: tt.inGame, RoomCode: tt.roomCode, outbound: make(chan models.GenericMessage, 8)}
+			server.Clients[client.Username] = client
+
+			server.enqueuePlayer(client, []string{shared.GameModeSimple}, 1)
+			if queued := server.MatchQueue.Contains("alice"); queued != tt.wantQueued {
+				t.Fatalf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+			if !tt.wantQueued {
+				if message := <-client.outbound; message.Type != models.MsgTypeErrorNotification {
+					t.Errorf("sent %s, want %s", message.Type, models.MsgTypeErrorNotification)
+				}
+			}
+		})
+	}
+}
+
+func TestRunMatchmakerStops(t *testing.T) {
+	server := NewServer(config.Default(), storage.NewJSONHandler("", t.TempDir()))
+	stopped := make(chan struct{})
+	go func() {
+		server.runMatchmaker()
+		close(stopped)
+	}()
+
+	close(server.quit)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("runMatchmaker() kept running after the server stopped accepting players")
+	}
+}
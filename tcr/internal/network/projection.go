@@ -0,0 +1,101 @@
+package network
+
+import (
+	"tcr/internal/game"
+	"tcr/internal/models"
+	"time"
+)
+
+// ViewerRole describes how much of a game a recipient of game state may see
+type ViewerRole int
+
+const (
+	ViewerPlayer    ViewerRole = iota // Sees their own (and their teammate's) state in full and the opponents' redacted
+	ViewerSpectator                   // Sees both players redacted
+)
+
+// Viewer identifies the recipient of a projected game state
+type Viewer struct {
+	Role     ViewerRole
+	Username string // Player receiving the state, for ViewerPlayer
+	Teammate string // Teammate of the player in a 2v2 match, for ViewerPlayer
+}
+
+// PlayerViewer returns the viewer for one of the players of a match
+func PlayerViewer(username string) Viewer {
+	return Viewer{Role: ViewerPlayer, Username: username}
+}
+
+// canSeeHand checks if the viewer may see the hand, mana and pending rage of the given player
+func (v Viewer) canSeeHand(username string) bool {
+	switch v.Role {
+	case ViewerPlayer:
+		return v.Username == username || (v.Teammate != "" && v.Teammate == username)
+	default:
+		return false
+	}
+}
+
+// ProjectPlayerState returns a player's state as the viewer is allowed to see it.
+// Hidden hands are reduced to their size and a pending rage is hidden with them; mana is only
+// shown if the match settings reveal it.
+func ProjectPlayerState(state models.PlayerState, viewer Viewer, settings models.MatchSettings) models.PlayerState {
+	state.HandSize = len(state.Troops)
+	if viewer.canSeeHand(state.Username) {
+		return state
+	}
+
+	state.Troops = []models.TroopState{}
+	state.HandHidden = true
+	state.RagePercent = 0
+	if !settings.RevealOpponentMana {
+		state.CurrentMana = 0
+		state.ManaHidden = true
+	}
+	return state
+}
+
+// ProjectGameState builds the game state update of a match as the viewer is allowed to see it
+func (s *GameServer) ProjectGameState(gameState *game.GameState, settings models.MatchSettings, viewer Viewer, lastActionLog string) models.GameStateUpdatePayload {
+	update := models.GameStateUpdatePayload{
+		PlayerA:       ProjectPlayerState(s.createPlayerState(gameState.PlayerA, gameState.Rules), viewer, settings),
+		PlayerB:       ProjectPlayerState(s.createPlayerState(gameState.PlayerB, gameState.Rules), viewer, settings),
+		CurrentTurn:   gameState.CurrentTurn,
+		LastActionLog: lastActionLog,
+		ManaRegen:     gameState.Rules.TurnRegen(gameState.InOvertime()),
+		Hits:          hitResults(gameState.LastHits),
+	}
+
+	if gameState.IsTeamMatch() {
+		partnerA := ProjectPlayerState(s.createPlayerState(gameState.PartnerA, gameState.Rules), viewer, settings)
+		partnerB := ProjectPlayerState(s.createPlayerState(gameState.PartnerB, gameState.Rules), viewer, settings)
+		update.PartnerA = &partnerA
+		update.PartnerB = &partnerB
+	}
+
+	if !gameState.PhaseEndsAt.IsZero() {
+		update.Phase = gameState.Phase
+		update.PhaseSecondsLeft = max(int(time.Until(gameState.PhaseEndsAt).Round(time.Second).Seconds()), 0)
+	}
+	return update
+}
+
+// hitResults converts the hits of a deploy for clients; nil stays nil so the field is omitted
+func hitResults(hits []game.Hit) []models.HitResult {
+	if len(hits) == 0 {
+		return nil
+	}
+	results := make([]models.HitResult, len(hits))
+	for i, hit := range hits {
+		results[i] = models.HitResult{
+			TowerID:     hit.TowerID,
+			Kind:        hit.Kind,
+			Building:    hit.Building,
+			Damage:      hit.Damage,
+			Critical:    hit.Critical,
+			Destroyed:   hit.Destroyed,
+			HPRemaining: hit.HPRemaining,
+		}
+	}
+	return results
+}
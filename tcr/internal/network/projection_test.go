@@ -0,0 +1,52 @@
+package network
+
+import (
+	"tcr/internal/models"
+	"testing"
+)
+
+func TestProjectPlayerState(t *testing.T) {
+	state := models.PlayerState{
+		Username:    "alice",
+		Troops:      []models.TroopState{{Name: "Pawn"}, {Name: "Knight"}},
+		CurrentMana: 7,
+		RagePercent: 50,
+	}
+	spectator := Viewer{Role: ViewerSpectator}
+
+	tests := []struct {
+		name           string
+		viewer         Viewer
+		revealMana     bool
+		wantHand       bool
+		wantMana       int
+		wantManaHidden bool
+	}{
+		{"own state", PlayerViewer("alice"), false, true, 7, false},
+		{"teammate's state", Viewer{Role: ViewerPlayer, Username: "carol", Teammate: "alice"}, false, true, 7, false},
+		{"opponent's state", PlayerViewer("bob"), false, false, 0, true},
+		{"opponent's state with mana revealed", PlayerViewer("bob"), true, false, 7, false},
+		{"spectator", spectator, false, false, 0, true},
+		{"spectator with mana revealed", spectator, true, false, 7, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectPlayerState(state, tt.viewer, models.MatchSettings{RevealOpponentMana: tt.revealMana})
+			if got.HandSize != len(state.Troops) {
+				t.Errorf("HandSize = %d, want %d", got.HandSize, len(state.Troops))
+			}
+			if shown := len(got.Troops) == len(state.Troops); shown != tt.wantHand || got.HandHidden == tt.wantHand {
+				t.Errorf("hand shown = %v (HandHidden %v), want %v", shown, got.HandHidden, tt.wantHand)
+			}
+			if got.CurrentMana != tt.wantMana || got.ManaHidden != tt.wantManaHidden {
+				t.Errorf("mana = %d (ManaHidden %v), want %d (%v)", got.CurrentMana, got.ManaHidden, tt.wantMana, tt.wantManaHidden)
+			}
+			if shown := got.RagePercent == state.RagePercent; shown != tt.wantHand {
+				t.Errorf("RagePercent = %d, want it shown %v", got.RagePercent, tt.wantHand)
+			}
+		})
+	}
+	if len(state.Troops) != 2 || state.CurrentMana != 7 || state.RagePercent != 50 {
+		t.Error("ProjectPlayerState modified the original state")
+	}
+}
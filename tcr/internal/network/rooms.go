@@ -0,0 +1,506 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"tcr/internal/config"
+	"tcr/internal/game"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
+
+// roomCodeAlphabet excludes characters that are easy to confuse (0/O, 1/I)
+const roomCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Room represents a private room where two players set up a match with custom settings
+type Room struct {
+	Code       string
+	Host       *Client
+	Guest      *Client
+	HostReady  bool
+	GuestReady bool
+	Settings   models.MatchSettings
+}
+
+// Challenge represents a pending direct challenge from one online player to another
+type Challenge struct {
+	Challenger *Client
+	Target     *Client
+	Settings   models.MatchSettings
+	ExpiresAt  time.Time
+}
+
+// challengeKey builds the key used to store a challenge in GameServer.Challenges
+func challengeKey(challengerUsername, targetUsername string) string {
+	return challengerUsername + ">" + targetUsername
+}
+
+// generateRoomCode creates a room code that is not in use yet.
+// The caller must hold s.mutex.
+func (s *GameServer) generateRoomCode() string {
+	for {
+		code := make([]byte, shared.RoomCodeLength)
+		for i := range code {
+			code[i] = roomCodeAlphabet[shared.GetRandomInt(0, len(roomCodeAlphabet)-1)]
+		}
+		if _, exists := s.Rooms[string(code)]; !exists {
+			return string(code)
+		}
+	}
+}
+
+// parseMatchSettings reads match settings from a payload map, falling back to the defaults of the mode for missing fields
+func (s *GameServer) parseMatchSettings(payloadMap map[string]interface{}) (models.MatchSettings, error) {
+	settingsMap, ok := payloadMap["settings"].(map[string]interface{})
+
+	mode := shared.GameModeSimple
+	if ok {
+		if requested, ok := settingsMap["mode"].(string); ok && requested != "" {
+			mode = strings.ToUpper(requested)
+		}
+	}
+	settings := game.DefaultMatchSettings(s.Config, mode)
+
+	if ok {
+		if ruleSet, ok := settingsMap["ruleSet"].(string); ok && ruleSet != "" {
+			settings.RuleSet = ruleSet
+			if rules, exists := s.Config.CustomRules(ruleSet); exists {
+				settings.StartingMana = rules.InitialMana
+			}
+		}
+		if turnTimer, ok := settingsMap["turnTimerSeconds"].(float64); ok { // JSON numbers are float64
+			settings.TurnTimerSeconds = int(turnTimer)
+		}
+		if startingMana, ok := settingsMap["startingMana"].(float64); ok {
+			settings.StartingMana = int(startingMana)
+		}
+		if revealMana, ok := settingsMap["revealOpponentMana"].(bool); ok {
+			settings.RevealOpponentMana = revealMana
+		}
+		if levelCap, ok := settingsMap["levelCap"].(float64); ok {
+			settings.LevelCap = int(levelCap)
+		}
+		if handicap, ok := settingsMap["handicap"].(string); ok {
+			settings.Handicap = strings.ToUpper(handicap)
+		}
+	}
+
+	if err := game.ValidateMatchSettings(s.Config, settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// handleCreateRoom handles a create room request
+func (s *GameServer) handleCreateRoom(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before creating a room")
+		return
+	}
+
+	createPayload, ok := payload.(map[string]interface{})
+	if !ok {
+		createPayload = make(map[string]interface{})
+	}
+
+	settings, err := s.parseMatchSettings(createPayload)
+	if err != nil {
+		sendError(client, fmt.Sprintf("Invalid room settings: %v", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if client.InGame {
+		sendError(client, "You are already in a game")
+		return
+	}
+	if client.RoomCode != "" {
+		sendError(client, fmt.Sprintf("You are already in room %s", client.RoomCode))
+		return
+	}
+
+	// A player in a room is no longer looking for a random match
+	if s.MatchQueue.Remove(client.Username) {
+		sendQueueLeft(client, "You left the matchmaking queue to host a room.")
+	}
+
+	room := &Room{
+		Code:     s.generateRoomCode(),
+		Host:     client,
+		Settings: settings,
+	}
+	s.Rooms[room.Code] = room
+	client.RoomCode = room.Code
+
+	log.Printf("Player %s created room %s (%+v)", client.Username, room.Code, settings)
+	s.notifyPresence(client)
+	s.sendRoomUpdate(room, fmt.Sprintf("Room created. Share the code %s with your opponent.", room.Code))
+}
+
+// handleJoinRoom handles a join room request
+func (s *GameServer) handleJoinRoom(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before joining a room")
+		return
+	}
+
+	joinPayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid join room payload")
+		return
+	}
+
+	roomCode, ok := joinPayload["roomCode"].(string)
+	if !ok || roomCode == "" {
+		sendError(client, "Invalid room code")
+		return
+	}
+	roomCode = strings.ToUpper(strings.TrimSpace(roomCode))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if client.InGame {
+		sendError(client, "You are already in a game")
+		return
+	}
+	if client.RoomCode != "" {
+		sendError(client, fmt.Sprintf("You are already in room %s", client.RoomCode))
+		return
+	}
+
+	room, exists := s.Rooms[roomCode]
+	if !exists {
+		sendError(client, fmt.Sprintf("Room %s does not exist", roomCode))
+		return
+	}
+	if room.Guest != nil {
+		sendError(client, fmt.Sprintf("Room %s is full", roomCode))
+		return
+	}
+
+	if s.MatchQueue.Remove(client.Username) {
+		sendQueueLeft(client, "You left the matchmaking queue to join a room.")
+	}
+
+	room.Guest = client
+	client.RoomCode = room.Code
+
+	log.Printf("Player %s joined room %s", client.Username, room.Code)
+	s.notifyPresence(client)
+	s.sendRoomUpdate(room, fmt.Sprintf("%s joined the room. Type 'ready' when you are ready to play.", client.Username))
+}
+
+// handleLeaveRoom handles a leave room request
+func (s *GameServer) handleLeaveRoom(client *Client, payload interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if client.RoomCode == "" {
+		sendError(client, "You are not in a room")
+		return
+	}
+
+	s.leaveRoom(client, fmt.Sprintf("%s left the room.", client.Username))
+}
+
+// leaveRoom removes a client from their room. If the host leaves, the room is closed.
+// The caller must hold s.mutex.
+func (s *GameServer) leaveRoom(client *Client, reason string) {
+	room, exists := s.Rooms[client.RoomCode]
+	client.RoomCode = ""
+	s.notifyPresence(client)
+	if !exists {
+		return
+	}
+
+	// Tell the leaving player the room is gone for them
+	client.Send(models.GenericMessage{
+		Type:    models.MsgTypeRoomUpdate,
+		Payload: models.RoomUpdatePayload{Message: "You left the room."},
+	})
+
+	if room.Host == client {
+		// Host left: close the room
+		delete(s.Rooms, room.Code)
+		log.Printf("Room %s closed: %s", room.Code, reason)
+		if room.Guest != nil {
+			room.Guest.RoomCode = ""
+			s.notifyPresence(room.Guest)
+			room.Guest.Send(models.GenericMessage{
+				Type:    models.MsgTypeRoomUpdate,
+				Payload: models.RoomUpdatePayload{Message: reason + " The room was closed."},
+			})
+		}
+		return
+	}
+
+	// Guest left: the room stays open for someone else, and the host must ready up again
+	room.Guest = nil
+	room.GuestReady = false
+	room.HostReady = false
+	log.Printf("Room %s: %s", room.Code, reason)
+	s.sendRoomUpdate(room, reason)
+}
+
+// handleRoomReady handles a room ready request
+func (s *GameServer) handleRoomReady(client *Client, payload interface{}) {
+	ready := true
+	if readyPayload, ok := payload.(map[string]interface{}); ok {
+		if r, ok := readyPayload["ready"].(bool); ok {
+			ready = r
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	room, exists := s.Rooms[client.RoomCode]
+	if !exists {
+		sendError(client, "You are not in a room")
+		return
+	}
+
+	if room.Host == client {
+		room.HostReady = ready
+	} else {
+		room.GuestReady = ready
+	}
+
+	// Both players ready: start the match
+	if room.Guest != nil && room.HostReady && room.GuestReady {
+		delete(s.Rooms, room.Code)
+		room.Host.RoomCode = ""
+		room.Guest.RoomCode = ""
+
+		log.Printf("Room %s: both players ready, starting match", room.Code)
+		s.createGameSession(room.Host, room.Guest, room.Settings)
+		return
+	}
+
+	if ready {
+		s.sendRoomUpdate(room, fmt.Sprintf("%s is ready.", client.Username))
+	} else {
+		s.sendRoomUpdate(room, fmt.Sprintf("%s is no longer ready.", client.Username))
+	}
+}
+
+// sendRoomUpdate sends the current room state to all room members.
+// The caller must hold s.mutex.
+func (s *GameServer) sendRoomUpdate(room *Room, message string) {
+	update := models.RoomUpdatePayload{
+		RoomCode:   room.Code,
+		Host:       room.Host.Username,
+		HostReady:  room.HostReady,
+		GuestReady: room.GuestReady,
+		Settings:   room.Settings,
+		Message:    message,
+	}
+	if room.Guest != nil {
+		update.Guest = room.Guest.Username
+	}
+
+	updateMsg := models.GenericMessage{
+		Type:    models.MsgTypeRoomUpdate,
+		Payload: update,
+	}
+
+	room.Host.Send(updateMsg)
+	if room.Guest != nil {
+		room.Guest.Send(updateMsg)
+	}
+}
+
+// handleChallengeRequest handles a direct challenge from one player to another
+func (s *GameServer) handleChallengeRequest(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before challenging players")
+		return
+	}
+
+	challengePayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid challenge payload")
+		return
+	}
+
+	targetUsername, ok := challengePayload["targetUsername"].(string)
+	if !ok || targetUsername == "" {
+		sendError(client, "Invalid target username")
+		return
+	}
+	if targetUsername == client.Username {
+		sendError(client, "You cannot challenge yourself")
+		return
+	}
+
+	settings, err := s.parseMatchSettings(challengePayload)
+	if err != nil {
+		sendError(client, fmt.Sprintf("Invalid challenge settings: %v", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if client.InGame {
+		sendError(client, "You are already in a game")
+		return
+	}
+
+	target, online := s.Clients[targetUsername]
+	if !online {
+		sendError(client, fmt.Sprintf("%s is not online", targetUsername))
+		return
+	}
+	if target.InGame {
+		sendError(client, fmt.Sprintf("%s is currently in a game", targetUsername))
+		return
+	}
+
+	key := challengeKey(client.Username, targetUsername)
+	if _, pending := s.Challenges[key]; pending {
+		sendError(client, fmt.Sprintf("You already have a pending challenge to %s", targetUsername))
+		return
+	}
+
+	challenge := &Challenge{
+		Challenger: client,
+		Target:     target,
+		Settings:   settings,
+		ExpiresAt:  time.Now().Add(config.Seconds(s.Config.Timeouts.ChallengeSeconds)),
+	}
+	s.Challenges[key] = challenge
+
+	// Expire the challenge if it is not answered in time
+	time.AfterFunc(config.Seconds(s.Config.Timeouts.ChallengeSeconds), func() {
+		s.expireChallenge(key, challenge)
+	})
+
+	log.Printf("Player %s challenged %s (%+v)", client.Username, targetUsername, settings)
+
+	target.Send(models.GenericMessage{
+		Type: models.MsgTypeChallengeNotify,
+		Payload: models.ChallengeNotificationPayload{
+			ChallengerUsername: client.Username,
+			Settings:           settings,
+			ExpiresInSeconds:   s.Config.Timeouts.ChallengeSeconds,
+		},
+	})
+
+	client.Send(models.GenericMessage{
+		Type: models.MsgTypeChallengeResult,
+		Payload: models.ChallengeResultPayload{
+			TargetUsername: targetUsername,
+			Accepted:       false,
+			Message:        fmt.Sprintf("Challenge sent to %s. Waiting for a response...", targetUsername),
+		},
+	})
+}
+
+// handleChallengeResponse handles the challenged player accepting or declining a challenge
+func (s *GameServer) handleChallengeResponse(client *Client, payload interface{}) {
+	responsePayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid challenge response payload")
+		return
+	}
+
+	challengerUsername, ok := responsePayload["challengerUsername"].(string)
+	if !ok || challengerUsername == "" {
+		sendError(client, "Invalid challenger username")
+		return
+	}
+	accept, _ := responsePayload["accept"].(bool)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := challengeKey(challengerUsername, client.Username)
+	challenge, exists := s.Challenges[key]
+	if !exists || time.Now().After(challenge.ExpiresAt) {
+		delete(s.Challenges, key)
+		sendError(client, fmt.Sprintf("No pending challenge from %s", challengerUsername))
+		return
+	}
+	delete(s.Challenges, key)
+
+	challenger := challenge.Challenger
+
+	if !accept {
+		log.Printf("Player %s declined the challenge from %s", client.Username, challengerUsername)
+		challenger.Send(models.GenericMessage{
+			Type: models.MsgTypeChallengeResult,
+			Payload: models.ChallengeResultPayload{
+				TargetUsername: client.Username,
+				Accepted:       false,
+				Message:        fmt.Sprintf("%s declined your challenge.", client.Username),
+			},
+		})
+		return
+	}
+
+	if client.InGame || challenger.InGame {
+		sendError(client, "Cannot start the challenge: a player is already in a game")
+		return
+	}
+
+	// Accepting a challenge takes both players out of the queue and any room
+	for _, player := range []*Client{challenger, client} {
+		s.MatchQueue.Remove(player.Username)
+		if player.RoomCode != "" {
+			s.leaveRoom(player, fmt.Sprintf("%s accepted a challenge.", player.Username))
+		}
+	}
+
+	log.Printf("Player %s accepted the challenge from %s", client.Username, challengerUsername)
+	challenger.Send(models.GenericMessage{
+		Type: models.MsgTypeChallengeResult,
+		Payload: models.ChallengeResultPayload{
+			TargetUsername: client.Username,
+			Accepted:       true,
+			Message:        fmt.Sprintf("%s accepted your challenge!", client.Username),
+		},
+	})
+
+	s.createGameSession(challenger, client, challenge.Settings)
+}
+
+// expireChallenge removes a challenge that was not answered in time and informs the challenger
+func (s *GameServer) expireChallenge(key string, challenge *Challenge) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The challenge may already have been answered (or replaced by a new one)
+	if s.Challenges[key] != challenge {
+		return
+	}
+	delete(s.Challenges, key)
+
+	challenge.Challenger.Send(models.GenericMessage{
+		Type: models.MsgTypeChallengeResult,
+		Payload: models.ChallengeResultPayload{
+			TargetUsername: challenge.Target.Username,
+			Accepted:       false,
+			Message:        fmt.Sprintf("Your challenge to %s expired.", challenge.Target.Username),
+		},
+	})
+}
+
+// removeRoomsAndChallenges cleans up the rooms and challenges of a disconnected client.
+// The caller must hold s.mutex.
+func (s *GameServer) removeRoomsAndChallenges(client *Client) {
+	if client.RoomCode != "" {
+		s.leaveRoom(client, fmt.Sprintf("%s disconnected.", client.Username))
+	}
+
+	for key, challenge := range s.Challenges {
+		if challenge.Challenger == client || challenge.Target == client {
+			delete(s.Challenges, key)
+		}
+	}
+}
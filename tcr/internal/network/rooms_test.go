@@ -0,0 +1,44 @@
+package network
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+func TestParseMatchSettings(t *testing.T) {
+	server := NewServer(config.Default(), nil)
+	defaults := models.MatchSettings{Mode: shared.GameModeSimple, StartingMana: shared.InitialMana}
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		want    models.MatchSettings
+		wantErr bool
+	}{
+		{"no settings", map[string]interface{}{}, defaults, false},
+		{"mode is case-insensitive", map[string]interface{}{"settings": map[string]interface{}{"mode": "enhanced"}},
+			models.MatchSettings{Mode: shared.GameModeEnhanced, StartingMana: shared.InitialMana}, false},
+		{"turn timer and starting mana", map[string]interface{}{"settings": map[string]interface{}{"turnTimerSeconds": float64(30), "startingMana": float64(7)}},
+			models.MatchSettings{Mode: shared.GameModeSimple, TurnTimerSeconds: 30, StartingMana: 7}, false},
+		{"no starting mana", map[string]interface{}{"settings": map[string]interface{}{"startingMana": float64(0)}},
+			models.MatchSettings{Mode: shared.GameModeSimple, StartingMana: 0}, false},
+		{"negative starting mana", map[string]interface{}{"settings": map[string]interface{}{"startingMana": float64(-1)}}, models.MatchSettings{}, true},
+		{"level cap and handicap", map[string]interface{}{"settings": map[string]interface{}{"levelCap": float64(3), "handicap": "tower_hp"}},
+			models.MatchSettings{Mode: shared.GameModeSimple, StartingMana: shared.InitialMana, LevelCap: 3, Handicap: shared.HandicapTowerHP}, false},
+		{"unknown handicap", map[string]interface{}{"settings": map[string]interface{}{"handicap": "extra_card"}}, models.MatchSettings{}, true},
+		{"unknown mode", map[string]interface{}{"settings": map[string]interface{}{"mode": "turbo"}}, models.MatchSettings{}, true},
+		{"turn timer out of range", map[string]interface{}{"settings": map[string]interface{}{"turnTimerSeconds": float64(1)}}, models.MatchSettings{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.parseMatchSettings(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMatchSettings() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseMatchSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,65 +5,108 @@ import (
 	"log"
 	"net"
 	"sync"
+	"tcr/internal/config"
 	"tcr/internal/game"
 	"tcr/internal/models"
 	"tcr/internal/shared"
 	"tcr/internal/storage"
+	"time"
 )
 
 // Client represents a connected client
 type Client struct {
-	Username string
-	Conn     net.Conn
-	PlayerID string
-	InGame   bool
+	Username   string
+	Conn       net.Conn
+	PlayerID   string
+	InGame     bool
+	RoomCode   string          // Code of the private room the client is in, if any
+	Spectating string          // ID of the game session the client is watching, if any
+	Level      int             // Player level shown in the lobby
+	Rating     int             // Skill rating shown in the lobby
+	Muted      map[string]bool // Players whose chat this client does not want to see
+
+	chatTimes []time.Time // When the client's recent chat messages were sent, for rate limiting
+
+	writeTimeout time.Duration              // Longest a single write to the client may take
+	outbound     chan models.GenericMessage // Messages waiting to be written by writeLoop
+	closing      chan struct{}              // Closed to have writeLoop flush outbound and hang up
+	done         chan struct{}              // Closed when the connection is shut down
+	closingOnce  sync.Once
+	closeOnce    sync.Once
 }
 
-// GameSession represents a game session between two clients
+// GameSession represents a game session between two clients, or two teams of two in 2v2.
+// GameEngine, Spectators, turnTimer and phaseTimer belong to the session's event loop (see run).
 type GameSession struct {
+	ID         string
 	GameEngine *game.GameSession
 	PlayerA    *Client
 	PlayerB    *Client
+	PartnerA   *Client               // Teammate of PlayerA in a 2v2 match, nil in 1v1
+	PartnerB   *Client               // Teammate of PlayerB in a 2v2 match, nil in 1v1
+	Settings   models.MatchSettings  // Match settings (mode, turn timer, starting mana)
+	Specs      *models.SpecSet       // Troop and tower specs the match is played with
+	Spectators map[string]*Spectator // map of username to spectator watching the match
+	turnTimer  *time.Timer           // Skips the current turn when the turn timer runs out
+	phaseTimer *time.Timer           // Ends regular time or overtime of a match played against the clock
+
+	commands      []sessionCommand // Commands waiting for the event loop
+	commandsMutex sync.Mutex       // Guards commands and stopped
+	stopped       bool             // Set once the match is over; later commands are dropped
+	wake          chan struct{}    // Signals the event loop that commands are waiting
+	done          chan struct{}    // Closed when the event loop returns
 }
 
 // GameServer represents the TCP game server
 type GameServer struct {
-	Addr          string
-	Listener      net.Listener
-	Clients       map[string]*Client      // map of username to client
-	GameSessions  map[string]*GameSession // map of session ID to game session
-	WaitingPlayer *Client                 // Player waiting for a match
-	TroopSpecs    []models.TroopSpec
-	TowerSpecs    []models.TowerSpec
-	JSONHandler   *storage.JSONHandler
-	mutex         sync.Mutex
+	Addr         string
+	Listener     net.Listener
+	Clients      map[string]*Client      // map of username to client
+	GameSessions map[string]*GameSession // map of session ID to game session
+	MatchQueue   *MatchQueue             // Players waiting for a rated match
+	Rooms        map[string]*Room        // map of room code to private room
+	Challenges   map[string]*Challenge   // map of "challenger>target" to pending challenge
+	Rematches    map[string]*Rematch     // map of username to the rematch offer after their last game
+	Specs        *models.SpecSet         // Troop and tower specs new matches are played with (see ReloadSpecs)
+	JSONHandler  *storage.JSONHandler
+	Config       *config.Config // Server configuration, including the game rules of each mode
+	mutex        sync.Mutex
+	reloadMutex  sync.Mutex // Serializes spec reloads
+
+	presenceUpdates chan *Client // Clients whose lobby presence changed
+
+	connections  map[*Client]bool // Every open connection, logged in or not
+	shuttingDown bool             // Set once the server stops accepting connections
+	quit         chan struct{}    // Closed when the server stops accepting connections
 }
 
-// NewServer creates a new game server
-func NewServer(addr string, jsonHandler *storage.JSONHandler) *GameServer {
+// NewServer creates a new game server with the given (validated) configuration
+func NewServer(cfg *config.Config, jsonHandler *storage.JSONHandler) *GameServer {
 	return &GameServer{
-		Addr:         addr,
+		Addr:         cfg.Server.ListenAddr,
 		Clients:      make(map[string]*Client),
 		GameSessions: make(map[string]*GameSession),
+		MatchQueue:   NewMatchQueue(cfg.Matchmaking),
+		Rooms:        make(map[string]*Room),
+		Challenges:   make(map[string]*Challenge),
+		Rematches:    make(map[string]*Rematch),
 		JSONHandler:  jsonHandler,
+		Config:       cfg,
+
+		presenceUpdates: make(chan *Client, shared.PresenceUpdateBufferSize),
+		connections:     make(map[*Client]bool),
+		quit:            make(chan struct{}),
 	}
 }
 
 // Start starts the server and begins listening for connections
 func (s *GameServer) Start() error {
 	// Load game specifications
-	var err error
-	s.TroopSpecs, err = s.JSONHandler.LoadTroopSpecs()
-	if err != nil {
-		return fmt.Errorf("failed to load troop specs: %v", err)
-	}
-
-	s.TowerSpecs, err = s.JSONHandler.LoadTowerSpecs()
-	if err != nil {
-		return fmt.Errorf("failed to load tower specs: %v", err)
+	if _, err := s.ReloadSpecs(); err != nil {
+		return fmt.Errorf("failed to load game specs: %v", err)
 	}
 
-	log.Printf("Loaded %d troop specs and %d tower specs", len(s.TroopSpecs), len(s.TowerSpecs))
+	var err error
 
 	// Start listening
 	s.Listener, err = net.Listen("tcp", s.Addr)
@@ -73,9 +116,25 @@ func (s *GameServer) Start() error {
 
 	log.Printf("Server started on %s", s.Addr)
 
+	// Periodically re-run matchmaking so waiting players accept wider rating gaps
+	go s.runMatchmaker()
+
+	// Push lobby presence changes to logged-in clients
+	go s.runPresenceBroadcaster()
+
+	// Pick up balance changes to the spec files
+	if s.Config.Storage.WatchSeconds > 0 {
+		go s.watchSpecs(config.Seconds(s.Config.Storage.WatchSeconds))
+	}
+
 	for {
 		conn, err := s.Listener.Accept()
 		if err != nil {
+			select {
+			case <-s.quit:
+				return ErrServerClosed
+			default:
+			}
 			log.Printf("Error accepting connection: %v", err)
 			continue
 		}
@@ -87,45 +146,60 @@ func (s *GameServer) Start() error {
 	}
 }
 
-// Stop stops the server
+// Stop stops the server immediately, closing every connection. See Shutdown for a graceful stop.
 func (s *GameServer) Stop() error {
-	// Close all client connections
 	s.mutex.Lock()
-	for _, client := range s.Clients {
-		client.Conn.Close()
+	defer s.mutex.Unlock()
+
+	// Close all client connections
+	for client := range s.connections {
+		client.Close()
 	}
-	s.mutex.Unlock()
 
 	// Close the listener
-	if s.Listener != nil {
-		return s.Listener.Close()
-	}
-	return nil
+	return s.stopAccepting()
 }
 
 // handleClient handles a client connection
 func (s *GameServer) handleClient(conn net.Conn) {
 	// Create a new client
-	client := &Client{
-		Conn:   conn,
-		InGame: false,
+	client := newClient(conn, config.Seconds(s.Config.Timeouts.ClientWriteSeconds))
+
+	s.mutex.Lock()
+	if s.shuttingDown {
+		s.mutex.Unlock()
+		client.Close()
+		return
 	}
+	s.connections[client] = true
+	s.mutex.Unlock()
 
 	// Cleanup when this function exits
 	defer func() {
 		// Remove client from game session if in one
 		s.mutex.Lock()
 		if client.Username != "" {
+			// Waiting players are taken out of the queue, their room and challenges
+			s.removeFromQueue(client)
+			s.removeRoomsAndChallenges(client)
+			s.clearRematch(client, fmt.Sprintf("%s left. The rematch is no longer available.", client.Username))
+			if client.Spectating != "" {
+				s.stopSpectating(client)
+			}
 			if client.InGame {
 				// Handle game cleanup if in a game
 				s.handlePlayerDisconnect(client)
 			}
 			delete(s.Clients, client.Username)
 		}
+		delete(s.connections, client)
 		s.mutex.Unlock()
 
+		// Let the lobby know the player went offline
+		s.notifyPresence(client)
+
 		// Close connection
-		conn.Close()
+		client.Close()
 		log.Printf("Connection from %s closed", conn.RemoteAddr())
 	}()
 
@@ -148,6 +222,48 @@ func (s *GameServer) handleClient(conn net.Conn) {
 			s.handleDeployTroop(client, message.Payload)
 		case models.MsgTypeSkipTurnCommand:
 			s.handleSkipTurn(client, message.Payload)
+		case models.MsgTypeJoinQueue:
+			s.handleJoinQueue(client, message.Payload)
+		case models.MsgTypeLeaveQueue:
+			s.handleLeaveQueue(client, message.Payload)
+		case models.MsgTypeCreateRoom:
+			s.handleCreateRoom(client, message.Payload)
+		case models.MsgTypeJoinRoom:
+			s.handleJoinRoom(client, message.Payload)
+		case models.MsgTypeLeaveRoom:
+			s.handleLeaveRoom(client, message.Payload)
+		case models.MsgTypeRoomReady:
+			s.handleRoomReady(client, message.Payload)
+		case models.MsgTypeChallengeRequest:
+			s.handleChallengeRequest(client, message.Payload)
+		case models.MsgTypeChallengeResponse:
+			s.handleChallengeResponse(client, message.Payload)
+		case models.MsgTypeListPlayers:
+			s.handleListPlayers(client, message.Payload)
+		case models.MsgTypeListGames:
+			s.handleListGames(client, message.Payload)
+		case models.MsgTypeSpectateGame:
+			s.handleSpectateGame(client, message.Payload)
+		case models.MsgTypeStopSpectating:
+			s.handleStopSpectating(client, message.Payload)
+		case models.MsgTypeSurrender:
+			s.handleSurrender(client, message.Payload)
+		case models.MsgTypeOfferDraw:
+			s.handleOfferDraw(client, message.Payload)
+		case models.MsgTypeAcceptDraw:
+			s.handleAcceptDraw(client, message.Payload)
+		case models.MsgTypeDeclineDraw:
+			s.handleDeclineDraw(client, message.Payload)
+		case models.MsgTypeRequestRematch:
+			s.handleRequestRematch(client, message.Payload)
+		case models.MsgTypeChatMessage:
+			s.handleChatMessage(client, message.Payload)
+		case models.MsgTypeMutePlayer:
+			s.handleMutePlayer(client, message.Payload)
+		case models.MsgTypeGetCollection:
+			s.handleGetCollection(client, message.Payload)
+		case models.MsgTypeSetDeck:
+			s.handleSetDeck(client, message.Payload)
 		default:
 			log.Printf("Unknown message type: %s", message.Type)
 		}
@@ -159,20 +275,20 @@ func (s *GameServer) handleRegister(client *Client, payload interface{}) {
 	// Parse register payload
 	registerPayload, ok := payload.(map[string]interface{})
 	if !ok {
-		sendError(client.Conn, "Invalid registration payload")
+		sendError(client, "Invalid registration payload")
 		return
 	}
 
 	// Extract username and password
 	username, ok := registerPayload["username"].(string)
 	if !ok || username == "" {
-		sendError(client.Conn, "Invalid username")
+		sendError(client, "Invalid username")
 		return
 	}
 
 	password, ok := registerPayload["password"].(string)
 	if !ok || password == "" {
-		sendError(client.Conn, "Invalid password")
+		sendError(client, "Invalid password")
 		return
 	}
 
@@ -189,7 +305,7 @@ func (s *GameServer) handleRegister(client *Client, payload interface{}) {
 			Payload: registerResponse,
 		}
 
-		WriteMessage(client.Conn, responseMsg)
+		client.Send(responseMsg)
 		return
 	}
 
@@ -202,7 +318,7 @@ func (s *GameServer) handleRegister(client *Client, payload interface{}) {
 	err := s.JSONHandler.SaveUserData(userData)
 	if err != nil {
 		log.Printf("Error saving user data: %v", err)
-		sendError(client.Conn, "Failed to register user")
+		sendError(client, "Failed to register user")
 		return
 	}
 
@@ -219,7 +335,7 @@ func (s *GameServer) handleRegister(client *Client, payload interface{}) {
 		Payload: registerResponse,
 	}
 
-	WriteMessage(client.Conn, responseMsg)
+	client.Send(responseMsg)
 }
 
 // handleLogin handles a login request
@@ -227,14 +343,14 @@ func (s *GameServer) handleLogin(client *Client, payload interface{}) {
 	// Parse login payload
 	loginPayload, ok := payload.(map[string]interface{})
 	if !ok {
-		sendError(client.Conn, "Invalid login payload")
+		sendError(client, "Invalid login payload")
 		return
 	}
 
 	// Extract username and password
 	username, ok := loginPayload["username"].(string)
 	if !ok || username == "" {
-		sendError(client.Conn, "Invalid username")
+		sendError(client, "Invalid username")
 		return
 	}
 
@@ -246,7 +362,7 @@ func (s *GameServer) handleLogin(client *Client, payload interface{}) {
 
 	// Check if user exists
 	if !s.JSONHandler.UserExists(username) {
-		sendError(client.Conn, "User does not exist")
+		sendError(client, "User does not exist")
 		return
 	}
 
@@ -254,27 +370,37 @@ func (s *GameServer) handleLogin(client *Client, payload interface{}) {
 	userData, err := s.JSONHandler.LoadUserData(username)
 	if err != nil {
 		log.Printf("Error loading user data: %v", err)
-		sendError(client.Conn, "Login failed")
+		sendError(client, "Login failed")
 		return
 	}
 
 	if userData.Password != password {
-		sendError(client.Conn, "Invalid password")
+		sendError(client, "Invalid password")
 		return
 	}
 
+	// Load the player's profile for lobby presence
+	profile, err := s.JSONHandler.LoadPlayerData(username)
+	if err != nil {
+		log.Printf("Error loading player data for %s: %v. Using defaults for lobby presence.", username, err)
+		profile.Level = 1
+		profile.Rating = shared.DefaultRating
+	}
+
 	// Check if username is already logged in
 	s.mutex.Lock()
 	_, exists := s.Clients[username]
 	if exists {
 		s.mutex.Unlock()
-		sendError(client.Conn, "User already logged in")
+		sendError(client, "User already logged in")
 		return
 	}
 
 	// Update client info and add to clients map
 	client.Username = username
 	client.PlayerID = username // Using username as player ID for now
+	client.Level = profile.Level
+	client.Rating = profile.Rating
 	s.Clients[username] = client
 	s.mutex.Unlock()
 
@@ -292,212 +418,245 @@ func (s *GameServer) handleLogin(client *Client, payload interface{}) {
 		Payload: loginResponse,
 	}
 
-	err = WriteMessage(client.Conn, responseMsg)
+	err = client.Send(responseMsg)
 	if err != nil {
 		log.Printf("Error sending login response: %v", err)
 		return
 	}
 
-	// Try to match the player
-	s.tryMatchPlayer(client)
+	// Show the new player who is online and tell everyone else they arrived
+	s.sendPlayerList(client)
+	s.notifyPresence(client)
 }
 
-// tryMatchPlayer attempts to match a player with another waiting player
-func (s *GameServer) tryMatchPlayer(client *Client) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// createGameSession creates a new game session between two players.
+// The caller must hold s.mutex.
+func (s *GameServer) createGameSession(playerA, playerB *Client, settings models.MatchSettings) {
+	s.startGameSession([]*Client{playerA}, []*Client{playerB}, settings)
+}
 
-	// If no waiting player, set this client as waiting
-	if s.WaitingPlayer == nil {
-		s.WaitingPlayer = client
-		log.Printf("Player %s is waiting for a match", client.Username)
-
-		// Send notification to client
-		message := "Waiting for another player to join..."
-		notificationMsg := models.GenericMessage{
-			Type: models.MsgTypeErrorNotification, // Reusing error for notifications
-			Payload: models.ErrorNotificationPayload{
-				ErrorMessage: message,
-			},
+// startGameSession creates a new game session between two sides of one player each, or of two
+// players each in 2v2 (see createTeamGameSession). The caller must hold s.mutex.
+func (s *GameServer) startGameSession(teamA, teamB []*Client, settings models.MatchSettings) {
+	players := append(append([]*Client{}, teamA...), teamB...)
+	if s.shuttingDown {
+		for _, player := range players {
+			sendNotice(player, "The server is shutting down. No new games can be started.")
 		}
-		WriteMessage(client.Conn, notificationMsg)
 		return
 	}
 
-	// Don't match player with themselves (if reconnecting)
-	if s.WaitingPlayer.Username == client.Username {
-		log.Printf("Player %s reconnected, still waiting for opponent", client.Username)
-		return
+	// Create game engine with the current specs; the match keeps them if specs are reloaded
+	specs := s.Specs
+	rules := game.NewRuleSet(s.Config, settings)
+	var gameEngine *game.GameSession
+	if len(teamA) == shared.TeamMatchSize {
+		gameEngine = game.NewTeamGameSession([2]string{teamA[0].Username, teamA[1].Username}, [2]string{teamB[0].Username, teamB[1].Username},
+			specs.Troops, specs.Towers, s.JSONHandler, settings, rules)
+	} else {
+		gameEngine = game.NewGameSessionWithSettings(teamA[0].Username, teamB[0].Username, specs.Troops, specs.Towers, s.JSONHandler, settings, rules)
 	}
 
-	// We have a match!
-	playerA := s.WaitingPlayer
-	playerB := client
-	s.WaitingPlayer = nil
-
-	log.Printf("Matched players %s and %s", playerA.Username, playerB.Username)
-
-	// Create a new game session
-	s.createGameSession(playerA, playerB)
-}
-
-// createGameSession creates a new game session between two players
-func (s *GameServer) createGameSession(playerA, playerB *Client) {
-	// Create game engine
-	gameEngine := game.NewGameSession(playerA.Username, playerB.Username, s.TroopSpecs, s.TowerSpecs, s.JSONHandler)
-
 	// Create game session
-	sessionID := fmt.Sprintf("%s_vs_%s", playerA.Username, playerB.Username)
+	sessionID := sessionIDFor(teamA, teamB)
 	session := &GameSession{
+		ID:         sessionID,
 		GameEngine: gameEngine,
-		PlayerA:    playerA,
-		PlayerB:    playerB,
+		PlayerA:    teamA[0],
+		PlayerB:    teamB[0],
+		Settings:   settings,
+		Specs:      specs,
+		Spectators: make(map[string]*Spectator),
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	if len(teamA) == shared.TeamMatchSize {
+		session.PartnerA = teamA[1]
+		session.PartnerB = teamB[1]
+	}
+	go session.run()
+
+	// Players who were watching another match stop spectating, and older rematch offers lapse
+	for _, player := range players {
+		s.clearRematch(player, fmt.Sprintf("%s started another game.", player.Username))
+		if player.Spectating != "" {
+			spectatedID := player.Spectating
+			s.stopSpectating(player)
+			sendSpectateEnd(player, spectatedID, "Your own match is starting.")
+		}
 	}
 
 	// Update client states
-	playerA.InGame = true
-	playerB.InGame = true
+	for _, player := range players {
+		player.InGame = true
+	}
 
 	// Add session to map
 	s.GameSessions[sessionID] = session
 
-	log.Printf("Created game session %s", sessionID)
+	log.Printf("Created %s game session %s with %s rules and specs v%d", settings.Mode, sessionID, gameEngine.GameState.Rules.Name(), specs.Version)
+	for _, player := range players {
+		s.notifyPresence(player)
+	}
 
-	// Send game start notifications to both players
-	s.sendGameStartNotifications(session)
+	// Send game start notifications to every player
+	session.post(func() {
+		s.sendGameStartNotifications(session)
+	})
 }
 
-// sendGameStartNotifications sends game start notifications to both players
+// sendGameStartNotifications sends game start notifications to every player
 func (s *GameServer) sendGameStartNotifications(session *GameSession) {
-	playerA := session.PlayerA
-	playerB := session.PlayerB
-	gameEngine := session.GameEngine
-
-	// Prepare game start notification for player A
-	playerAState := s.createPlayerState(gameEngine.GameState.PlayerA)
-	playerBState := s.createPlayerState(gameEngine.GameState.PlayerB)
-
-	// Notification for Player A
-	playerANotification := models.GameStartNotificationPayload{
-		OpponentUsername: playerB.Username,
-		YourPlayerInfo:   playerAState,
-		GameMode:         "SIMPLE", // For now, only Simple TCR is supported
-	}
-
-	playerAMsg := models.GenericMessage{
-		Type:    models.MsgTypeGameStartNotification,
-		Payload: playerANotification,
-	}
-
-	// Notification for Player B
-	playerBNotification := models.GameStartNotificationPayload{
-		OpponentUsername: playerA.Username,
-		YourPlayerInfo:   playerBState,
-		GameMode:         "SIMPLE", // For now, only Simple TCR is supported
-	}
-
-	playerBMsg := models.GenericMessage{
-		Type:    models.MsgTypeGameStartNotification,
-		Payload: playerBNotification,
-	}
-
-	// Send notifications
-	err := WriteMessage(playerA.Conn, playerAMsg)
-	if err != nil {
-		log.Printf("Error sending game start notification to %s: %v", playerA.Username, err)
-	}
+	gameState := session.GameEngine.GameState
+	rules := gameState.Rules
+	handicap := handicapPayload(gameState.Handicap)
+
+	for _, client := range session.players() {
+		player := gameState.Player(client.Username)
+		opponent := gameState.OpposingSide(client.Username)
+
+		notification := models.GameStartNotificationPayload{
+			OpponentUsername:       opponent.Username,
+			YourPlayerInfo:         ProjectPlayerState(s.createPlayerState(player, rules), session.viewerFor(client), session.Settings),
+			GameMode:               session.Settings.Mode,
+			Settings:               session.Settings,
+			SpecVersion:            session.Specs.Version,
+			Targeting:              rules.Params().Targeting,
+			YourEffectiveLevel:     player.EffectiveLevel(),
+			OpponentEffectiveLevel: opponent.EffectiveLevel(),
+			Handicap:               handicap,
+		}
+		if session.isTeamMatch() {
+			notification.Teammate = session.teammateOf(client).Username
+			notification.Opponents = usernames(gameState.Team(opponent))
+			notification.TurnOrder = usernames(gameState.TurnOrder)
+		}
 
-	err = WriteMessage(playerB.Conn, playerBMsg)
-	if err != nil {
-		log.Printf("Error sending game start notification to %s: %v", playerB.Username, err)
+		startMsg := models.GenericMessage{
+			Type:    models.MsgTypeGameStartNotification,
+			Payload: notification,
+		}
+		if err := client.Send(startMsg); err != nil {
+			log.Printf("Error sending game start notification to %s: %v", client.Username, err)
+		}
 	}
 
-	// Send initial game state update to both players
+	// Start the match clock, then send the initial game state update to every player
+	s.startPhaseTimer(session)
 	s.broadcastGameState(session, "")
 
 	// Send turn notification to the first player
 	s.sendTurnNotification(session)
 }
 
-// createPlayerState creates a PlayerState from a game.Player
-func (s *GameServer) createPlayerState(player *game.Player) models.PlayerState {
+// handicapPayload describes the handicap bonus of a match, or returns nil if there is none
+func handicapPayload(handicap *game.Handicap) *models.HandicapPayload {
+	if handicap == nil {
+		return nil
+	}
+	return &models.HandicapPayload{
+		Username: handicap.Username,
+		Kind:     handicap.Kind,
+		Amount:   handicap.Amount,
+	}
+}
+
+// createPlayerState creates a PlayerState from a game.Player playing by the given rule set
+func (s *GameServer) createPlayerState(player *game.Player, rules game.RuleSet) models.PlayerState {
 	// Create tower states
-	kingTowerState := models.TowerState{
-		ID:        player.KingTower.ID,
-		Type:      player.KingTower.Spec.Type,
-		CurrentHP: player.KingTower.CurrentHP,
-		MaxHP:     player.KingTower.MaxHP,
-		Attack:    player.KingTower.CurrentATK,
-		Defense:   player.KingTower.CurrentDEF,
-		Destroyed: player.KingTower.Destroyed,
-	}
-
-	guardTower1State := models.TowerState{
-		ID:        player.GuardTower1.ID,
-		Type:      player.GuardTower1.Spec.Type,
-		CurrentHP: player.GuardTower1.CurrentHP,
-		MaxHP:     player.GuardTower1.MaxHP,
-		Attack:    player.GuardTower1.CurrentATK,
-		Defense:   player.GuardTower1.CurrentDEF,
-		Destroyed: player.GuardTower1.Destroyed,
-	}
-
-	guardTower2State := models.TowerState{
-		ID:        player.GuardTower2.ID,
-		Type:      player.GuardTower2.Spec.Type,
-		CurrentHP: player.GuardTower2.CurrentHP,
-		MaxHP:     player.GuardTower2.MaxHP,
-		Attack:    player.GuardTower2.CurrentATK,
-		Defense:   player.GuardTower2.CurrentDEF,
-		Destroyed: player.GuardTower2.Destroyed,
+	towerStates := make([]models.TowerState, len(player.Towers))
+	for i, tower := range player.Towers {
+		towerStates[i] = models.TowerState{
+			ID:         tower.ID,
+			Name:       tower.Spec.Name,
+			Type:       tower.Spec.Type,
+			Role:       tower.Spec.Role,
+			Requires:   tower.Spec.Requires,
+			CurrentHP:  tower.CurrentHP,
+			MaxHP:      tower.MaxHP,
+			Attack:     tower.CurrentATK,
+			Defense:    tower.CurrentDEF,
+			Destroyed:  tower.Destroyed,
+			Targetable: rules.CanTarget(player, tower),
+		}
 	}
 
 	// Create troop states
 	troopStates := make([]models.TroopState, len(player.Troops))
 	for i, troop := range player.Troops {
 		troopStates[i] = models.TroopState{
-			Name:     troop.Spec.Name,
-			HP:       troop.CurrentHP,
-			Attack:   troop.CurrentATK,
-			Defense:  troop.CurrentDEF,
-			ManaCost: troop.Spec.ManaCost,
+			Name:                    troop.Spec.Name,
+			HP:                      troop.CurrentHP,
+			Attack:                  troop.CurrentATK,
+			Defense:                 troop.CurrentDEF,
+			ManaCost:                troop.Spec.ManaCost,
+			CardType:                troop.Spec.Card(),
+			ArmorPenetrationPercent: troop.Spec.ArmorPenetrationPercent,
+			PreferredTarget:         troop.Spec.PreferredTarget,
+			SplashPercent:           troop.Spec.SplashPercent,
+			ChainTargets:            troop.Spec.ChainTargets,
+			ChainFalloffPercent:     troop.Spec.ChainFalloffPercent,
+			Lifetime:                troop.Spec.Lifetime,
+			Protects:                troop.Spec.Protects,
 		}
+		if troop.Spec.Card() == shared.CardSpell {
+			troopStates[i].Description = game.SpellDescription(troop, rules)
+		} else if chance, multiplier := rules.CriticalHit(troop.Spec); chance > 0 && troop.Spec.Card() == shared.CardTroop {
+			troopStates[i].CritChancePercent = chance
+			troopStates[i].CritMultiplier = multiplier
+		}
+	}
+
+	// Create building states; in 2v2 the buildings of a side stand with its tower owner
+	var buildingStates []models.BuildingState
+	for _, building := range player.Buildings {
+		buildingStates = append(buildingStates, models.BuildingState{
+			ID:          building.ID,
+			Name:        building.Spec.Name,
+			Owner:       building.Owner,
+			CurrentHP:   building.CurrentHP,
+			MaxHP:       building.MaxHP,
+			Attack:      building.CurrentATK,
+			Defense:     building.CurrentDEF,
+			Protects:    building.Spec.Protects,
+			TurnsLeft:   building.TurnsLeft,
+			FrozenTurns: building.FrozenTurns,
+		})
 	}
 
 	return models.PlayerState{
 		Username:                player.Username,
-		KingTower:               kingTowerState,
-		GuardTower1:             guardTower1State,
-		GuardTower2:             guardTower2State,
+		Towers:                  towerStates,
 		Troops:                  troopStates,
+		Buildings:               buildingStates,
 		Level:                   player.Level,
 		CurrentEXP:              player.CurrentEXP,
 		RequiredEXPForNextLevel: player.RequiredEXPForNextLevel,
 		CurrentMana:             player.CurrentMana,
-		MaxMana:                 shared.MaxMana,
+		MaxMana:                 rules.MaxMana(),
+		RagePercent:             player.RagePercent,
 	}
 }
 
-// broadcastGameState sends the current game state to both players
+// broadcastGameState sends the current game state to every player and the spectators
 func (s *GameServer) broadcastGameState(session *GameSession, lastActionLog string) {
-	gameEngine := session.GameEngine
-
-	// Create game state update
-	stateUpdate := models.GameStateUpdatePayload{
-		PlayerA:       s.createPlayerState(gameEngine.GameState.PlayerA),
-		PlayerB:       s.createPlayerState(gameEngine.GameState.PlayerB),
-		CurrentTurn:   gameEngine.GameState.CurrentTurn,
-		LastActionLog: lastActionLog,
+	// Each player only sees the opponents' hand sizes
+	for _, player := range session.players() {
+		player.Send(s.gameStateMessage(session, session.viewerFor(player), lastActionLog))
 	}
 
-	gameStateMsg := models.GenericMessage{
+	// Spectators receive the same update with both hands hidden, after their delay
+	s.broadcastToSpectators(session, s.gameStateMessage(session, Viewer{Role: ViewerSpectator}, lastActionLog))
+}
+
+// gameStateMessage builds a GAME_STATE_UPDATE message for the current state of a session as seen by a viewer
+func (s *GameServer) gameStateMessage(session *GameSession, viewer Viewer, lastActionLog string) models.GenericMessage {
+	stateUpdate := s.ProjectGameState(session.GameEngine.GameState, session.Settings, viewer, lastActionLog)
+
+	return models.GenericMessage{
 		Type:    models.MsgTypeGameStateUpdate,
 		Payload: stateUpdate,
 	}
-
-	// Send to both players
-	WriteMessage(session.PlayerA.Conn, gameStateMsg)
-	WriteMessage(session.PlayerB.Conn, gameStateMsg)
 }
 
 // sendTurnNotification sends a turn notification to the current player
@@ -507,7 +666,8 @@ func (s *GameServer) sendTurnNotification(session *GameSession) {
 
 	// Create turn notification
 	turnNotification := models.TurnNotificationPayload{
-		CurrentTurnUsername: currentTurn,
+		CurrentTurnUsername:  currentTurn,
+		TurnTimeLimitSeconds: session.Settings.TurnTimerSeconds,
 	}
 
 	turnMsg := models.GenericMessage{
@@ -515,22 +675,109 @@ func (s *GameServer) sendTurnNotification(session *GameSession) {
 		Payload: turnNotification,
 	}
 
-	// Determine which client should receive the notification
-	var currentPlayer *Client
-	if currentTurn == session.PlayerA.Username {
-		currentPlayer = session.PlayerA
+	// Send notification to the player whose turn it is
+	if currentPlayer := session.playerClient(currentTurn); currentPlayer != nil {
+		currentPlayer.Send(turnMsg)
+	}
+
+	// Restart the turn timer for the player whose turn it is now
+	s.startTurnTimer(session)
+}
+
+// startTurnTimer (re)starts the turn timer of a session if the match uses one
+func (s *GameServer) startTurnTimer(session *GameSession) {
+	if session.turnTimer != nil {
+		session.turnTimer.Stop()
+	}
+
+	if session.Settings.TurnTimerSeconds <= 0 {
+		return
+	}
+
+	// Remember which turn the timer belongs to, so a stale timer never skips a later turn
+	username := session.GameEngine.GameState.CurrentTurn
+	turnNumber := session.GameEngine.GameState.TurnNumber
+	session.turnTimer = time.AfterFunc(time.Duration(session.Settings.TurnTimerSeconds)*time.Second, func() {
+		session.post(func() {
+			s.handleTurnTimeout(session, username, turnNumber)
+		})
+	})
+}
+
+// handleTurnTimeout skips the turn of a player who ran out of time
+func (s *GameServer) handleTurnTimeout(session *GameSession, username string, turnNumber int) {
+	gameState := session.GameEngine.GameState
+	if gameState.IsGameOver || gameState.CurrentTurn != username || gameState.TurnNumber != turnNumber {
+		return
+	}
+
+	// A bonus attack that was not used in time is forfeited
+	gameState.CanContinueAttacking = false
+
+	actionResultMsg, success := session.GameEngine.SkipTurn(username)
+	if !success {
+		log.Printf("Could not skip timed-out turn of %s: %s", username, actionResultMsg)
+		return
+	}
+
+	log.Printf("Turn of %s timed out", username)
+	s.broadcastGameState(session, fmt.Sprintf("%s ran out of time. %s", username, actionResultMsg))
+	s.sendTurnNotification(session)
+}
+
+// startPhaseTimer starts the clock of the current phase (regular time or overtime) of a match
+// played against the clock
+func (s *GameServer) startPhaseTimer(session *GameSession) {
+	gameState := session.GameEngine.GameState
+	duration := gameState.Rules.MatchDuration()
+	if gameState.InOvertime() {
+		duration = gameState.Rules.OvertimeDuration()
+	}
+	if duration <= 0 {
+		return
+	}
+
+	// Remember which phase the timer belongs to, so a stale timer never ends a later phase
+	phase := gameState.Phase
+	gameState.PhaseEndsAt = time.Now().Add(duration)
+	session.phaseTimer = time.AfterFunc(duration, func() {
+		session.post(func() {
+			s.handlePhaseTimeout(session, phase)
+		})
+	})
+}
+
+// handlePhaseTimeout decides a match whose regular time or overtime ran out.
+// A tie on time starts overtime if the rule set has one.
+func (s *GameServer) handlePhaseTimeout(session *GameSession, phase string) {
+	gameState := session.GameEngine.GameState
+	if gameState.IsGameOver || gameState.Phase != phase {
+		return
+	}
+
+	var resultMessage string
+	if phase == shared.PhaseOvertime {
+		resultMessage = session.GameEngine.EndOvertime()
 	} else {
-		currentPlayer = session.PlayerB
+		var overtime bool
+		resultMessage, overtime = session.GameEngine.EndRegularTime()
+		if overtime {
+			log.Printf("Game session %s went to overtime", session.ID)
+			s.startPhaseTimer(session)
+			s.broadcastGameState(session, resultMessage)
+			return
+		}
 	}
 
-	// Send notification
-	WriteMessage(currentPlayer.Conn, turnMsg)
+	log.Printf("Time ran out in game session %s", session.ID)
+	s.broadcastGameState(session, resultMessage)
+	s.handleGameOver(session)
 }
 
 // getSessionForPlayer finds the game session for a given player
 func (s *GameServer) getSessionForPlayer(client *Client) *GameSession {
 	for _, session := range s.GameSessions {
-		if session.PlayerA.Username == client.Username || session.PlayerB.Username == client.Username {
+		if session.hasPlayer(client) {
 			return session
 		}
 	}
@@ -539,52 +786,57 @@ func (s *GameServer) getSessionForPlayer(client *Client) *GameSession {
 
 // handleDeployTroop handles a deploy troop command
 func (s *GameServer) handleDeployTroop(client *Client, payload interface{}) {
-	// Check if player is in a game
-	s.mutex.Lock()
-	session := s.getSessionForPlayer(client)
-	s.mutex.Unlock()
-
-	if session == nil {
-		sendError(client.Conn, "You are not in a game")
-		return
-	}
-
 	// Parse deploy troop payload
 	deployPayload, ok := payload.(map[string]interface{})
 	if !ok {
-		sendError(client.Conn, "Invalid deploy troop payload")
+		sendError(client, "Invalid deploy troop payload")
 		return
 	}
 
 	// Extract troop name and target tower ID
 	troopName, ok := deployPayload["troopName"].(string)
 	if !ok || troopName == "" {
-		sendError(client.Conn, "Invalid troop name")
+		sendError(client, "Invalid troop name")
 		return
 	}
 
+	// An empty or missing target lets the troop pick one by its preferred target
 	targetTowerID, ok := deployPayload["targetTowerID"].(string)
-	if !ok || targetTowerID == "" {
-		sendError(client.Conn, "Invalid target tower ID")
+	if _, present := deployPayload["targetTowerID"]; present && !ok {
+		sendError(client, "Invalid target tower ID")
 		return
 	}
 
 	// Pass command to the game engine
+	s.submitAction(client, func(session *GameSession) {
+		s.deployTroop(session, client, troopName, targetTowerID)
+	})
+}
+
+// deployTroop applies a deploy troop command on the session's event loop
+func (s *GameServer) deployTroop(session *GameSession, client *Client, troopName, targetTowerID string) {
 	actionResultMsg, success := session.GameEngine.DeployTroop(client.Username, troopName, targetTowerID)
 
 	// Send action result to the player
+	action := fmt.Sprintf("Deploy %s to %s", troopName, targetTowerID)
+	if targetTowerID == "" {
+		action = fmt.Sprintf("Deploy %s", troopName)
+	}
 	actionResult := models.ActionResultPayload{
 		Success: success,
-		Action:  fmt.Sprintf("Deploy %s to %s", troopName, targetTowerID),
+		Action:  action,
 		Message: actionResultMsg,
 	}
+	if success {
+		actionResult.Hits = hitResults(session.GameEngine.GameState.LastHits)
+	}
 
 	resultMsg := models.GenericMessage{
 		Type:    models.MsgTypeActionResult,
 		Payload: actionResult,
 	}
 
-	WriteMessage(client.Conn, resultMsg)
+	client.Send(resultMsg)
 
 	// If successful, broadcast updated game state to both players
 	if success {
@@ -603,19 +855,16 @@ func (s *GameServer) handleDeployTroop(client *Client, payload interface{}) {
 
 // handleSkipTurn handles a skip turn command
 func (s *GameServer) handleSkipTurn(client *Client, payload interface{}) {
-	// Check if player is in a game
-	s.mutex.Lock()
-	session := s.getSessionForPlayer(client)
-	s.mutex.Unlock()
-
-	if session == nil {
-		sendError(client.Conn, "You are not in a game")
-		return
-	}
-
 	// No payload to parse for skip turn, just the client's username is needed.
 
 	// Pass command to the game engine
+	s.submitAction(client, func(session *GameSession) {
+		s.skipTurn(session, client)
+	})
+}
+
+// skipTurn applies a skip turn command on the session's event loop
+func (s *GameServer) skipTurn(session *GameSession, client *Client) {
 	actionResultMsg, success := session.GameEngine.SkipTurn(client.Username)
 
 	// Send action result to the player who skipped
@@ -630,7 +879,7 @@ func (s *GameServer) handleSkipTurn(client *Client, payload interface{}) {
 		Payload: actionResult,
 	}
 
-	WriteMessage(client.Conn, resultMsg)
+	client.Send(resultMsg)
 
 	// If successful, broadcast updated game state to both players
 	if success {
@@ -649,92 +898,31 @@ func (s *GameServer) handleSkipTurn(client *Client, payload interface{}) {
 	}
 }
 
-// handleGameOver handles game over events
+// handleGameOver handles game over events. Runs on the session's event loop, which it stops.
 func (s *GameServer) handleGameOver(session *GameSession) {
-	winnerUsername := session.GameEngine.GameState.Winner // Username of the winner
-	var winningPlayer *game.Player
-	var losingPlayer *game.Player
-
-	// Determine player objects
-	if winnerUsername == session.PlayerA.Username {
-		winningPlayer = session.GameEngine.GameState.PlayerA
-		losingPlayer = session.GameEngine.GameState.PlayerB
-	} else if winnerUsername == session.PlayerB.Username {
-		winningPlayer = session.GameEngine.GameState.PlayerB
-		losingPlayer = session.GameEngine.GameState.PlayerA
+	session.stop()
+
+	// The engine has already awarded match EXP and saved every player's profile. In 2v2 the winner
+	// may be either player of the winning side.
+	gameState := session.GameEngine.GameState
+	winnerUsername := gameState.Winner
+	if winningSide := gameState.Side(winnerUsername); winningSide != nil {
+		log.Printf("Game session %s won by %s.", session.ID, gameState.TeamName(winningSide))
 	} else {
-		// This case implies a draw or game ended without a clear winner from GameState
-		// For now, we assume a winner is always set if King Tower is destroyed.
-		// If draw mechanics are added, this section will need adjustment.
-		log.Printf("Game Over for session %s. No clear winner or draw declared in GameState.Winner. Current EXP not changed for win/loss.", session.GameEngine.GameState.PlayerA.Username+"_vs_"+session.GameEngine.GameState.PlayerB.Username)
-		// Save data for both players even if no win/loss EXP is awarded
-		pA := session.GameEngine.GameState.PlayerA
-		pB := session.GameEngine.GameState.PlayerB
-		if err := s.JSONHandler.SavePlayerData(storage.PlayerProfile{
-			Username: pA.Username, Level: pA.Level, CurrentEXP: pA.CurrentEXP, RequiredEXPForNextLevel: pA.RequiredEXPForNextLevel,
-		}); err != nil {
-			log.Printf("Error saving player data for %s: %v", pA.Username, err)
-		}
-		if err := s.JSONHandler.SavePlayerData(storage.PlayerProfile{
-			Username: pB.Username, Level: pB.Level, CurrentEXP: pB.CurrentEXP, RequiredEXPForNextLevel: pB.RequiredEXPForNextLevel,
-		}); err != nil {
-			log.Printf("Error saving player data for %s: %v", pB.Username, err)
-		}
-		// Proceed with standard game over notification without win/loss EXP messages
-	}
-
-	if winningPlayer != nil { // If there was a winner
-		log.Printf("Player %s won. Awarding %d EXP.", winnerUsername, shared.WinEXPReward)
-		// winningPlayer.CurrentEXP += shared.WinEXPReward // This is now handled by GameEngine.HandleGameOver
-		// levelUpMsgWinner := session.GameEngine.HandleExperienceAndLevelUp(winningPlayer) // This is also handled by GameEngine.HandleGameOver
-		// if levelUpMsgWinner != "" {
-		// 	log.Printf("Post-game level up for winner %s: %s", winnerUsername, levelUpMsgWinner)
-		// 	// This message could be appended to the GameOverNotification or sent separately
-		// }
-
-		// GameEngine.HandleGameOver already calls HandleExperienceAndLevelUp which saves player data.
-		// So, explicit saves here might be redundant if GameEngine.HandleGameOver is the sole authority.
-		// However, the original handleGameOver in engine.go was designed to be called BY the network layer (like this one)
-		// and engine.HandleGameOver itself doesn't award match EXP, it relies on the caller.
-
-		// The GameEngine's HandleGameOver (which awards EXP and then calls HandleExperienceAndLevelUp for saving)
-		// should be the one called if we want the engine to manage this. The current server.handleGameOver seems to duplicate some logic.
-
-		// For now, assuming the server's handleGameOver is orchestrating and gameEngine.HandleGameOver was called by DeployTroop.
-		// The main thing is that player data (winningPlayer, losingPlayer) should be saved after any EXP changes.
-		// The call to GameEngine.HandleExperienceAndLevelUp (which saves) is inside GameEngine.HandleGameOver.
-		// If GameEngine.DeployTroop calls GameEngine.HandleGameOver, then saves are done.
-		// This server.handleGameOver might be for cases where the game ends not due to a troop deployment (e.g. disconnect).
-
-		// Let's simplify: if gameEngine.HandleGameOver was called (e.g. from DeployTroop), data is saved.
-		// If this server.handleGameOver is for other reasons (like disconnect), we save here.
-		// The original gameEngine.HandleGameOver modified player stats and called its own HandleExperienceAndLevelUp.
-
-		// Reconciling: game.HandleGameOver in engine.go *is* called by DeployTroop and does EXP and saving.
-		// This network.handleGameOver should primarily be for sending notifications and session cleanup.
-		// It should rely on the game.Player objects already having their EXP/Level updated by the engine.
-
-		// Save winning player's already updated profile (updated by engine)
-		if err := s.JSONHandler.SavePlayerData(storage.PlayerProfile{
-			Username: winningPlayer.Username, Level: winningPlayer.Level, CurrentEXP: winningPlayer.CurrentEXP, RequiredEXPForNextLevel: winningPlayer.RequiredEXPForNextLevel,
-		}); err != nil {
-			log.Printf("Error saving player data for winner %s: %v", winningPlayer.Username, err)
-		}
-
-		// Save loser's data as well (their EXP might have changed from destroying units, and engine would have updated it)
-		if losingPlayer != nil {
-			if err := s.JSONHandler.SavePlayerData(storage.PlayerProfile{
-				Username: losingPlayer.Username, Level: losingPlayer.Level, CurrentEXP: losingPlayer.CurrentEXP, RequiredEXPForNextLevel: losingPlayer.RequiredEXPForNextLevel,
-			}); err != nil {
-				log.Printf("Error saving player data for loser %s: %v", losingPlayer.Username, err)
-			}
-		}
-	} // Add logic for DrawEXPReward if draw state is possible and distinct from no winner.
+		log.Printf("Game session %s ended without a winner (%s).", session.ID, winnerUsername)
+	}
 
 	// Create game over notification (original logic)
+	reason := session.GameEngine.GameState.EndReason
+	if reason == "" {
+		reason = "King Tower destroyed"
+	}
+	log.Printf("Game session %s ended: %s (specs v%d)", session.ID, reason, session.Specs.Version)
 	gameOverPayload := models.GameOverNotificationPayload{
-		WinnerUsername: winnerUsername,         // This remains the same
-		Reason:         "King Tower destroyed", // Or other reason
+		WinnerUsername: winnerUsername, // This remains the same
+		Reason:         reason,
+		WinningTeam:    winningTeam(session.GameEngine.GameState),
+		CardRewards:    session.GameEngine.GameState.CardRewards,
 	}
 
 	gameOverMsg := models.GenericMessage{
@@ -742,45 +930,36 @@ func (s *GameServer) handleGameOver(session *GameSession) {
 		Payload: gameOverPayload,
 	}
 
-	// Send to both players
-	if session.PlayerA != nil && session.PlayerA.Conn != nil {
-		WriteMessage(session.PlayerA.Conn, gameOverMsg)
-	}
-	if session.PlayerB != nil && session.PlayerB.Conn != nil {
-		WriteMessage(session.PlayerB.Conn, gameOverMsg)
+	// Send to every player
+	for _, player := range session.players() {
+		if player.Conn != nil {
+			player.Send(gameOverMsg)
+		}
 	}
+	s.broadcastToSpectators(session, gameOverMsg)
 
 	// Clean up game session (original logic)
 	s.mutex.Lock()
-	if session.PlayerA != nil {
-		session.PlayerA.InGame = false
-	}
-	if session.PlayerB != nil {
-		session.PlayerB.InGame = false
-	}
-	// Use a safe way to identify the session before deleting
-	// This assumes session IDs are formed by player names, which might need to be more robust
-	var sessionID string
-	if session.PlayerA != nil && session.PlayerB != nil {
-		sessionID = session.PlayerA.Username + "_vs_" + session.PlayerB.Username
-	} else if session.PlayerA != nil {
-		// Fallback or handle case where PlayerB might be nil (e.g. disconnected earlier)
-		sessionID = session.PlayerA.Username + "_vs_unknown"
-	} else if session.PlayerB != nil {
-		sessionID = "unknown_vs_" + session.PlayerB.Username
-	}
-	// else if both are nil, sessionID remains empty, delete might not work as expected
-
-	if sessionID != "" {
-		delete(s.GameSessions, sessionID)
-		log.Printf("Cleaned up game session: %s", sessionID)
-	} else {
-		log.Printf("Could not determine session ID for cleanup. Session players: A=%v, B=%v", session.PlayerA, session.PlayerB)
+	for _, player := range session.players() {
+		player.InGame = false
+	}
+	updateClientProfiles(session)
+	s.endSpectating(session, "The game is over.")
+	if !session.isTeamMatch() {
+		s.offerRematch(session)
+	}
+	for _, player := range session.players() {
+		s.notifyPresence(player)
+	}
+	if s.GameSessions[session.ID] == session {
+		delete(s.GameSessions, session.ID)
+		log.Printf("Cleaned up game session: %s", session.ID)
 	}
 	s.mutex.Unlock()
 }
 
-// handlePlayerDisconnect handles a player disconnecting from a game
+// handlePlayerDisconnect handles a player disconnecting from a game.
+// The caller must hold s.mutex.
 func (s *GameServer) handlePlayerDisconnect(client *Client) {
 	session := s.getSessionForPlayer(client)
 	if session == nil {
@@ -790,48 +969,52 @@ func (s *GameServer) handlePlayerDisconnect(client *Client) {
 
 	log.Printf("Player %s disconnected from game session.", client.Username)
 
-	// Save the disconnecting player's data
-	var disconnectedPlayerGameObj *game.Player
-	if client.Username == session.GameEngine.GameState.PlayerA.Username {
-		disconnectedPlayerGameObj = session.GameEngine.GameState.PlayerA
-	} else if client.Username == session.GameEngine.GameState.PlayerB.Username {
-		disconnectedPlayerGameObj = session.GameEngine.GameState.PlayerB
-	}
-
-	if disconnectedPlayerGameObj != nil {
-		if err := s.JSONHandler.SavePlayerData(storage.PlayerProfile{
-			Username: disconnectedPlayerGameObj.Username, Level: disconnectedPlayerGameObj.Level, CurrentEXP: disconnectedPlayerGameObj.CurrentEXP, RequiredEXPForNextLevel: disconnectedPlayerGameObj.RequiredEXPForNextLevel,
-		}); err != nil {
-			log.Printf("Error saving player data for disconnecting player %s: %v", disconnectedPlayerGameObj.Username, err)
-		} else {
-			log.Printf("Saved player data for disconnecting player %s (Level: %d, EXP: %d)", disconnectedPlayerGameObj.Username, disconnectedPlayerGameObj.Level, disconnectedPlayerGameObj.CurrentEXP)
-		}
-	} else {
-		log.Printf("Could not find game object for disconnecting player %s to save data.", client.Username)
+	// The game may end on its own before the disconnect is handled, then there is nothing left to do
+	session.post(func() {
+		s.resolveDisconnect(session, client)
+	})
+}
+
+// resolveDisconnect ends a match whose player disconnected. Runs on the session's event loop, which it stops.
+func (s *GameServer) resolveDisconnect(session *GameSession, client *Client) {
+	session.stop()
+
+	// Resolve the match as a forfeit: the remaining player (in 2v2, the other side) wins, ratings
+	// are updated and every player's data is saved by the game engine
+	forfeitMessage := session.GameEngine.HandleForfeit(client.Username)
+	log.Printf("Resolved disconnect of %s: %s", client.Username, forfeitMessage)
+
+	gameOverPayload := models.GameOverNotificationPayload{
+		WinnerUsername: session.GameEngine.GameState.Winner,
+		Reason:         fmt.Sprintf("%s disconnected", client.Username),
+		WinningTeam:    winningTeam(session.GameEngine.GameState),
+		CardRewards:    session.GameEngine.GameState.CardRewards,
 	}
 
-	// Determine the other player
-	var otherPlayer *Client
-	if client.Username == session.PlayerA.Username {
-		otherPlayer = session.PlayerB
-	} else {
-		otherPlayer = session.PlayerA
+	gameOverMsg := models.GenericMessage{
+		Type:    models.MsgTypeGameOverNotification,
+		Payload: gameOverPayload,
 	}
 
-	// Send game over notification to the other player
-	if otherPlayer != nil && otherPlayer.InGame {
-		gameOverPayload := models.GameOverNotificationPayload{
-			WinnerUsername: otherPlayer.Username,
-			Reason:         fmt.Sprintf("%s disconnected", client.Username),
+	// Send game over notification to the other players and the spectators
+	for _, player := range session.players() {
+		if player != client {
+			player.Send(gameOverMsg)
 		}
+	}
+	s.broadcastToSpectators(session, gameOverMsg)
 
-		gameOverMsg := models.GenericMessage{
-			Type:    models.MsgTypeGameOverNotification,
-			Payload: gameOverPayload,
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, player := range session.players() {
+		player.InGame = false
+	}
+	s.endSpectating(session, "The game is over.")
+	updateClientProfiles(session)
+	for _, player := range session.players() {
+		if player != client {
+			s.notifyPresence(player)
 		}
-
-		WriteMessage(otherPlayer.Conn, gameOverMsg)
-		otherPlayer.InGame = false
 	}
 
 	// Clean up game session
@@ -844,7 +1027,7 @@ func (s *GameServer) handlePlayerDisconnect(client *Client) {
 }
 
 // sendError sends an error notification to the client
-func sendError(conn net.Conn, errorMessage string) {
+func sendError(client *Client, errorMessage string) {
 	errorPayload := models.ErrorNotificationPayload{
 		ErrorMessage: errorMessage,
 	}
@@ -852,7 +1035,7 @@ func sendError(conn net.Conn, errorMessage string) {
 		Type:    models.MsgTypeErrorNotification,
 		Payload: errorPayload,
 	}
-	err := WriteMessage(conn, errorMsg)
+	err := client.Send(errorMsg)
 	if err != nil {
 		log.Printf("Error sending error notification: %v", err)
 	}
@@ -0,0 +1,98 @@
+package network
+
+// sessionCommand is a unit of work applied to a game session by its event loop
+type sessionCommand func()
+
+// run is the event loop of a game session. Commands from both players, the turn and match
+// timers and the rest of the server are applied one at a time, so the game engine and the
+// spectators of the session are only ever touched from this goroutine.
+// It returns once the session has been stopped.
+func (session *GameSession) run() {
+	defer close(session.done)
+
+	for range session.wake {
+		for {
+			command, ok := session.nextCommand()
+			if !ok {
+				break
+			}
+			command()
+		}
+
+		session.commandsMutex.Lock()
+		stopped := session.stopped
+		session.commandsMutex.Unlock()
+		if stopped {
+			return
+		}
+	}
+}
+
+// post queues a command for the session's event loop without waiting for it to run.
+// It never blocks, so it may be called while holding s.mutex.
+// It returns false if the session has already been stopped.
+func (session *GameSession) post(command sessionCommand) bool {
+	session.commandsMutex.Lock()
+	if session.stopped {
+		session.commandsMutex.Unlock()
+		return false
+	}
+	session.commands = append(session.commands, command)
+	session.commandsMutex.Unlock()
+
+	select {
+	case session.wake <- struct{}{}:
+	default: // The loop has already been woken up
+	}
+	return true
+}
+
+// call runs a command on the session's event loop and waits for it to finish.
+// It returns false if the session was stopped before the command ran.
+// Must not be called while holding s.mutex or from the event loop itself.
+func (session *GameSession) call(command sessionCommand) bool {
+	finished := make(chan struct{})
+	if !session.post(func() {
+		command()
+		close(finished)
+	}) {
+		return false
+	}
+
+	select {
+	case <-finished:
+		return true
+	case <-session.done:
+		return false
+	}
+}
+
+// nextCommand takes the oldest pending command off the queue
+func (session *GameSession) nextCommand() (sessionCommand, bool) {
+	session.commandsMutex.Lock()
+	defer session.commandsMutex.Unlock()
+
+	if session.stopped || len(session.commands) == 0 {
+		return nil, false
+	}
+	command := session.commands[0]
+	session.commands[0] = nil
+	session.commands = session.commands[1:]
+	return command, true
+}
+
+// stop ends the session's event loop once the current command returns.
+// Pending and later commands are dropped. Only called from the event loop.
+func (session *GameSession) stop() {
+	session.commandsMutex.Lock()
+	session.stopped = true
+	session.commands = nil
+	session.commandsMutex.Unlock()
+
+	if session.turnTimer != nil {
+		session.turnTimer.Stop()
+	}
+	if session.phaseTimer != nil {
+		session.phaseTimer.Stop()
+	}
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"errors"
+	"log"
+	"tcr/internal/models"
+	"time"
+)
+
+// ErrServerClosed is returned by Start once the server has been stopped, and by Shutdown if it already was
+var ErrServerClosed = errors.New("server closed")
+
+// Shutdown stops the server gracefully. New connections are refused, matches in progress
+// end as no-contest (ratings unchanged, profiles saved) and every client is told the reason
+// before its connection is closed. It waits at most drainTimeout for matches to wrap up
+// and queued messages to be written; whatever is left after that is closed anyway.
+func (s *GameServer) Shutdown(reason string, drainTimeout time.Duration) error {
+	deadline := time.Now().Add(drainTimeout)
+
+	s.mutex.Lock()
+	if s.shuttingDown {
+		s.mutex.Unlock()
+		return ErrServerClosed
+	}
+	err := s.stopAccepting()
+
+	sessions := make([]*GameSession, 0, len(s.GameSessions))
+	for _, session := range s.GameSessions {
+		sessions = append(sessions, session)
+		session.post(func() {
+			s.resolveNoContest(session, reason)
+		})
+	}
+	s.mutex.Unlock()
+
+	log.Printf("Shutting down: ending %d matches in progress", len(sessions))
+	for _, session := range sessions {
+		if !waitUntil(session.done, deadline) {
+			log.Printf("Timed out waiting for game session %s to end", session.ID)
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	clients := make([]*Client, 0, len(s.connections))
+	for client := range s.connections {
+		clients = append(clients, client)
+	}
+	s.mutex.Unlock()
+
+	// Tell everyone why, then hang up once their queued messages are written
+	shutdownMsg := models.GenericMessage{
+		Type: models.MsgTypeServerShutdown,
+		Payload: models.ServerShutdownPayload{
+			Reason: reason,
+		},
+	}
+	for _, client := range clients {
+		client.Send(shutdownMsg)
+		client.closeAfterFlush()
+	}
+
+	for _, client := range clients {
+		if !waitUntil(client.done, deadline) {
+			log.Printf("Timed out flushing messages to %s, closing it", client.Conn.RemoteAddr())
+			client.Close()
+		}
+	}
+
+	log.Printf("Server shut down")
+	return err
+}
+
+// stopAccepting closes the listener so no new clients can connect.
+// The caller must hold s.mutex.
+func (s *GameServer) stopAccepting() error {
+	if s.shuttingDown {
+		return nil
+	}
+	s.shuttingDown = true
+	close(s.quit)
+
+	if s.Listener != nil {
+		return s.Listener.Close()
+	}
+	return nil
+}
+
+// resolveNoContest ends a match without a result because the server is going down.
+// Runs on the session's event loop, which it stops.
+func (s *GameServer) resolveNoContest(session *GameSession, reason string) {
+	session.stop()
+
+	resultMessage := session.GameEngine.HandleNoContest(reason)
+	log.Printf("Ended game session %s: %s (specs v%d)", session.ID, resultMessage, session.Specs.Version)
+
+	gameOverMsg := models.GenericMessage{
+		Type: models.MsgTypeGameOverNotification,
+		Payload: models.GameOverNotificationPayload{
+			WinnerUsername: "", // No contest
+			Reason:         reason,
+		},
+	}
+
+	for _, player := range session.players() {
+		player.Send(gameOverMsg)
+	}
+	s.broadcastToSpectators(session, gameOverMsg)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, player := range session.players() {
+		player.InGame = false
+	}
+	updateClientProfiles(session)
+	s.endSpectating(session, "The game was stopped.")
+	delete(s.GameSessions, session.ID)
+	for _, player := range session.players() {
+		s.notifyPresence(player)
+	}
+}
+
+// waitUntil waits for a channel to be closed, giving up at the deadline
+func waitUntil(ch <-chan struct{}, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-ch:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
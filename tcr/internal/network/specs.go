@@ -0,0 +1,81 @@
+package network
+
+import (
+	"log"
+	"os"
+	"tcr/internal/models"
+	"time"
+)
+
+// ReloadSpecs loads the troop and tower specs from the config directory and, if they are
+// valid and have changed, makes them the specs new matches are played with. Matches in
+// progress keep the specs they started with. On error the current specs stay in use.
+func (s *GameServer) ReloadSpecs() (*models.SpecSet, error) {
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+
+	s.mutex.Lock()
+	current := s.Specs
+	s.mutex.Unlock()
+
+	version := 1
+	if current != nil {
+		version = current.Version + 1
+	}
+
+	specs, err := s.JSONHandler.LoadSpecSet(version)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && specs.Checksum == current.Checksum {
+		return current, nil // Nothing changed
+	}
+
+	s.mutex.Lock()
+	s.Specs = specs
+	s.mutex.Unlock()
+
+	log.Printf("Loaded specs v%d (%s): %d troop specs and %d tower specs", specs.Version, specs.Checksum, len(specs.Troops), len(specs.Towers))
+	return specs, nil
+}
+
+// watchSpecs reloads the specs whenever a spec file changes, until the server stops
+// accepting connections. Files are checked every interval.
+func (s *GameServer) watchSpecs(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastModified := s.specFilesModified()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		}
+
+		modified := s.specFilesModified()
+		if modified.Equal(lastModified) {
+			continue
+		}
+		lastModified = modified
+
+		if _, err := s.ReloadSpecs(); err != nil {
+			log.Printf("Spec files changed but could not be loaded, keeping the current specs: %v", err)
+		}
+	}
+}
+
+// specFilesModified returns the latest modification time of the spec files
+func (s *GameServer) specFilesModified() time.Time {
+	var latest time.Time
+	for _, path := range s.JSONHandler.SpecFiles() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
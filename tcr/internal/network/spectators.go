@@ -0,0 +1,298 @@
+package network
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
+)
+
+// Spectator represents a client watching a live match
+type Spectator struct {
+	Client  *Client
+	Delay   time.Duration        // Everything the spectator receives is held back by this long
+	updates chan spectatorUpdate // Pending messages, written out in order by run
+	lagging bool                 // The spectator fell too far behind and is being disconnected
+}
+
+// spectatorUpdate is a message waiting to be delivered to a spectator
+type spectatorUpdate struct {
+	message models.GenericMessage
+	sendAt  time.Time
+}
+
+// newSpectator creates a spectator and starts delivering its updates
+func newSpectator(client *Client, delay time.Duration) *Spectator {
+	spectator := &Spectator{
+		Client:  client,
+		Delay:   delay,
+		updates: make(chan spectatorUpdate, shared.SpectatorBufferSize),
+	}
+	go spectator.run()
+	return spectator
+}
+
+// run writes queued updates to the spectator once their delay has passed.
+// It returns when the updates channel is closed.
+func (sp *Spectator) run() {
+	for update := range sp.updates {
+		if wait := time.Until(update.sendAt); wait > 0 {
+			time.Sleep(wait)
+		}
+		sp.Client.Send(update.message)
+	}
+}
+
+// send queues a message for the spectator. Only called from the session's event loop.
+// Updates are never dropped: a spectator whose queue is full is too slow to keep up and is
+// disconnected, like a slow client.
+func (sp *Spectator) send(message models.GenericMessage) {
+	if sp.lagging {
+		return
+	}
+	select {
+	case sp.updates <- spectatorUpdate{message: message, sendAt: time.Now().Add(sp.Delay)}:
+	default:
+		log.Printf("Spectator queue of %s is full, disconnecting slow spectator", sp.Client.Username)
+		sp.lagging = true
+		sp.Client.Close()
+	}
+}
+
+// broadcastToSpectators queues a message for everyone watching a session
+func (s *GameServer) broadcastToSpectators(session *GameSession, message models.GenericMessage) {
+	for _, spectator := range session.Spectators {
+		spectator.send(message)
+	}
+}
+
+// sendSpectatorCount tells every player how many spectators are watching their match
+func (s *GameServer) sendSpectatorCount(session *GameSession) {
+	countMsg := models.GenericMessage{
+		Type: models.MsgTypeSpectatorCount,
+		Payload: models.SpectatorCountPayload{
+			Count: len(session.Spectators),
+		},
+	}
+
+	for _, player := range session.players() {
+		player.Send(countMsg)
+	}
+}
+
+// handleListGames handles a request for the list of live matches
+func (s *GameServer) handleListGames(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before listing games")
+		return
+	}
+
+	s.mutex.Lock()
+	sessions := make([]*GameSession, 0, len(s.GameSessions))
+	for _, session := range s.GameSessions {
+		sessions = append(sessions, session)
+	}
+	s.mutex.Unlock()
+
+	// Each session reports on its own event loop; games that end meanwhile are left out
+	games := make([]models.GameSummary, 0, len(sessions))
+	for _, session := range sessions {
+		var summary models.GameSummary
+		ok := session.call(func() {
+			summary = models.GameSummary{
+				SessionID:      session.ID,
+				PlayerA:        session.PlayerA.Username,
+				PlayerB:        session.PlayerB.Username,
+				PartnerA:       partnerName(session.PartnerA),
+				PartnerB:       partnerName(session.PartnerB),
+				Settings:       session.Settings,
+				TurnNumber:     session.GameEngine.GameState.TurnNumber,
+				SpectatorCount: len(session.Spectators),
+				SpecVersion:    session.Specs.Version,
+			}
+		})
+		if ok {
+			games = append(games, summary)
+		}
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].SessionID < games[j].SessionID
+	})
+
+	listMsg := models.GenericMessage{
+		Type: models.MsgTypeGameList,
+		Payload: models.GameListPayload{
+			Games: games,
+		},
+	}
+	client.Send(listMsg)
+}
+
+// handleSpectateGame handles a request to watch a live match
+func (s *GameServer) handleSpectateGame(client *Client, payload interface{}) {
+	if client.Username == "" {
+		sendError(client, "You must log in before spectating")
+		return
+	}
+
+	spectatePayload, ok := payload.(map[string]interface{})
+	if !ok {
+		sendError(client, "Invalid spectate payload")
+		return
+	}
+
+	sessionID, ok := spectatePayload["sessionId"].(string)
+	if !ok || sessionID == "" {
+		sendError(client, "Invalid session ID")
+		return
+	}
+
+	delaySeconds := 0
+	if delay, ok := spectatePayload["delaySeconds"].(float64); ok {
+		delaySeconds = int(delay)
+	}
+	if delaySeconds < 0 || delaySeconds > shared.MaxSpectatorDelaySeconds {
+		sendError(client, fmt.Sprintf("Spectator delay must be between 0 and %d seconds", shared.MaxSpectatorDelaySeconds))
+		return
+	}
+
+	s.mutex.Lock()
+	if client.InGame {
+		s.mutex.Unlock()
+		sendError(client, "You cannot spectate while playing")
+		return
+	}
+	session, exists := s.GameSessions[sessionID]
+	if !exists {
+		s.mutex.Unlock()
+		sendError(client, fmt.Sprintf("No live game with ID %s", sessionID))
+		return
+	}
+	if session.hasPlayer(client) {
+		s.mutex.Unlock()
+		sendError(client, "You cannot spectate your own game")
+		return
+	}
+	if client.Spectating != "" {
+		s.stopSpectating(client)
+	}
+
+	startMsg := models.GenericMessage{
+		Type: models.MsgTypeSpectateStart,
+		Payload: models.SpectateStartPayload{
+			SessionID:    sessionID,
+			PlayerA:      session.PlayerA.Username,
+			PlayerB:      session.PlayerB.Username,
+			PartnerA:     partnerName(session.PartnerA),
+			PartnerB:     partnerName(session.PartnerB),
+			Settings:     session.Settings,
+			DelaySeconds: delaySeconds,
+		},
+	}
+	client.Send(startMsg)
+
+	// Join on the session's event loop; posting under s.mutex keeps the join ahead of
+	// any stopSpectating for this client
+	joined := session.post(func() {
+		spectator := newSpectator(client, time.Duration(delaySeconds)*time.Second)
+		session.Spectators[client.Username] = spectator
+		spectator.send(s.gameStateMessage(session, Viewer{Role: ViewerSpectator}, ""))
+		s.sendSpectatorCount(session)
+	})
+	if !joined {
+		// The game ended while the request was being handled
+		s.mutex.Unlock()
+		sendSpectateEnd(client, sessionID, "The game is over.")
+		return
+	}
+	client.Spectating = sessionID
+	s.mutex.Unlock()
+
+	log.Printf("%s is spectating %s with a %ds delay", client.Username, sessionID, delaySeconds)
+	s.notifyPresence(client)
+}
+
+// handleStopSpectating handles a request to stop watching a match
+func (s *GameServer) handleStopSpectating(client *Client, payload interface{}) {
+	s.mutex.Lock()
+	sessionID := client.Spectating
+	if sessionID == "" {
+		s.mutex.Unlock()
+		sendError(client, "You are not spectating a game")
+		return
+	}
+	s.stopSpectating(client)
+	s.mutex.Unlock()
+
+	sendSpectateEnd(client, sessionID, "You stopped spectating.")
+	s.notifyPresence(client)
+}
+
+// stopSpectating removes a client from the audience of the match it watches.
+// The caller must hold s.mutex.
+func (s *GameServer) stopSpectating(client *Client) {
+	sessionID := client.Spectating
+	client.Spectating = ""
+
+	session, exists := s.GameSessions[sessionID]
+	if !exists {
+		return
+	}
+
+	session.post(func() {
+		if spectator, ok := session.Spectators[client.Username]; ok && spectator.Client == client {
+			delete(session.Spectators, client.Username)
+			close(spectator.updates)
+			s.sendSpectatorCount(session)
+		}
+	})
+	log.Printf("%s stopped spectating %s", client.Username, sessionID)
+}
+
+// endSpectating releases every spectator of a finished match after their pending updates are delivered,
+// along with clients whose join had not run yet.
+// Runs on the session's event loop; the caller must hold s.mutex.
+func (s *GameServer) endSpectating(session *GameSession, message string) {
+	endMsg := models.GenericMessage{
+		Type: models.MsgTypeSpectateEnd,
+		Payload: models.SpectateEndPayload{
+			SessionID: session.ID,
+			Message:   message,
+		},
+	}
+
+	for username, spectator := range session.Spectators {
+		spectator.send(endMsg)
+		close(spectator.updates)
+		delete(session.Spectators, username)
+
+		if spectator.Client.Spectating == session.ID {
+			spectator.Client.Spectating = ""
+			s.notifyPresence(spectator.Client)
+		}
+	}
+
+	// A client whose join was still queued when the match ended never became a spectator
+	for client := range s.connections {
+		if client.Spectating == session.ID {
+			client.Spectating = ""
+			client.Send(endMsg)
+			s.notifyPresence(client)
+		}
+	}
+}
+
+// sendSpectateEnd tells a client it is no longer spectating
+func sendSpectateEnd(client *Client, sessionID, message string) {
+	endMsg := models.GenericMessage{
+		Type: models.MsgTypeSpectateEnd,
+		Payload: models.SpectateEndPayload{
+			SessionID: sessionID,
+			Message:   message,
+		},
+	}
+	client.Send(endMsg)
+}
@@ -0,0 +1,41 @@
+package network
+
+import (
+	"tcr/internal/config"
+	"tcr/internal/models"
+	"testing"
+)
+
+func TestEndSpectatingQueuedJoin(t *testing.T) {
+	server := NewServer(config.Default(), nil)
+	session := &GameSession{ID: "s1", Spectators: make(map[string]*Spectator)}
+
+	// carol's join was posted but never ran; dave watches another match
+	carol := &Client{Username: "carol", Spectating: "s1", outbound: make(chan models.GenericMessage, 1), done: make(chan struct{})}
+	dave := &Client{Username: "dave", Spectating: "s2", outbound: make(chan models.GenericMessage, 1), done: make(chan struct{})}
+	server.connections[carol] = true
+	server.connections[dave] = true
+
+	server.mutex.Lock()
+	server.endSpectating(session, "The game is over.")
+	server.mutex.Unlock()
+
+	if carol.Spectating != "" {
+		t.Errorf("carol is still spectating %q", carol.Spectating)
+	}
+	select {
+	case msg := <-carol.outbound:
+		if msg.Type != models.MsgTypeSpectateEnd {
+			t.Errorf("carol received %s, want %s", msg.Type, models.MsgTypeSpectateEnd)
+		}
+	default:
+		t.Errorf("carol was not told the match ended")
+	}
+
+	if dave.Spectating != "s2" {
+		t.Errorf("dave is spectating %q, want s2", dave.Spectating)
+	}
+	if len(dave.outbound) != 0 {
+		t.Errorf("dave received %d messages, want none", len(dave.outbound))
+	}
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"fmt"
+	"tcr/internal/game"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+)
+
+// players returns every player of a session in turn order: A, B in 1v1 and A, B, partner A,
+// partner B in 2v2
+func (session *GameSession) players() []*Client {
+	if session.PartnerA == nil {
+		return []*Client{session.PlayerA, session.PlayerB}
+	}
+	return []*Client{session.PlayerA, session.PlayerB, session.PartnerA, session.PartnerB}
+}
+
+// isTeamMatch checks if a session is a 2v2 match
+func (session *GameSession) isTeamMatch() bool {
+	return session.PartnerA != nil
+}
+
+// hasPlayer checks if a client plays in a session
+func (session *GameSession) hasPlayer(client *Client) bool {
+	for _, player := range session.players() {
+		if player.Username == client.Username {
+			return true
+		}
+	}
+	return false
+}
+
+// playerClient returns the client of the player with the given username, or nil if they do not play in the session
+func (session *GameSession) playerClient(username string) *Client {
+	for _, player := range session.players() {
+		if player.Username == username {
+			return player
+		}
+	}
+	return nil
+}
+
+// teammateOf returns the teammate of a player in a 2v2 match, or nil in 1v1
+func (session *GameSession) teammateOf(client *Client) *Client {
+	switch client {
+	case session.PlayerA:
+		return session.PartnerA
+	case session.PartnerA:
+		return session.PlayerA
+	case session.PlayerB:
+		return session.PartnerB
+	case session.PartnerB:
+		return session.PlayerB
+	}
+	return nil
+}
+
+// opponentsOf returns the players on the other side of a player's match
+func (session *GameSession) opponentsOf(client *Client) []*Client {
+	opponents := make([]*Client, 0, shared.TeamMatchSize)
+	for _, player := range session.players() {
+		if player != client && player != session.teammateOf(client) {
+			opponents = append(opponents, player)
+		}
+	}
+	return opponents
+}
+
+// partnerName returns the username of a partner of a 2v2 match, or "" for the missing partner of a 1v1 match
+func partnerName(partner *Client) string {
+	if partner == nil {
+		return ""
+	}
+	return partner.Username
+}
+
+// viewerFor returns the viewer of the game state for one of the players of a session.
+// In 2v2, teammates see each other's hand and mana.
+func (session *GameSession) viewerFor(client *Client) Viewer {
+	viewer := PlayerViewer(client.Username)
+	if teammate := session.teammateOf(client); teammate != nil {
+		viewer.Teammate = teammate.Username
+	}
+	return viewer
+}
+
+// winningTeam lists both winners of a finished 2v2 match, or returns nil for 1v1 matches and draws
+func winningTeam(gameState *game.GameState) []string {
+	if !gameState.IsTeamMatch() {
+		return nil
+	}
+	side := gameState.Side(gameState.Winner)
+	if side == nil {
+		return nil
+	}
+	return usernames(gameState.Team(side))
+}
+
+// usernames returns the usernames of game players
+func usernames(players []*game.Player) []string {
+	names := make([]string, len(players))
+	for i, player := range players {
+		names[i] = player.Username
+	}
+	return names
+}
+
+// createTeamGameSession creates a new 2v2 game session. The first player of each team owns the
+// team's towers and takes the team's first turn. The caller must hold s.mutex.
+func (s *GameServer) createTeamGameSession(teamA, teamB [2]*Client, settings models.MatchSettings) {
+	settings.TeamSize = shared.TeamMatchSize
+	s.startGameSession(teamA[:], teamB[:], settings)
+}
+
+// sessionIDFor returns the ID of a session between two sides, e.g. "alice_vs_bob" or "alice+carol_vs_bob+dave"
+func sessionIDFor(teamA, teamB []*Client) string {
+	return fmt.Sprintf("%s_vs_%s", teamLabel(teamA), teamLabel(teamB))
+}
+
+// teamLabel joins the usernames of a team with "+"
+func teamLabel(team []*Client) string {
+	label := team[0].Username
+	for _, player := range team[1:] {
+		label += "+" + player.Username
+	}
+	return label
+}
@@ -1,6 +1,6 @@
 package shared
 
-// Game constants
+// Game constants. Rules, timeouts and matchmaking values are the defaults of the server config (see internal/config).
 const (
 	// Base requirements
 	BaseEXPForLevelUp    = 100
@@ -12,6 +12,16 @@ const (
 	GameDurationSeconds = 180 // 3 minutes
 	MaxMana             = 20  // Maximum mana a player can hold
 
+	// Overtime constants
+	OvertimeManaRegenMultiplier = 2.0 // Mana regenerates twice as fast during overtime
+
+	// Handicap constants, per level the lower-level player is behind
+	HandicapManaPerLevel           = 2  // Bonus starting mana
+	HandicapTowerHPPercentPerLevel = 10 // Bonus tower HP in percent, offsetting the 10% per level stat scaling
+
+	// Team match constants
+	TeamMatchSize = 2 // Players per side in a team (2v2) match
+
 	// Combat constants
 	CritDamageMultiplier   = 1.2 // 20% bonus damage on critical hit
 	DefaultTroopCritChance = 20  // 20% chance for troops in Enhanced mode
@@ -22,16 +32,211 @@ const (
 
 	// Special ability constants
 	QueenHealAmount = 300
+
+	// Card collection constants
+	MaxCardLevel       = 10 // Highest level a card can be upgraded to
+	CardCopiesPerLevel = 2  // Copies needed to upgrade a card, per level it has (2 for level 1 -> 2)
+	WinCardCopies      = 3  // Card copies each winner receives
+	LossCardCopies     = 1  // Card copies each loser (or each player on a draw) receives
+	MinDeckSize        = 4  // Fewest cards a deck may have
+	MaxDeckSize        = 8  // Most cards a deck may have
+
+	// Skill rating constants (Elo)
+	DefaultRating = 1200 // Rating assigned to new players
+	RatingKFactor = 32   // Maximum rating change for a single match
+
+	// Matchmaking constants
+	MatchmakingBaseRatingGap      = 100  // Rating gap accepted as soon as a player joins the queue
+	MatchmakingGapWidenPerSecond  = 10   // Extra rating gap accepted for every second spent waiting
+	MatchmakingMaxRatingGap       = 1000 // Upper bound for the accepted rating gap
+	MatchmakingTickSeconds        = 1    // How often the queue is re-evaluated
+	MatchmakingStatusEverySeconds = 5    // How often waiting players receive a queue status update
+	MatchmakingDefaultWaitSeconds = 30   // Wait estimate used before any match has been made
+	MatchmakingWaitSampleSize     = 10   // Number of recent waits used for the wait estimate
+)
+
+// Private room and challenge constants
+const (
+	RoomCodeLength          = 6   // Length of shareable room codes
+	ChallengeTimeoutSeconds = 60  // How long a direct challenge stays open
+	MinTurnTimerSeconds     = 10  // Shortest allowed turn timer (0 disables the timer)
+	MaxTurnTimerSeconds     = 300 // Longest allowed turn timer
+	RematchTimeoutSeconds   = 60  // How long players can ask for a rematch after a game
+)
+
+// Connection constants
+const (
+	ClientSendBufferSize        = 128 // Messages queued per client before it is disconnected as too slow
+	ClientWriteTimeoutSeconds   = 10  // Longest a single write to a client may take
+	ShutdownDrainTimeoutSeconds = 10  // Longest a graceful shutdown waits for matches and messages to be flushed
+)
+
+// Spec reload constants
+const (
+	SpecWatchIntervalSeconds = 5 // How often the config directory is checked for changed troop and tower specs
+)
+
+// Lobby constants
+const (
+	PresenceUpdateBufferSize = 256 // Pending presence updates before new ones are dropped
+
+	// Spectators
+	MaxSpectatorDelaySeconds = 120 // Longest delay a spectator may ask for
+	SpectatorBufferSize      = 256 // Updates buffered per spectator before a lagging one is disconnected
+)
+
+// Chat constants
+const (
+	MaxChatMessageLength       = 200 // Longest chat message in characters
+	ChatRateLimitMessages      = 5   // Messages a player may send per rate limit window
+	ChatRateLimitWindowSeconds = 10  // Length of the chat rate limit window
+
+	ChatScopeLobby = "LOBBY" // Everyone online who is not in a match
+	ChatScopeRoom  = "ROOM"  // Members of the sender's private room
+	ChatScopeMatch = "MATCH" // Every player of the sender's match
+	ChatScopeTeam  = "TEAM"  // The sender and their teammate in a 2v2 match
+)
+
+// Emotes maps each quick emote usable during a match to the text shown to players
+var Emotes = map[string]string{
+	"HELLO":    "👋 Hello!",
+	"GG":       "🤝 Good game!",
+	"THANKS":   "🙏 Thanks!",
+	"WOW":      "😮 Wow!",
+	"OOPS":     "😅 Oops!",
+	"THINKING": "🤔 Hmm...",
+	"ANGRY":    "😠 Grr!",
+}
+
+// Game modes
+const (
+	GameModeSimple   = "SIMPLE"
+	GameModeEnhanced = "ENHANCED"
+	GameModeAny      = "ANY" // Queue preference accepting every mode
+)
+
+// SupportedGameModes lists the modes players can queue for, in default preference order
+var SupportedGameModes = []string{GameModeSimple, GameModeEnhanced}
+
+// Phases of a match played against the clock
+const (
+	PhaseRegular  = "REGULAR"  // Regular time: destroying the King tower wins
+	PhaseOvertime = "OVERTIME" // Sudden death after a tie on time: the first tower destroyed wins
+)
+
+// Handicaps give the lower-level player of a match a bonus for each level they are behind
+const (
+	HandicapMana    = "MANA"     // Bonus starting mana
+	HandicapTowerHP = "TOWER_HP" // Bonus HP for every tower
+)
+
+// Handicaps lists the handicaps a match may use
+var Handicaps = []string{HandicapMana, HandicapTowerHP}
+
+// Tower roles
+const (
+	KingTowerRole  = "KING"  // Destroying this tower wins the match
+	GuardTowerRole = "GUARD" // Protects other towers
+)
+
+// TowerRoles lists the roles a tower spec may have
+var TowerRoles = []string{KingTowerRole, GuardTowerRole}
+
+// Preferred targets decide what a troop attacks when the player names no target
+const (
+	TargetTowers   = "TOWERS"    // The next open tower, guard towers before the King
+	TargetTroops   = "TROOPS"    // Enemy buildings on the field first, then the next open tower
+	TargetLowestHP = "LOWEST_HP" // The open tower with the least HP left
 )
 
-// Tower types
+// PreferredTargets lists the preferred targets a troop spec may have
+var PreferredTargets = []string{TargetTowers, TargetTroops, TargetLowestHP}
+
+// Kinds of hits an attack can deal
 const (
-	KingTowerType   = "KING"
-	GuardTower1Type = "GUARD1"
-	GuardTower2Type = "GUARD2"
+	HitPrimary  = "PRIMARY"  // The troop's target
+	HitSplash   = "SPLASH"   // A share of the damage on a tower next to the target
+	HitChain    = "CHAIN"    // A jump to a further tower, with falloff
+	HitBuilding = "BUILDING" // An attack on a building, or intercepted by one
 )
 
-// Special ability identifiers
+// Targeting rules decide which towers can be attacked
+const (
+	TargetingClassic = "CLASSIC" // A tower can be attacked once every tower in its Requires is destroyed
+	TargetingLanes   = "LANES"   // Guard towers can be attacked in any order; the King once any guard is destroyed
+)
+
+// TargetingRules lists the targeting rules a game mode may use
+var TargetingRules = []string{TargetingClassic, TargetingLanes}
+
+// Damage formulas decide how much damage an attack deals
 const (
-	HealLowestHPTowerAbility = "HEAL_LOWEST_HP_TOWER_300"
+	DamageCritical = "CRITICAL" // DMG = ATK - DEF, where ATK is sometimes multiplied by the critical hit multiplier
+	DamageFlat     = "FLAT"     // DMG = ATK - DEF
 )
+
+// DamageFormulas lists the damage formulas a game mode may use
+var DamageFormulas = []string{DamageCritical, DamageFlat}
+
+// Mana economies decide what deploying a troop costs
+const (
+	ManaPool = "POOL" // Troops cost mana, which regenerates every turn (1.5x when skipping)
+	ManaFree = "FREE" // Troops cost nothing; mana is not used
+)
+
+// ManaEconomies lists the mana economies a game mode may use
+var ManaEconomies = []string{ManaPool, ManaFree}
+
+// Turn structures decide when the turn passes to the other player
+const (
+	TurnsBonusAttack = "BONUS_ATTACK" // Destroying a tower grants another attack in the same turn
+	TurnsAlternate   = "ALTERNATE"    // The turn always passes after a deploy
+)
+
+// TurnStructures lists the turn structures a game mode may use
+var TurnStructures = []string{TurnsBonusAttack, TurnsAlternate}
+
+// Win conditions decide when destroying a tower wins the match
+const (
+	WinKingTower = "KING"       // Destroying the King tower wins
+	WinAllTowers = "ALL_TOWERS" // Destroying every tower of the opponent wins
+)
+
+// WinConditions lists the win conditions a game mode may use
+var WinConditions = []string{WinKingTower, WinAllTowers}
+
+// EXP awards decide what players earn EXP for
+const (
+	EXPMatchAndTowers = "MATCH_AND_TOWERS" // Match results and destroyed towers and buildings (their DestroyEXP) award EXP
+	EXPMatchOnly      = "MATCH_ONLY"       // Only match results award EXP
+)
+
+// EXPAwards lists the EXP awards a game mode may use
+var EXPAwards = []string{EXPMatchAndTowers, EXPMatchOnly}
+
+// Special ability identifiers. Spells act through their ability.
+const (
+	HealLowestHPTowerAbility = "HEAL_LOWEST_HP_TOWER_300" // Heals the caster's weakest tower
+	FireballAbility          = "FIREBALL"                 // Damages an enemy tower, ignoring its defense
+	FreezeAbility            = "FREEZE"                   // An enemy building skips its next attacks; towers cannot be frozen
+	RageAbility              = "RAGE"                     // The caster's next troop attacks with bonus ATK
+)
+
+// SpecialAbilities lists the special abilities a troop spec may have
+var SpecialAbilities = []string{HealLowestHPTowerAbility, FireballAbility, FreezeAbility, RageAbility}
+
+// Card types
+const (
+	CardTroop    = "TROOP"    // Attacks an enemy tower and is consumed
+	CardSpell    = "SPELL"    // Acts through its special ability without a troop body
+	CardBuilding = "BUILDING" // Stands on its owner's side for a number of turns
+)
+
+// CardTypes lists the card types a troop spec may have
+var CardTypes = []string{CardTroop, CardSpell, CardBuilding}
+
+// MaxBuildings is the most buildings a side may have standing at once
+const MaxBuildings = 2
+
+// MaxFreezeTurns is the most attacks a FREEZE spell may make a building skip
+const MaxFreezeTurns = 2
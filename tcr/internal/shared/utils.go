@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"math"
 	"math/rand"
 	"time"
 )
@@ -34,3 +35,16 @@ func CalculateRequiredEXP(currentLevel int) int {
 	}
 	return exp
 }
+
+// CardUpgradeCopies returns the card copies needed to upgrade a card from the given level
+func CardUpgradeCopies(cardLevel int) int {
+	return cardLevel * CardCopiesPerLevel
+}
+
+// CalculateEloRatings returns the new ratings of two players after a match.
+// scoreA is the result from player A's point of view: 1 for a win, 0.5 for a draw, 0 for a loss.
+func CalculateEloRatings(ratingA, ratingB int, scoreA float64) (int, int) {
+	expectedA := 1.0 / (1.0 + math.Pow(10, float64(ratingB-ratingA)/400.0))
+	delta := int(math.Round(RatingKFactor * (scoreA - expectedA)))
+	return ratingA + delta, ratingB - delta
+}
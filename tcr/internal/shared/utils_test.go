@@ -0,0 +1,31 @@
+package shared
+
+import "testing"
+
+func TestCalculateEloRatings(t *testing.T) {
+	tests := []struct {
+		name             string
+		ratingA, ratingB int
+		scoreA           float64
+		wantA, wantB     int
+	}{
+		{"equal ratings, A wins", 1200, 1200, 1, 1216, 1184},
+		{"equal ratings, A loses", 1200, 1200, 0, 1184, 1216},
+		{"equal ratings, draw", 1200, 1200, 0.5, 1200, 1200},
+		{"underdog wins", 1000, 1400, 1, 1029, 1371},
+		{"favourite wins", 1400, 1000, 1, 1403, 997},
+		{"draw moves ratings together", 1000, 1400, 0.5, 1013, 1387},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotA, gotB := CalculateEloRatings(tt.ratingA, tt.ratingB, tt.scoreA)
+			if gotA != tt.wantA || gotB != tt.wantB {
+				t.Errorf("CalculateEloRatings(%d, %d, %v) = %d, %d; want %d, %d",
+					tt.ratingA, tt.ratingB, tt.scoreA, gotA, gotB, tt.wantA, tt.wantB)
+			}
+			if gotA+gotB != tt.ratingA+tt.ratingB {
+				t.Errorf("ratings are not zero-sum: %d + %d != %d + %d", gotA, gotB, tt.ratingA, tt.ratingB)
+			}
+		})
+	}
+}
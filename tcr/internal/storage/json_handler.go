@@ -1,13 +1,18 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sync"
 	"tcr/internal/models"
+	"tcr/internal/shared"
+	"time"
 )
 
 // JSONHandler handles JSON file operations
@@ -29,6 +34,17 @@ type PlayerProfile struct {
 	Level                   int    `json:"level"`
 	CurrentEXP              int    `json:"currentEXP"`
 	RequiredEXPForNextLevel int    `json:"requiredEXPForNextLevel"`
+	Rating                  int    `json:"rating"` // Skill rating (Elo) used for matchmaking
+
+	// Card collection: progress per card name, and the cards the player plays with
+	Cards map[string]CardProgress `json:"cards,omitempty"` // Unlocked cards without an entry are at level 1
+	Deck  []string                `json:"deck,omitempty"`  // Empty plays with every unlocked card
+}
+
+// CardProgress is a player's progress with one card of their collection
+type CardProgress struct {
+	Level  int `json:"level"`  // Card level; the card's stats scale with it
+	Copies int `json:"copies"` // Copies collected towards the next level
 }
 
 // NewJSONHandler creates a new JSON handler
@@ -39,38 +55,71 @@ func NewJSONHandler(configDir, dataDir string) *JSONHandler {
 	}
 }
 
+// Spec files in the config directory
+const (
+	TroopSpecsFile = "troops.json"
+	TowerSpecsFile = "towers.json"
+)
+
 // LoadTroopSpecs loads troop specifications from a JSON file
 func (h *JSONHandler) LoadTroopSpecs() ([]models.TroopSpec, error) {
-	filePath := filepath.Join(h.ConfigDir, "troops.json")
-	file, err := os.ReadFile(filePath)
+	file, err := os.ReadFile(filepath.Join(h.ConfigDir, TroopSpecsFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read troop specs file: %w", err)
 	}
+	return parseTroopSpecs(file)
+}
 
-	var troops []models.TroopSpec
-	err = json.Unmarshal(file, &troops)
+// LoadTowerSpecs loads tower specifications from a JSON file
+func (h *JSONHandler) LoadTowerSpecs() ([]models.TowerSpec, error) {
+	file, err := os.ReadFile(filepath.Join(h.ConfigDir, TowerSpecsFile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse troop specs JSON: %w", err)
+		return nil, fmt.Errorf("failed to read tower specs file: %w", err)
 	}
+	return parseTowerSpecs(file)
+}
 
-	return troops, nil
+// SpecFiles returns the paths of the spec files in the config directory
+func (h *JSONHandler) SpecFiles() []string {
+	return []string{
+		filepath.Join(h.ConfigDir, TroopSpecsFile),
+		filepath.Join(h.ConfigDir, TowerSpecsFile),
+	}
 }
 
-// LoadTowerSpecs loads tower specifications from a JSON file
-func (h *JSONHandler) LoadTowerSpecs() ([]models.TowerSpec, error) {
-	filePath := filepath.Join(h.ConfigDir, "towers.json")
-	file, err := os.ReadFile(filePath)
+// LoadSpecSet loads the troop and tower specs as a set with the given version.
+// The set is only returned if both files parse and pass ValidateSpecs.
+func (h *JSONHandler) LoadSpecSet(version int) (*models.SpecSet, error) {
+	troopFile, err := os.ReadFile(filepath.Join(h.ConfigDir, TroopSpecsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read troop specs file: %w", err)
+	}
+	towerFile, err := os.ReadFile(filepath.Join(h.ConfigDir, TowerSpecsFile))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tower specs file: %w", err)
 	}
 
-	var towers []models.TowerSpec
-	err = json.Unmarshal(file, &towers)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse tower specs JSON: %w", err)
+	// Report the problems of both files at once
+	troops, troopErr := parseTroopSpecs(troopFile)
+	towers, towerErr := parseTowerSpecs(towerFile)
+	if err := errors.Join(troopErr, towerErr); err != nil {
+		return nil, err
+	}
+	if err := ValidateSpecs(troops, towers); err != nil {
+		return nil, err
 	}
 
-	return towers, nil
+	checksum := sha256.New()
+	checksum.Write(troopFile)
+	checksum.Write(towerFile)
+
+	return &models.SpecSet{
+		Version:  version,
+		Checksum: hex.EncodeToString(checksum.Sum(nil))[:12],
+		LoadedAt: time.Now(),
+		Troops:   troops,
+		Towers:   towers,
+	}, nil
 }
 
 // SaveUserData saves user login data to a JSON file
@@ -168,28 +217,23 @@ func (h *JSONHandler) LoadPlayerData(username string) (PlayerProfile, error) {
 	playersDataDir := filepath.Join(h.DataDir, "players")
 	filePath := filepath.Join(playersDataDir, username+".json")
 
-	log.Printf("[LOADPLAYERDATA_DEBUG] Attempting to load player data for: Username='%s', FullPath='%s'", username, filePath)
-
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			log.Printf("[LOADPLAYERDATA_DEBUG] Player data file not found for '%s'. Returning default profile.", username)
 			// Return default profile for a new player
 			return PlayerProfile{
 				Username:                username,
 				Level:                   1,
 				CurrentEXP:              0,
 				RequiredEXPForNextLevel: 100, // Base EXP for level 1 to level up, as per plan
+				Rating:                  shared.DefaultRating,
 			}, nil
 		}
 		return PlayerProfile{}, fmt.Errorf("error reading player data file '%s': %w", filePath, err)
 	}
 
-	log.Printf("[LOADPLAYERDATA_DEBUG] Successfully read data for '%s', length: %d", filePath, len(data))
-
 	var profile PlayerProfile
 	if err := json.Unmarshal(data, &profile); err != nil {
-		log.Printf("[LOADPLAYERDATA_DEBUG] JSON unmarshal error for '%s': %v", filePath, err)
 		return PlayerProfile{}, fmt.Errorf("error unmarshaling player data from '%s': %w", filePath, err)
 	}
 
@@ -198,7 +242,10 @@ func (h *JSONHandler) LoadPlayerData(username string) (PlayerProfile, error) {
 		profile.Username = username
 	}
 
-	log.Printf("[LOADPLAYERDATA_DEBUG] Successfully unmarshaled data for '%s': EXP=%d, Level=%d, ReqEXP=%d",
-		filePath, profile.CurrentEXP, profile.Level, profile.RequiredEXPForNextLevel)
+	// Profiles saved before ratings were introduced start at the default rating
+	if profile.Rating == 0 {
+		profile.Rating = shared.DefaultRating
+	}
+
 	return profile, nil
 }
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testTroops = `[{"Name": "Pawn", "BaseHP": 50, "BaseATK": 150, "BaseDEF": 100, "ManaCost": 3}]`
+	testTowers = `[
+		{"Name": "King Tower", "Type": "KING", "Role": "KING", "Requires": ["LEFT", "RIGHT"], "BaseHP": 2000},
+		{"Name": "Left Guard", "Type": "LEFT", "Role": "GUARD", "BaseHP": 1000},
+		{"Name": "Right Guard", "Type": "RIGHT", "Role": "GUARD", "BaseHP": 1000}
+	]`
+)
+
+// writeSpecFiles writes troop and tower spec files to dir
+func writeSpecFiles(t *testing.T, dir, troops, towers string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, TroopSpecsFile), []byte(troops), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, TowerSpecsFile), []byte(towers), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSpecSet(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewJSONHandler(dir, t.TempDir())
+
+	writeSpecFiles(t, dir, testTroops, testTowers)
+	first, err := handler.LoadSpecSet(1)
+	if err != nil {
+		t.Fatalf("LoadSpecSet() error = %v", err)
+	}
+	if first.Version != 1 || len(first.Troops) != 1 || len(first.Towers) != 3 {
+		t.Errorf("LoadSpecSet() = version %d with %d troops and %d towers, want version 1 with 1 and 3",
+			first.Version, len(first.Troops), len(first.Towers))
+	}
+
+	same, err := handler.LoadSpecSet(2)
+	if err != nil {
+		t.Fatalf("LoadSpecSet() error = %v", err)
+	}
+	if same.Checksum != first.Checksum {
+		t.Errorf("checksum changed from %s to %s for the same files", first.Checksum, same.Checksum)
+	}
+
+	writeSpecFiles(t, dir, `[{"Name": "Pawn", "BaseHP": 60, "BaseATK": 150, "BaseDEF": 100, "ManaCost": 3}]`, testTowers)
+	changed, err := handler.LoadSpecSet(3)
+	if err != nil {
+		t.Fatalf("LoadSpecSet() error = %v", err)
+	}
+	if changed.Checksum == first.Checksum {
+		t.Errorf("checksum %s did not change with the troop specs", changed.Checksum)
+	}
+
+	writeSpecFiles(t, dir, `[]`, testTowers)
+	if _, err := handler.LoadSpecSet(4); err == nil {
+		t.Error("LoadSpecSet() accepted specs without a combat troop")
+	}
+
+	writeSpecFiles(t, dir, `[{"Name": "Pawn"`, testTowers)
+	if _, err := handler.LoadSpecSet(4); err == nil {
+		t.Error("LoadSpecSet() accepted a troop file with a syntax error")
+	}
+}
@@ -0,0 +1,370 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+)
+
+// SpecProblem is a problem found in a spec file, located as precisely as possible
+type SpecProblem struct {
+	File    string // Spec file, e.g. troops.json
+	Index   int    // Position of the entry in the file; -1 if the problem concerns the whole file
+	Entry   string // Name of the entry, if known
+	Field   string // Field of the entry at fault; empty if the problem concerns the whole entry
+	Line    int    // Line in the file for syntax errors, 0 otherwise
+	Message string
+}
+
+// Error formats the problem as file[index].Field (entry): message
+func (p *SpecProblem) Error() string {
+	location := p.File
+	if p.Line > 0 {
+		location += fmt.Sprintf(":%d", p.Line)
+	}
+	if p.Index >= 0 {
+		location += fmt.Sprintf("[%d]", p.Index)
+	}
+	if p.Field != "" {
+		location += "." + p.Field
+	}
+	if p.Entry != "" {
+		location += fmt.Sprintf(" (%s)", p.Entry)
+	}
+	return location + ": " + p.Message
+}
+
+// ValidateSpecs checks that matches can be played with the given specs.
+// Every problem is reported as a *SpecProblem, joined into one error.
+func ValidateSpecs(troops []models.TroopSpec, towers []models.TowerSpec) error {
+	var problems []error
+	report := func(file string, index int, entry, field, format string, args ...interface{}) {
+		problems = append(problems, &SpecProblem{
+			File:    file,
+			Index:   index,
+			Entry:   entry,
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	troopIndices := make(map[string]int)
+	regularTroops, startingTroops := 0, 0
+	for i, troop := range troops {
+		problem := func(field, format string, args ...interface{}) {
+			report(TroopSpecsFile, i, troop.Name, field, format, args...)
+		}
+
+		if troop.Name == "" {
+			problem("Name", "must not be empty")
+		} else if first, exists := troopIndices[troop.Name]; exists {
+			problem("Name", "duplicates %s[%d]", TroopSpecsFile, first)
+		} else {
+			troopIndices[troop.Name] = i
+		}
+
+		if troop.CardType != "" && !slices.Contains(shared.CardTypes, troop.CardType) {
+			problem("CardType", "unknown card type %q (known: %v)", troop.CardType, shared.CardTypes)
+		} else if troop.IsSpecialOnly && troop.CardType == shared.CardTroop {
+			problem("IsSpecialOnly", "must be false for a %s card", shared.CardTroop)
+		}
+		switch troop.Card() {
+		case shared.CardSpell:
+			if troop.BaseHP < 0 {
+				problem("BaseHP", "must not be negative")
+			}
+			if troop.SpecialAbility == "" {
+				problem("SpecialAbility", "must be set for a spell")
+			}
+		case shared.CardBuilding:
+			if troop.BaseHP <= 0 {
+				problem("BaseHP", "must be positive for a building")
+			}
+			if troop.Lifetime <= 0 {
+				problem("Lifetime", "must be positive for a building")
+			}
+			if troop.Protects != "" && !slices.ContainsFunc(towers, func(tower models.TowerSpec) bool { return tower.Type == troop.Protects }) {
+				problem("Protects", "no tower has Type %q", troop.Protects)
+			}
+		default:
+			regularTroops++
+			if troop.UnlockLevel <= 1 {
+				startingTroops++
+			}
+			if troop.BaseHP <= 0 {
+				problem("BaseHP", "must be positive for a troop that fights")
+			}
+		}
+		if troop.Card() != shared.CardBuilding {
+			if troop.Lifetime != 0 {
+				problem("Lifetime", "only buildings may have one")
+			}
+			if troop.Protects != "" {
+				problem("Protects", "only buildings may protect a tower")
+			}
+		}
+		switch troop.SpecialAbility {
+		case shared.FireballAbility:
+			if troop.BaseATK <= 0 {
+				problem("BaseATK", "must be positive for a %s spell (its damage)", troop.SpecialAbility)
+			}
+		case shared.RageAbility:
+			if troop.SpellPower <= 0 {
+				problem("SpellPower", "must be positive for a %s spell (its ATK bonus percent)", troop.SpecialAbility)
+			}
+		case shared.FreezeAbility:
+			if troop.SpellPower < 1 || troop.SpellPower > shared.MaxFreezeTurns {
+				problem("SpellPower", "must be between 1 and %d for a %s spell (the attacks skipped)", shared.MaxFreezeTurns, troop.SpecialAbility)
+			}
+		}
+		if troop.SpecialAbility != "" && troop.Card() != shared.CardSpell {
+			problem("SpecialAbility", "only spells may have one")
+		}
+		if troop.BaseATK < 0 {
+			problem("BaseATK", "must not be negative")
+		}
+		if troop.BaseDEF < 0 {
+			problem("BaseDEF", "must not be negative")
+		}
+		if troop.ManaCost < 0 {
+			problem("ManaCost", "must not be negative")
+		}
+		if troop.DestroyEXP < 0 {
+			problem("DestroyEXP", "must not be negative")
+		}
+		if troop.UnlockLevel < 0 {
+			problem("UnlockLevel", "must not be negative")
+		}
+		if troop.CritChancePercent < 0 || troop.CritChancePercent > 100 {
+			problem("CritChancePercent", "must be between 0 and 100")
+		}
+		if troop.CritMultiplier != 0 && troop.CritMultiplier < 1 {
+			problem("CritMultiplier", "must be 0 (mode default) or at least 1")
+		}
+		if troop.ArmorPenetrationPercent < 0 || troop.ArmorPenetrationPercent > 100 {
+			problem("ArmorPenetrationPercent", "must be between 0 and 100")
+		}
+		if troop.SplashPercent < 0 || troop.SplashPercent > 100 {
+			problem("SplashPercent", "must be between 0 and 100")
+		}
+		if troop.ChainTargets < 0 {
+			problem("ChainTargets", "must not be negative")
+		}
+		if troop.ChainFalloffPercent < 0 || troop.ChainFalloffPercent > 100 {
+			problem("ChainFalloffPercent", "must be between 0 and 100")
+		}
+		if troop.PreferredTarget != "" && !slices.Contains(shared.PreferredTargets, troop.PreferredTarget) {
+			problem("PreferredTarget", "unknown target %q (known: %v)", troop.PreferredTarget, shared.PreferredTargets)
+		}
+		if troop.SpecialAbility != "" && !slices.Contains(shared.SpecialAbilities, troop.SpecialAbility) {
+			problem("SpecialAbility", "unknown ability %q (known: %v)", troop.SpecialAbility, shared.SpecialAbilities)
+		}
+	}
+	if regularTroops == 0 {
+		report(TroopSpecsFile, -1, "", "", "at least one card must be a troop")
+	} else if startingTroops == 0 {
+		report(TroopSpecsFile, -1, "", "", "at least one troop must be unlocked from the start (UnlockLevel 0 or 1)")
+	}
+
+	towerIndices := make(map[string]int)
+	kings := 0
+	for i, tower := range towers {
+		problem := func(field, format string, args ...interface{}) {
+			report(TowerSpecsFile, i, tower.Name, field, format, args...)
+		}
+
+		if tower.Type == "" {
+			problem("Type", "must not be empty")
+		} else if first, exists := towerIndices[tower.Type]; exists {
+			problem("Type", "duplicates %s[%d]", TowerSpecsFile, first)
+		} else {
+			towerIndices[tower.Type] = i
+		}
+
+		if !slices.Contains(shared.TowerRoles, tower.Role) {
+			problem("Role", "unknown role %q (known: %v)", tower.Role, shared.TowerRoles)
+		}
+		if tower.Role == shared.KingTowerRole {
+			kings++
+		}
+
+		if tower.BaseHP <= 0 {
+			problem("BaseHP", "must be positive")
+		}
+		if tower.BaseATK < 0 {
+			problem("BaseATK", "must not be negative")
+		}
+		if tower.BaseDEF < 0 {
+			problem("BaseDEF", "must not be negative")
+		}
+		if tower.CritChancePercent < 0 || tower.CritChancePercent > 100 {
+			problem("CritChancePercent", "must be between 0 and 100")
+		}
+		if tower.DestroyEXP < 0 {
+			problem("DestroyEXP", "must not be negative")
+		}
+	}
+	if kings != 1 {
+		report(TowerSpecsFile, -1, "", "", "exactly one tower must have Role %q, found %d", shared.KingTowerRole, kings)
+	}
+
+	// Every required tower must exist, and requirements must not go round in a circle
+	for i, tower := range towers {
+		for _, required := range tower.Requires {
+			if required == tower.Type {
+				report(TowerSpecsFile, i, tower.Name, "Requires", "a tower cannot require itself")
+			} else if _, exists := towerIndices[required]; !exists {
+				report(TowerSpecsFile, i, tower.Name, "Requires", "no tower has Type %q", required)
+			}
+		}
+	}
+	for _, cycle := range requirementCycles(towers) {
+		first := towers[cycle[0]]
+		report(TowerSpecsFile, cycle[0], first.Name, "Requires", "requirements form a cycle (%s), so these towers can never be attacked", cycleTypes(towers, cycle))
+	}
+
+	return errors.Join(problems...)
+}
+
+// requirementCycles finds the towers whose Requires lead back to themselves.
+// Each cycle is returned once, as the indices of its towers in order.
+func requirementCycles(towers []models.TowerSpec) [][]int {
+	indices := make(map[string]int)
+	for i, tower := range towers {
+		indices[tower.Type] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(towers))
+	var path []int
+	var cycles [][]int
+
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		path = append(path, i)
+		for _, required := range towers[i].Requires {
+			next, exists := indices[required]
+			if !exists || next == i {
+				continue // Reported separately
+			}
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				start := slices.Index(path, next)
+				cycles = append(cycles, slices.Clone(path[start:]))
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+	}
+
+	for i := range towers {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	return cycles
+}
+
+// cycleTypes formats a requirement cycle as "A -> B -> A"
+func cycleTypes(towers []models.TowerSpec, cycle []int) string {
+	types := make([]string, 0, len(cycle)+1)
+	for _, i := range cycle {
+		types = append(types, towers[i].Type)
+	}
+	types = append(types, towers[cycle[0]].Type)
+	return strings.Join(types, " -> ")
+}
+
+// parseTroopSpecs decodes the contents of a troop specs file
+func parseTroopSpecs(data []byte) ([]models.TroopSpec, error) {
+	entries, err := decodeSpecEntries(TroopSpecsFile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	troops := make([]models.TroopSpec, len(entries))
+	var problems []error
+	for i, entry := range entries {
+		if err := decodeSpecEntry(TroopSpecsFile, i, entry, &troops[i]); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return troops, errors.Join(problems...)
+}
+
+// parseTowerSpecs decodes the contents of a tower specs file
+func parseTowerSpecs(data []byte) ([]models.TowerSpec, error) {
+	entries, err := decodeSpecEntries(TowerSpecsFile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	towers := make([]models.TowerSpec, len(entries))
+	var problems []error
+	for i, entry := range entries {
+		if err := decodeSpecEntry(TowerSpecsFile, i, entry, &towers[i]); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return towers, errors.Join(problems...)
+}
+
+// decodeSpecEntries splits a spec file into its entries, so each one can be decoded and reported on separately
+func decodeSpecEntries(file string, data []byte) ([]json.RawMessage, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		problem := &SpecProblem{File: file, Index: -1, Message: err.Error()}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			problem.Line = lineAt(data, syntaxErr.Offset)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			problem.Message = "must contain a JSON array of entries"
+		}
+		return nil, problem
+	}
+	return entries, nil
+}
+
+// decodeSpecEntry decodes one entry of a spec file. Unknown fields are rejected so typos do not go unnoticed.
+func decodeSpecEntry(file string, index int, entry json.RawMessage, spec interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(entry))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(spec)
+	if err == nil {
+		return nil
+	}
+
+	problem := &SpecProblem{File: file, Index: index, Message: err.Error()}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		problem.Field = typeErr.Field
+		problem.Message = fmt.Sprintf("must be of type %s, not a JSON %s", typeErr.Type, typeErr.Value)
+	}
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		problem.Field = strings.Trim(field, `"`)
+		problem.Message = "unknown field"
+	}
+	return problem
+}
+
+// lineAt returns the line number of a byte offset in data
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"errors"
+	"tcr/internal/models"
+	"tcr/internal/shared"
+	"testing"
+)
+
+// validSpecs returns a small set of specs matches can be played with
+func validSpecs() ([]models.TroopSpec, []models.TowerSpec) {
+	troops := []models.TroopSpec{
+		{Name: "Pawn", BaseHP: 50, BaseATK: 20, ManaCost: 3},
+		{Name: "Queen", IsSpecialOnly: true, SpecialAbility: shared.HealLowestHPTowerAbility},
+	}
+	towers := []models.TowerSpec{
+		{Name: "King Tower", Type: "KING", Role: shared.KingTowerRole, Requires: []string{"LEFT"}, BaseHP: 2000},
+		{Name: "Left Guard", Type: "LEFT", Role: shared.GuardTowerRole, BaseHP: 1000},
+	}
+	return troops, towers
+}
+
+// specProblems returns the problems of an error returned by ValidateSpecs or a spec parser
+func specProblems(t *testing.T, err error) []*SpecProblem {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	problems := make([]*SpecProblem, len(errs))
+	for i, err := range errs {
+		if !errors.As(err, &problems[i]) {
+			t.Fatalf("%v is not a *SpecProblem", err)
+		}
+	}
+	return problems
+}
+
+func TestValidateSpecs(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec)
+		want   []SpecProblem // File, Index and Field of each expected problem
+	}{
+		{
+			name: "valid specs",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return troops, towers
+			},
+		},
+		{
+			name: "duplicate troop name",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return append(troops, models.TroopSpec{Name: "Pawn", BaseHP: 10}), towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 2, Field: "Name"}},
+		},
+		{
+			name: "several problems in one troop",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[0].BaseATK = -1
+				troops[0].ManaCost = -1
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 0, Field: "BaseATK"}, {File: TroopSpecsFile, Index: 0, Field: "ManaCost"}},
+		},
+		{
+			name: "unknown ability",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[1].SpecialAbility = "TELEPORT"
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 1, Field: "SpecialAbility"}},
+		},
+		{
+			name: "combat stats out of range",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[0].CritMultiplier = 0.5
+				troops[0].ArmorPenetrationPercent = 120
+				troops[0].PreferredTarget = "HEALERS"
+				return troops, towers
+			},
+			want: []SpecProblem{
+				{File: TroopSpecsFile, Index: 0, Field: "CritMultiplier"},
+				{File: TroopSpecsFile, Index: 0, Field: "ArmorPenetrationPercent"},
+				{File: TroopSpecsFile, Index: 0, Field: "PreferredTarget"},
+			},
+		},
+		{
+			name: "valid building",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return append(troops, models.TroopSpec{Name: "Cannon", CardType: shared.CardBuilding, BaseHP: 300, Lifetime: 3, Protects: "LEFT"}), towers
+			},
+		},
+		{
+			name: "building without lifetime protecting an unknown tower",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return append(troops, models.TroopSpec{Name: "Cannon", CardType: shared.CardBuilding, BaseHP: 300, Protects: "MID"}), towers
+			},
+			want: []SpecProblem{
+				{File: TroopSpecsFile, Index: 2, Field: "Lifetime"},
+				{File: TroopSpecsFile, Index: 2, Field: "Protects"},
+			},
+		},
+		{
+			name: "building fields on a troop",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[0].Lifetime = 3
+				troops[0].Protects = "LEFT"
+				return troops, towers
+			},
+			want: []SpecProblem{
+				{File: TroopSpecsFile, Index: 0, Field: "Lifetime"},
+				{File: TroopSpecsFile, Index: 0, Field: "Protects"},
+			},
+		},
+		{
+			name: "no troop unlocked from the start",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[0].UnlockLevel = 3
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: -1}},
+		},
+		{
+			name: "negative unlock level",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				troops[0].UnlockLevel = -1
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 0, Field: "UnlockLevel"}},
+		},
+		{
+			name: "no troop that fights",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return troops[1:], towers
+			},
+			want: []SpecProblem{{File: TroopSpecsFile, Index: -1}},
+		},
+		{
+			name: "duplicate tower type",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				return troops, append(towers, models.TowerSpec{Name: "Copy", Type: "LEFT", Role: shared.GuardTowerRole, BaseHP: 1000})
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: 2, Field: "Type"}},
+		},
+		{
+			name: "unknown role",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				towers[1].Role = "WALL"
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: 1, Field: "Role"}},
+		},
+		{
+			name: "no King tower",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				towers[0].Role = shared.GuardTowerRole
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: -1}},
+		},
+		{
+			name: "required tower is missing",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				towers[0].Requires = []string{"RIGHT"}
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: 0, Field: "Requires"}},
+		},
+		{
+			name: "requirement cycle",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				towers[1].Requires = []string{"KING"}
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: 0, Field: "Requires"}},
+		},
+		{
+			name: "crit chance out of range",
+			modify: func(troops []models.TroopSpec, towers []models.TowerSpec) ([]models.TroopSpec, []models.TowerSpec) {
+				towers[0].CritChancePercent = 150
+				return troops, towers
+			},
+			want: []SpecProblem{{File: TowerSpecsFile, Index: 0, Field: "CritChancePercent"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			troops, towers := tt.modify(validSpecs())
+			problems := specProblems(t, ValidateSpecs(troops, towers))
+			if len(problems) != len(tt.want) {
+				t.Fatalf("ValidateSpecs() reported %d problems (%v), want %d", len(problems), problems, len(tt.want))
+			}
+			for i, problem := range problems {
+				want := tt.want[i]
+				if problem.File != want.File || problem.Index != want.Index || problem.Field != want.Field {
+					t.Errorf("problem %d = %v, want it at %s[%d].%s", i, problem, want.File, want.Index, want.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTroopSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []SpecProblem // File, Index, Field and Line of each expected problem
+	}{
+		{
+			name: "valid file",
+			data: `[{"Name": "Pawn", "BaseHP": 50}]`,
+		},
+		{
+			name: "syntax error",
+			data: "[\n{\"Name\": \"Pawn\",\n}\n]",
+			want: []SpecProblem{{File: TroopSpecsFile, Index: -1, Line: 3}},
+		},
+		{
+			name: "not an array",
+			data: `{"Name": "Pawn"}`,
+			want: []SpecProblem{{File: TroopSpecsFile, Index: -1}},
+		},
+		{
+			name: "unknown field",
+			data: `[{"Name": "Pawn"}, {"Name": "Knight", "Health": 50}]`,
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 1, Field: "Health"}},
+		},
+		{
+			name: "wrong type",
+			data: `[{"Name": "Pawn", "BaseHP": "lots"}]`,
+			want: []SpecProblem{{File: TroopSpecsFile, Index: 0, Field: "BaseHP"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTroopSpecs([]byte(tt.data))
+			problems := specProblems(t, err)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("parseTroopSpecs() reported %d problems (%v), want %d", len(problems), problems, len(tt.want))
+			}
+			for i, problem := range problems {
+				want := tt.want[i]
+				if problem.File != want.File || problem.Index != want.Index || problem.Field != want.Field || problem.Line != want.Line {
+					t.Errorf("problem %d = %v, want it at %s:%d[%d].%s", i, problem, want.File, want.Line, want.Index, want.Field)
+				}
+			}
+		})
+	}
+}